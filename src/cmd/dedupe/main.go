@@ -0,0 +1,84 @@
+// Command dedupe lists expressions of a language which look like duplicates of each other
+// and merges the chosen ones into the expression to keep.
+//
+// Usage:
+//
+//	dedupe -lang en -threshold 0.6 -limit 50
+//	dedupe -keep 12 -merge 34,56
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app/usecases"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/infrastructure"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/repos"
+)
+
+func main() {
+	lang := flag.String("lang", "", "language code to look duplicates up in")
+	threshold := flag.Float64("threshold", 0, "least similarity of listed pairs, 0.6 when not set")
+	limit := flag.Uint("limit", 0, "how many pairs are listed, 50 when not set")
+	keep := flag.Uint("keep", 0, "id of the expression to keep")
+	merge := flag.String("merge", "", "comma separated ids of expressions merged into the kept one")
+	flag.Parse()
+
+	if (*lang == "") == (*keep == 0) {
+		fmt.Fprintln(os.Stderr, "usage: dedupe -lang <code> [-threshold 0.6] [-limit 50] | dedupe -keep <id> -merge <id,id>")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	dataSourceName := fmt.Sprintf(
+		"host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
+		os.Getenv("POSTGRES_HOST"),
+		os.Getenv("POSTGRES_PORT"),
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_DB"),
+		os.Getenv("POSTGRES_PASSWORD"))
+
+	db, err := infrastructure.NewPostgresDB(dataSourceName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	repos := repos.NewRepos(db)
+	interactor := usecases.NewDedupeInteractor(repos.ExpressionMerge)
+
+	if *keep == 0 {
+		candidates, err := interactor.FindDuplicates(*lang, *threshold, *limit)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, c := range candidates {
+			fmt.Printf("%.2f\t%d\t%s\t%d\t%s\n", c.Similarity, *c.KeepId, c.KeepValue, *c.DropId, c.DropValue)
+		}
+		return
+	}
+
+	dropIds := []valueobject.ID{}
+	for _, arg := range strings.Split(*merge, ",") {
+		if arg = strings.TrimSpace(arg); arg == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(arg)
+		if err != nil || id <= 0 {
+			log.Fatalf("invalid expression id %q", arg)
+		}
+		dropIds = append(dropIds, valueobject.ID(id))
+	}
+
+	keepId := valueobject.ID(*keep)
+	if err := interactor.Merge(&keepId, dropIds); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("merged %d expressions into %d\n", len(dropIds), keepId)
+}
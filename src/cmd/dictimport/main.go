@@ -0,0 +1,73 @@
+// Command dictimport loads an open dictionary file (CC-CEDICT or TSV) into the database
+// replacing the previously imported dictionary of the same name.
+//
+// Usage: dictimport -name cc-cedict -format cedict -source zh -native en -transcription-type 1 cedict_ts.u8
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/app/usecases"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/infrastructure"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/repos"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/services"
+)
+
+func main() {
+	name := flag.String("name", "", "dictionary name, an existing dictionary with the name is replaced")
+	format := flag.String("format", string(app.DictionaryCedict), "file format: cedict or tsv")
+	source := flag.String("source", "", "language code of headwords")
+	native := flag.String("native", "", "language code of translations")
+	transcriptionType := flag.Int("transcription-type", 0, "transcription type id of the entries, 0 when transcriptions aren't suggested")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: dictimport [flags] <file>")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	dataSourceName := fmt.Sprintf(
+		"host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
+		os.Getenv("POSTGRES_HOST"),
+		os.Getenv("POSTGRES_PORT"),
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_DB"),
+		os.Getenv("POSTGRES_PASSWORD"))
+
+	db, err := infrastructure.NewPostgresDB(dataSourceName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	repos := repos.NewRepos(db)
+	services := services.NewServices(repos)
+
+	dictionary := app.Dictionary{
+		Name:           *name,
+		Format:         app.DictionaryFormat(*format),
+		SourceLangCode: *source,
+		NativeLangCode: *native,
+	}
+	if *transcriptionType > 0 {
+		typeId := valueobject.ID(*transcriptionType)
+		dictionary.TranscriptionTypeId = &typeId
+	}
+
+	imported, err := usecases.NewDictionaryInteractor(repos.Dictionary, services.Dictionary).Import(dictionary, file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("imported %d entries into dictionary \"%s\"\n", imported.Size, imported.Name)
+}
@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/alexkarpovich/lst-api/src/internal/app/usecases"
 	"github.com/alexkarpovich/lst-api/src/internal/infrastructure"
 	"github.com/alexkarpovich/lst-api/src/internal/interfaces"
 	"github.com/alexkarpovich/lst-api/src/internal/interfaces/repos"
@@ -19,6 +20,34 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
+// purgeTrash periodically removes groups and nodes which stayed in the trash for too long.
+func purgeTrash(ctx context.Context, gi *usecases.GroupInteractor) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		purged, err := gi.PurgeDeletedGroups()
+		if err != nil {
+			log.Println(err)
+		} else if purged > 0 {
+			log.Printf("purged %d deleted groups", purged)
+		}
+
+		purged, err = gi.PurgeDeletedNodes()
+		if err != nil {
+			log.Println(err)
+		} else if purged > 0 {
+			log.Printf("purged %d deleted nodes", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func main() {
 	dataSourceName := fmt.Sprintf(
 		"host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
@@ -39,6 +68,9 @@ func main() {
 
 	services := services.NewServices(repos)
 
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	go purgeTrash(purgeCtx, usecases.NewGroupInteractor(repos.Group, repos.Node, repos.User, repos.Activity, services.Email, services.Anki, services.Backup))
+
 	srv, err := interfaces.NewHTTPServer(serverAddress, repos, services)
 
 	if err != nil {
@@ -67,6 +99,7 @@ func main() {
 	// Doesn't block if no connections, but will otherwise wait
 	// until the timeout deadline.
 	srv.Shutdown(ctx)
+	stopPurge()
 	// Optionally, you could run srv.Shutdown in a goroutine and block on
 	// <-ctx.Done() if your application should wait for other services
 	// to finalize based on context cancellation.
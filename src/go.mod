@@ -12,9 +12,13 @@ require (
 	github.com/jmoiron/sqlx v1.3.4
 	github.com/justinas/alice v1.2.0
 	github.com/lib/pq v1.10.4
+	golang.org/x/text v0.13.0
 )
 
-require gopkg.in/alexcesaro/quotedprintable.v3 v3.0.0-20150716171945-2caba252f4dc // indirect
+require (
+	golang.org/x/sys v0.7.0 // indirect
+	gopkg.in/alexcesaro/quotedprintable.v3 v3.0.0-20150716171945-2caba252f4dc // indirect
+)
 
 require (
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+type ActivityAction string
+
+const (
+	ActivityGroupUpdate         ActivityAction = "group.update"
+	ActivityGroupDelete         ActivityAction = "group.delete"
+	ActivityGroupRestore        ActivityAction = "group.restore"
+	ActivityGroupClone          ActivityAction = "group.clone"
+	ActivityGroupPublish        ActivityAction = "group.publish"
+	ActivityGroupBackup         ActivityAction = "group.backup"
+	ActivityGroupRestoreBackup  ActivityAction = "group.restore-backup"
+	ActivityMemberInvite        ActivityAction = "member.invite"
+	ActivityMemberJoin          ActivityAction = "member.join"
+	ActivityMemberReject        ActivityAction = "member.reject"
+	ActivityMemberLeave         ActivityAction = "member.leave"
+	ActivityMemberDetach        ActivityAction = "member.detach"
+	ActivityMemberRole          ActivityAction = "member.role"
+	ActivityMemberTransferAdmin ActivityAction = "member.transfer-admin"
+	ActivityLinkCreate          ActivityAction = "link.create"
+	ActivityLinkRevoke          ActivityAction = "link.revoke"
+	ActivityNodeCreate          ActivityAction = "node.create"
+	ActivityNodeUpdate          ActivityAction = "node.update"
+	ActivityNodeMove            ActivityAction = "node.move"
+	ActivityNodeDelete          ActivityAction = "node.delete"
+	ActivityNodeRestore         ActivityAction = "node.restore"
+	ActivityNodeCopy            ActivityAction = "node.copy"
+	ActivityNodeMerge           ActivityAction = "node.merge"
+	ActivityNodeImport          ActivityAction = "node.import"
+	ActivityNodeRevert          ActivityAction = "node.revert"
+	ActivityExpressionAttach    ActivityAction = "expression.attach"
+	ActivityExpressionDetach    ActivityAction = "expression.detach"
+	ActivityTranslationAttach   ActivityAction = "translation.attach"
+	ActivityTranslationDetach   ActivityAction = "translation.detach"
+	ActivityTextAttach          ActivityAction = "text.attach"
+	ActivityTextDetach          ActivityAction = "text.detach"
+	ActivityTranscriptionCreate ActivityAction = "transcription.create"
+	ActivityTranscriptionAttach ActivityAction = "transcription.attach"
+	ActivityTranscriptionDetach ActivityAction = "transcription.detach"
+	ActivitySuggestionAccept    ActivityAction = "suggestion.accept"
+	ActivityTagCreate           ActivityAction = "tag.create"
+	ActivityTagUpdate           ActivityAction = "tag.update"
+	ActivityTagDelete           ActivityAction = "tag.delete"
+	ActivityTagAttach           ActivityAction = "tag.attach"
+	ActivityTagDetach           ActivityAction = "tag.detach"
+)
+
+type ActivityTarget string
+
+const (
+	TargetGroup       ActivityTarget = "group"
+	TargetMember      ActivityTarget = "member"
+	TargetLink        ActivityTarget = "link"
+	TargetNode        ActivityTarget = "node"
+	TargetExpression  ActivityTarget = "expression"
+	TargetTranslation ActivityTarget = "translation"
+	TargetText        ActivityTarget = "text"
+	TargetTag         ActivityTarget = "tag"
+)
+
+// Activity is an append-only audit record of a mutation made within a group.
+type Activity struct {
+	Id         *valueobject.ID `json:"id" db:"id"`
+	GroupId    *valueobject.ID `json:"groupId" db:"group_id"`
+	ActorId    *valueobject.ID `json:"actorId" db:"actor_id"`
+	Action     ActivityAction  `json:"action" db:"action"`
+	TargetType ActivityTarget  `json:"targetType" db:"target_type"`
+	TargetId   *valueobject.ID `json:"targetId" db:"target_id"`
+	Before     interface{}     `json:"before"`
+	After      interface{}     `json:"after"`
+	CreatedAt  time.Time       `json:"createdAt" db:"created_at"`
+}
+
+type ActivityFilter struct {
+	ActorId    *valueobject.ID
+	Action     ActivityAction
+	TargetType ActivityTarget
+	TargetId   *valueobject.ID
+	Since      *time.Time
+	Until      *time.Time
+	Cursor     *valueobject.ID
+	Limit      uint
+}
+
+type ActivityPage struct {
+	Items      []*Activity     `json:"items"`
+	NextCursor *valueobject.ID `json:"nextCursor"`
+}
+
+type ActivityRepo interface {
+	Log(Activity) error
+	LogByNode(*valueobject.ID, Activity) error
+	LogByExpression(*valueobject.ID, *valueobject.ID, Activity) error
+	LogByTranslation(*valueobject.ID, *valueobject.ID, Activity) error
+	List(*valueobject.ID, ActivityFilter) ([]*Activity, error)
+}
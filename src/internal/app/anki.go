@@ -0,0 +1,37 @@
+package app
+
+import (
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// AnkiDeckSeparator joins names of nested Anki decks.
+const AnkiDeckSeparator = "::"
+
+// DeckNote is an Anki note with plain text fields in the note type order.
+type DeckNote struct {
+	Fields []string
+	Tags   []string
+}
+
+// Deck is an Anki deck, Name is the full path like "Parent::Child".
+type Deck struct {
+	Name  string
+	Notes []*DeckNote
+}
+
+// DeckPackage is the content of .apkg file.
+type DeckPackage struct {
+	Decks []*Deck
+}
+
+type AnkiDeckReport struct {
+	Deck   string          `json:"deck"`
+	NodeId *valueobject.ID `json:"nodeId"`
+	Report *ImportReport   `json:"report"`
+}
+
+type AnkiImportReport struct {
+	Folders uint              `json:"folders"`
+	Slices  uint              `json:"slices"`
+	Decks   []*AnkiDeckReport `json:"decks"`
+}
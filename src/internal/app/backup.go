@@ -0,0 +1,79 @@
+package app
+
+import (
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// BackupVersion is the archive format version, archives of newer versions can't be restored.
+const BackupVersion = 1
+
+type BackupGroup struct {
+	Name              string           `json:"name"`
+	TargetLangCode    string           `json:"targetLangCode"`
+	NativeLangCode    string           `json:"nativeLangCode"`
+	TranscriptionType string           `json:"transcriptionType"`
+	Visibility        GroupVisibility  `json:"visibility"`
+	NodeOrder         []valueobject.ID `json:"nodeOrder"`
+}
+
+type BackupText struct {
+	Title     string    `json:"title"`
+	Content   string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type BackupTranslation struct {
+	Value          string   `json:"value"`
+	Comment        string   `json:"comment,omitempty"`
+	Transcriptions []string `json:"transcriptions"`
+}
+
+type BackupExpression struct {
+	Value          string               `json:"value"`
+	Position       *int                 `json:"position,omitempty"`
+	Transcriptions []string             `json:"transcriptions"`
+	Translations   []*BackupTranslation `json:"translations"`
+	CreatedAt      time.Time            `json:"createdAt"`
+}
+
+// BackupNode keeps the original node id as a key which ancestor paths
+// and the node order refer to, new ids are given on restore.
+type BackupNode struct {
+	Key             valueobject.ID      `json:"key"`
+	Type            NodeType            `json:"type"`
+	Name            string              `json:"name"`
+	Visibility      NodeVisibility      `json:"visibility"`
+	ExpressionOrder ExpressionOrder     `json:"expressionOrder,omitempty"`
+	SmartQuery      *SmartQuery         `json:"smartQuery,omitempty"`
+	Path            []valueobject.ID    `json:"path"`
+	Text            *BackupText         `json:"text,omitempty"`
+	Expressions     []*BackupExpression `json:"expressions"`
+}
+
+type BackupMember struct {
+	Username string       `json:"username"`
+	Email    string       `json:"email"`
+	Role     UserRole     `json:"role"`
+	Status   MemberStatus `json:"status"`
+}
+
+// GroupBackup is the whole content of a group which can be restored into a new group.
+type GroupBackup struct {
+	Version   uint            `json:"version"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Group     *BackupGroup    `json:"group"`
+	Nodes     []*BackupNode   `json:"nodes"`
+	Members   []*BackupMember `json:"members"`
+}
+
+// RestoreReport tells what was recreated from the archive.
+type RestoreReport struct {
+	GroupId     *valueobject.ID `json:"groupId"`
+	Nodes       uint            `json:"nodes"`
+	Expressions uint            `json:"expressions"`
+	Texts       uint            `json:"texts"`
+	Invited     []string        `json:"invited"`
+	Skipped     []string        `json:"skipped"`
+}
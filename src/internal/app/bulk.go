@@ -0,0 +1,60 @@
+package app
+
+import (
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// BulkMode tells what happens to the batch when some of its items fail.
+type BulkMode string
+
+const (
+	// BulkAtomic applies the batch only when every item succeeds
+	BulkAtomic BulkMode = "atomic"
+	// BulkBestEffort applies succeeded items and reports failed ones
+	BulkBestEffort BulkMode = "best-effort"
+)
+
+type BulkItemStatus string
+
+const (
+	BulkAttached BulkItemStatus = "attached"
+	BulkDetached BulkItemStatus = "detached"
+	// BulkUnchanged means the node already had the item attached or didn't have it for detach
+	BulkUnchanged BulkItemStatus = "unchanged"
+	// BulkRolledBack means the item succeeded but the atomic batch was rolled back
+	BulkRolledBack BulkItemStatus = "rolled-back"
+	BulkFailed     BulkItemStatus = "error"
+)
+
+type BulkTranslationResult struct {
+	Index  int             `json:"index"`
+	Id     *valueobject.ID `json:"id,omitempty"`
+	Value  string          `json:"value"`
+	Status BulkItemStatus  `json:"status"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type BulkExpressionResult struct {
+	Index        int                      `json:"index"`
+	Id           *valueobject.ID          `json:"id,omitempty"`
+	Value        string                   `json:"value"`
+	Status       BulkItemStatus           `json:"status"`
+	Error        string                   `json:"error,omitempty"`
+	Translations []*BulkTranslationResult `json:"translations"`
+}
+
+type BulkDetachResult struct {
+	TargetType ActivityTarget  `json:"targetType"`
+	Id         *valueobject.ID `json:"id"`
+	Status     BulkItemStatus  `json:"status"`
+	Error      string          `json:"error,omitempty"`
+}
+
+type BulkReport struct {
+	Mode        BulkMode                `json:"mode"`
+	Applied     bool                    `json:"applied"`
+	Succeeded   uint                    `json:"succeeded"`
+	Failed      uint                    `json:"failed"`
+	Expressions []*BulkExpressionResult `json:"expressions,omitempty"`
+	Detached    []*BulkDetachResult     `json:"detached,omitempty"`
+}
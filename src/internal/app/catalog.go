@@ -0,0 +1,49 @@
+package app
+
+import (
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// CatalogEntry is either a published group (node fields are empty) or
+// a published node of otherwise private group.
+type CatalogEntry struct {
+	GroupId        *valueobject.ID `json:"groupId" db:"group_id"`
+	GroupName      string          `json:"groupName" db:"group_name"`
+	TargetLangCode string          `json:"targetLangCode" db:"target_lang"`
+	NativeLangCode string          `json:"nativeLangCode" db:"native_lang"`
+	NodeId         *valueobject.ID `json:"nodeId" db:"node_id"`
+	NodeName       *string         `json:"nodeName" db:"node_name"`
+	NodeType       *NodeType       `json:"nodeType" db:"node_type"`
+	Size           uint            `json:"size" db:"size"`
+}
+
+type CatalogFilter struct {
+	TargetLangCode string
+	NativeLangCode string
+	Search         string
+	MinSize        uint
+	MaxSize        uint
+	Limit          uint
+	Offset         uint
+}
+
+type CatalogGroup struct {
+	Group *Group      `json:"group"`
+	Nodes []*FlatNode `json:"nodes"`
+}
+
+type Subscription struct {
+	GroupId   *valueobject.ID `json:"groupId" db:"group_id"`
+	Group     *Group          `json:"group"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
+}
+
+type CatalogRepo interface {
+	Search(CatalogFilter) ([]*CatalogEntry, error)
+	IsPublished(*valueobject.ID) (bool, error)
+	Subscribe(*valueobject.ID, *valueobject.ID) error
+	Unsubscribe(*valueobject.ID, *valueobject.ID) error
+	ListSubscriptions(*valueobject.ID) ([]*Subscription, error)
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// DictionaryFormat tells how the dictionary file is parsed.
+type DictionaryFormat string
+
+const (
+	// DictionaryCedict is the CC-CEDICT line format: traditional simplified [pin1 yin1] /gloss/gloss/
+	DictionaryCedict DictionaryFormat = "cedict"
+	// DictionaryTsv has headword, transcription and translations separated by ";" in tab separated columns
+	DictionaryTsv DictionaryFormat = "tsv"
+)
+
+// Dictionary is an imported open dictionary which suggests translations and transcriptions
+// of expressions in the source language. Transcriptions have the type when it is set.
+type Dictionary struct {
+	Id                  *valueobject.ID  `json:"id" db:"id"`
+	Name                string           `json:"name" db:"name"`
+	Format              DictionaryFormat `json:"format" db:"format"`
+	SourceLangCode      string           `json:"sourceLangCode" db:"source_lang"`
+	NativeLangCode      string           `json:"nativeLangCode" db:"native_lang"`
+	TranscriptionTypeId *valueobject.ID  `json:"transcriptionTypeId" db:"transcription_type"`
+	Size                uint             `json:"size" db:"size"`
+	CreatedAt           time.Time        `json:"createdAt" db:"created_at"`
+}
+
+// DictionaryEntry is a headword with its optional variant spelling, e.g. the traditional one.
+type DictionaryEntry struct {
+	Headword      string
+	Variant       string
+	Transcription string
+	Translations  []string
+}
+
+// DictionarySuggestion offers an entry for the expression, the transcription is given
+// only when the dictionary has the transcription type of the group.
+type DictionarySuggestion struct {
+	EntryId       *valueobject.ID `json:"entryId"`
+	Dictionary    string          `json:"dictionary"`
+	Transcription string          `json:"transcription,omitempty"`
+	Translations  []string        `json:"translations"`
+}
+
+type DictionaryRepo interface {
+	Import(Dictionary, []*DictionaryEntry) (*Dictionary, error)
+	Suggest(*valueobject.ID, *valueobject.ID) ([]*DictionarySuggestion, error)
+	GetSuggestion(*valueobject.ID, *valueobject.ID, *valueobject.ID) (*DictionarySuggestion, error)
+	Lexicon(*valueobject.ID, string) (map[string]string, error)
+	Vocabulary(string, string) (map[string]bool, error)
+}
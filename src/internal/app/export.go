@@ -0,0 +1,64 @@
+package app
+
+import (
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+type ExportFormat string
+
+const (
+	ExportJson ExportFormat = "json"
+	ExportCsv  ExportFormat = "csv"
+	ExportHtml ExportFormat = "html"
+	ExportPdf  ExportFormat = "pdf"
+)
+
+// ExportLayout tells how the printable formats place translations.
+type ExportLayout string
+
+const (
+	// ExportList shows translations next to expressions
+	ExportList ExportLayout = "list"
+	// ExportQuiz numbers entries and separates columns with a fold line to hide translations
+	ExportQuiz ExportLayout = "quiz"
+)
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportCsv:
+		return "text/csv; charset=utf-8"
+	case ExportHtml:
+		return "text/html; charset=utf-8"
+	case ExportPdf:
+		return "application/pdf"
+	}
+
+	return "application/json"
+}
+
+type ExportTranslation struct {
+	Value          string   `json:"value"`
+	Transcriptions []string `json:"transcriptions"`
+	Comment        string   `json:"comment,omitempty"`
+}
+
+type ExportEntry struct {
+	Expression     string               `json:"expression"`
+	Transcriptions []string             `json:"transcriptions"`
+	Translations   []*ExportTranslation `json:"translations"`
+}
+
+type ExportSlice struct {
+	Id      *valueobject.ID `json:"id"`
+	Name    string          `json:"name"`
+	Folders []string        `json:"folders"`
+	Entries []*ExportEntry  `json:"expressions"`
+}
+
+// VocabularyExport is the content of the exported slices in the group node order.
+type VocabularyExport struct {
+	Slices    []*ExportSlice `json:"slices"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+type TranscriptionGroup struct {
+	TypeId         *valueobject.ID             `json:"typeId"`
+	TypeName       string                      `json:"typeName"`
+	Transcriptions []*domain.TranscriptionItem `json:"transcriptions"`
+}
+
+// GeneratedTranscription is made by a transcriber for the user to confirm or fix.
+type GeneratedTranscription struct {
+	TypeId *valueobject.ID `json:"typeId"`
+	Value  string          `json:"value"`
+}
+
+// ExpressionText is a text readable by the user which contains the expression.
+type ExpressionText struct {
+	Id        *valueobject.ID `json:"id"`
+	LangCode  string          `json:"langCode"`
+	Title     string          `json:"title"`
+	Excerpt   string          `json:"excerpt"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// ExpressionUsage counts slices of the user groups and the user trainings using the expression.
+type ExpressionUsage struct {
+	Slices    uint `json:"slices"`
+	Trainings uint `json:"trainings"`
+}
+
+// ExpressionDetail is the expression with its translations by native language
+// and transcriptions by type.
+type ExpressionDetail struct {
+	Id             *valueobject.ID                      `json:"id"`
+	LangCode       string                               `json:"langCode"`
+	Value          string                               `json:"value"`
+	Translations   map[string][]*domain.TranslationItem `json:"translations"`
+	Transcriptions []*TranscriptionGroup                `json:"transcriptions"`
+	Texts          []*ExpressionText                    `json:"texts"`
+	Usage          ExpressionUsage                      `json:"usage"`
+}
+
+type ExpressionDetailRepo interface {
+	Detail(*valueobject.ID, *valueobject.ID) (*ExpressionDetail, error)
+}
+
+// DuplicateCandidate is a pair of expressions of one language which likely mean the same,
+// similarity compares lower case values.
+type DuplicateCandidate struct {
+	KeepId     *valueobject.ID `json:"keepId"`
+	KeepValue  string          `json:"keepValue"`
+	DropId     *valueobject.ID `json:"dropId"`
+	DropValue  string          `json:"dropValue"`
+	Similarity float64         `json:"similarity"`
+}
+
+type ExpressionMergeRepo interface {
+	FindDuplicates(string, float64, uint) ([]*DuplicateCandidate, error)
+	Merge(*valueobject.ID, []valueobject.ID) error
+}
@@ -17,6 +17,26 @@ const (
 	GroupDeleted
 )
 
+// GroupVisibility allows to publish the whole group to the catalog
+type GroupVisibility uint
+
+const (
+	// GroupPrivate is the zero value so groups are never published by default
+	GroupPrivate GroupVisibility = iota
+	GroupPublic
+)
+
+// GroupTrashRetention is how long a deleted group stays in the trash
+// and can be restored before it gets purged.
+const GroupTrashRetention = 30 * 24 * time.Hour
+
+type GroupLinkStatus uint
+
+const (
+	GroupLinkActive GroupLinkStatus = iota
+	GroupLinkRevoked
+)
+
 type GroupConfig struct {
 	NodeOrder []*valueobject.ID `json:"nodeOrder,omitempty"`
 }
@@ -39,8 +59,23 @@ type GroupMember struct {
 	Username       string          `json:"username" db:"username"`
 	Role           UserRole        `json:"role" db:"role"`
 	Status         MemberStatus    `json:"status" db:"status"`
-	Token          string          `db:"token"`
-	TokenExpiresAt time.Time       `db:"token_expires_at"`
+	Token          string          `json:"-" db:"token"`
+	TokenExpiresAt time.Time       `json:"-" db:"token_expires_at"`
+}
+
+// GroupLink is a shareable link which lets any user join the group
+// with a predefined role until it expires, is used up or revoked.
+type GroupLink struct {
+	Id        *valueobject.ID `json:"id" db:"id"`
+	GroupId   *valueobject.ID `json:"groupId" db:"group_id"`
+	AuthorId  *valueobject.ID `json:"authorId" db:"author_id"`
+	Token     string          `json:"token" db:"token"`
+	Role      UserRole        `json:"role" db:"role"`
+	MaxUses   uint            `json:"maxUses" db:"max_uses"`
+	UseCount  uint            `json:"useCount" db:"use_count"`
+	Status    GroupLinkStatus `json:"status" db:"status"`
+	ExpiresAt time.Time       `json:"expiresAt" db:"expires_at"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
 }
 
 type Group struct {
@@ -50,10 +85,13 @@ type Group struct {
 	NativeLangCode      string                    `json:"nativeLangCode" db:"native_lang"`
 	Name                string                    `json:"name" db:"name"`
 	Status              GroupStatus               `json:"status" db:"status"`
+	Visibility          GroupVisibility           `json:"visibility" db:"visibility"`
 	IsUntouched         bool                      `json:"isUntouched"`
 	TranscriptionType   *domain.TranscriptionType `json:"transcriptionType"`
 	Config              *GroupConfig              `json:"config" db:"config"`
 	Members             []*GroupMember            `json:"members"`
+	DeletedAt           *time.Time                `json:"deletedAt,omitempty" db:"deleted_at"`
+	DeletedBy           *valueobject.ID           `json:"-" db:"deleted_by"`
 }
 
 type GroupRepo interface {
@@ -61,13 +99,29 @@ type GroupRepo interface {
 	Update(Group) error
 	Get(*valueobject.ID) (*Group, error)
 	List(*valueobject.ID) ([]*Group, error)
-	MarkAsDeleted(*valueobject.ID) error
+	MarkAsDeleted(*valueobject.ID, *valueobject.ID) error
+	ListDeleted(*valueobject.ID, time.Time) ([]*Group, error)
+	Restore(*valueobject.ID, time.Time) error
+	Purge(time.Time) (uint, error)
+	UpdateVisibility(*valueobject.ID, GroupVisibility) error
 	MoveNode(*valueobject.ID, FlatNode, []*valueobject.ID) error
-	DeleteNode(*valueobject.ID, *valueobject.ID) error
+	DeleteNode(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	ListDeletedNodes(*valueobject.ID, time.Time) ([]*TrashedNode, error)
+	RestoreNode(*valueobject.ID, *valueobject.ID, time.Time) (*valueobject.ID, error)
+	PurgeNodes(time.Time) (uint, error)
 	FindMemberById(*valueobject.ID, *valueobject.ID) (*GroupMember, error)
 	FindMemberByNodeId(*valueobject.ID, *valueobject.ID) (*GroupMember, error)
 	FindMemberByToken(string) (*valueobject.ID, *GroupMember, error)
 	AttachUser(*valueobject.ID, GroupMember) error
 	DetachMember(*valueobject.ID, *valueobject.ID) error
 	UpdateMember(*valueobject.ID, GroupMember) error
+	Clone(*valueobject.ID, *valueobject.ID, Group, []valueobject.ID) (*valueobject.ID, error)
+	TransferAdmin(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	CreateLink(GroupLink) (*GroupLink, error)
+	ListLinks(*valueobject.ID) ([]*GroupLink, error)
+	FindLinkByToken(string) (*GroupLink, error)
+	RevokeLink(*valueobject.ID, *valueobject.ID) error
+	JoinByLink(string, GroupMember) (*valueobject.ID, error)
+	Backup(*valueobject.ID) (*GroupBackup, error)
+	RestoreBackup(*valueobject.ID, *GroupBackup, string) (*RestoreReport, error)
 }
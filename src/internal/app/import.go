@@ -0,0 +1,77 @@
+package app
+
+import (
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// ImportColumn tells how a column of the imported file is treated.
+type ImportColumn string
+
+const (
+	ImportExpression    ImportColumn = "expression"
+	ImportTranslation   ImportColumn = "translation"
+	ImportTranscription ImportColumn = "transcription"
+	ImportComment       ImportColumn = "comment"
+	ImportSkip          ImportColumn = "skip"
+)
+
+type ImportRowStatus string
+
+const (
+	// ImportCreated means the expression didn't exist and was created
+	ImportCreated ImportRowStatus = "created"
+	// ImportLinked means existing expression or translations were attached to the node
+	ImportLinked ImportRowStatus = "linked"
+	// ImportDuplicate means the node already had everything from the row
+	ImportDuplicate ImportRowStatus = "duplicate"
+	ImportError     ImportRowStatus = "error"
+)
+
+type ImportOptions struct {
+	Columns        []ImportColumn
+	Delimiter      rune
+	ValueSeparator string
+	HasHeader      bool
+	DryRun         bool
+}
+
+// ImportRow is a single vocabulary entry to be attached to a slice.
+type ImportRow struct {
+	Line           uint
+	Expression     string
+	Translations   []string
+	Transcriptions []string
+	Comment        string
+}
+
+type ImportRowReport struct {
+	Line         uint            `json:"line"`
+	Expression   string          `json:"expression"`
+	ExpressionId *valueobject.ID `json:"expressionId,omitempty"`
+	Status       ImportRowStatus `json:"status"`
+	Error        string          `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun    bool               `json:"dryRun"`
+	Created   uint               `json:"created"`
+	Linked    uint               `json:"linked"`
+	Duplicate uint               `json:"duplicate"`
+	Errors    uint               `json:"errors"`
+	Rows      []*ImportRowReport `json:"rows"`
+}
+
+// ImportNode is a folder or a slice created by an import of several decks, a slice comes with
+// its rows. Parent is the index of the imported folder it goes into, nil for the import folder.
+type ImportNode struct {
+	Type   NodeType
+	Name   string
+	Parent *int
+	Rows   []*ImportRow
+}
+
+// ImportedNode is the created node with reports of its rows.
+type ImportedNode struct {
+	Node    *Node
+	Reports []*ImportRowReport
+}
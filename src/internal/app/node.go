@@ -1,6 +1,8 @@
 package app
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
@@ -10,25 +12,49 @@ import (
 type NodeVisibility uint
 
 const (
+	// NodePrivate deny share the node, it's the zero value so nodes are never published by default
+	NodePrivate NodeVisibility = iota
 	// NodePublic allows share the node with other users
-	NodePublic NodeVisibility = iota
-	// NodePrivate deny share the node
-	NodePrivate
+	NodePublic
 )
 
+// NodeTrashRetention is how long deleted nodes can be restored before they get purged.
+const NodeTrashRetention = 30 * 24 * time.Hour
+
 type NodeType uint
 
 const (
 	NodeFolder NodeType = iota
 	NodeSlice
+	// NodeSmart is a slice whose expressions are computed from its smart query
+	NodeSmart
+)
+
+// IsSlice tells whether nodes of the type list expressions, either static or computed.
+func (t NodeType) IsSlice() bool {
+	return t == NodeSlice || t == NodeSmart
+}
+
+// ExpressionOrder tells how expressions of a slice are listed
+type ExpressionOrder string
+
+const (
+	// ExpressionOrderManual follows positions set by the reorder, new expressions go last
+	ExpressionOrderManual  ExpressionOrder = "manual"
+	ExpressionOrderCreated ExpressionOrder = "created"
+	ExpressionOrderAlpha   ExpressionOrder = "alpha"
+	// ExpressionOrderDifficulty puts first expressions whose translations learners fail most often in trainings
+	ExpressionOrderDifficulty ExpressionOrder = "difficulty"
 )
 
 type Expression struct {
-	Id             *valueobject.ID  `json:"id" db:"id"`
-	Value          string           `json:"value" db:"value"`
-	Transcriptions []*Transcription `json:"transcriptions"`
-	Translations   []*Translation   `json:"translations"`
-	CreatedAt      time.Time        `json:"createdAt" db:"created_at"`
+	Id             *valueobject.ID         `json:"id" db:"id"`
+	Value          string                  `json:"value" db:"value"`
+	Transcriptions []*Transcription        `json:"transcriptions"`
+	Translations   []*Translation          `json:"translations"`
+	Suggestions    []*DictionarySuggestion `json:"suggestions,omitempty"`
+	Generated      *GeneratedTranscription `json:"generatedTranscription,omitempty"`
+	CreatedAt      time.Time               `json:"createdAt" db:"created_at"`
 }
 
 type Translation struct {
@@ -69,16 +95,18 @@ type Text struct {
 }
 
 type Node struct {
-	Id          *valueobject.ID `json:"id" db:"id"`
-	TextId      *valueobject.ID `json:"textId" db:"text_id"`
-	Type        NodeType        `json:"type" db:"type"`
-	Name        string          `json:"name" db:"name"`
-	Path        string          `json:"path" db:"path"`
-	Visibility  NodeVisibility  `json:"visibility" db:"visibility"`
-	Text        *Text           `json:"text" db:"text"`
-	Expressions []*Expression   `json:"expressions"`
-	CreatedAt   time.Time       `json:"createdAt" db:"created_at"`
-	UpdatedAt   time.Time       `json:"updatedAt" db:"updated_at"`
+	Id              *valueobject.ID `json:"id" db:"id"`
+	TextId          *valueobject.ID `json:"textId" db:"text_id"`
+	Type            NodeType        `json:"type" db:"type"`
+	Name            string          `json:"name" db:"name"`
+	Path            string          `json:"path" db:"path"`
+	Visibility      NodeVisibility  `json:"visibility" db:"visibility"`
+	ExpressionOrder ExpressionOrder `json:"expressionOrder" db:"expression_order"`
+	SmartQuery      *SmartQuery     `json:"smartQuery,omitempty" db:"smart_query"`
+	Text            *Text           `json:"text" db:"text"`
+	Expressions     []*Expression   `json:"expressions"`
+	CreatedAt       time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updatedAt" db:"updated_at"`
 }
 
 type NodeView struct {
@@ -95,19 +123,129 @@ type FlatNode struct {
 	Visibility NodeVisibility  `json:"visibility" db:"visibility"`
 }
 
+// TrashedNode is a root of the deleted subtree kept in the group node trash.
+type TrashedNode struct {
+	Id        *valueobject.ID `json:"id" db:"id"`
+	NodeId    *valueobject.ID `json:"nodeId" db:"node_id"`
+	Type      NodeType        `json:"type" db:"type"`
+	Name      string          `json:"name" db:"name"`
+	Path      string          `json:"path" db:"path"`
+	Size      uint            `json:"size" db:"size"`
+	DeletedBy *valueobject.ID `json:"deletedBy" db:"deleted_by"`
+	DeletedAt time.Time       `json:"deletedAt" db:"deleted_at"`
+}
+
+// TreeNode is a node nested into its parent with counts aggregated over the subtree.
+type TreeNode struct {
+	FlatNode
+	TranslationCount uint        `json:"translationCount" db:"translation_count"`
+	DueCount         uint        `json:"dueCount" db:"due_count"`
+	HasChildren      bool        `json:"hasChildren"`
+	Children         []*TreeNode `json:"children"`
+}
+
+// BuildNodeTree nests the ordered flat nodes under the root node (top level when nil).
+// Children deeper than depth levels are left unloaded, zero depth means unlimited.
+func BuildNodeTree(nodes []*TreeNode, rootId *valueobject.ID, depth uint) []*TreeNode {
+	rootPath := ""
+	childrenByPath := make(map[string][]*TreeNode)
+
+	for _, node := range nodes {
+		childrenByPath[node.Path] = append(childrenByPath[node.Path], node)
+
+		if rootId != nil && *node.Id == *rootId {
+			rootPath = node.ChildPath()
+		}
+	}
+
+	if rootId != nil && rootPath == "" {
+		return []*TreeNode{}
+	}
+
+	var nest func(string, uint) []*TreeNode
+	nest = func(path string, level uint) []*TreeNode {
+		children := childrenByPath[path]
+		if children == nil {
+			children = []*TreeNode{}
+		}
+
+		for _, child := range children {
+			child.HasChildren = len(childrenByPath[child.ChildPath()]) > 0
+
+			if depth == 0 || level < depth {
+				child.Children = nest(child.ChildPath(), level+1)
+			}
+		}
+
+		return children
+	}
+
+	return nest(rootPath, 1)
+}
+
+// SplitNodePath converts ltree path of ancestor ids into a slice of ids.
+func SplitNodePath(path string) []valueobject.ID {
+	ids := []valueobject.ID{}
+	if path == "" {
+		return ids
+	}
+
+	for _, label := range strings.Split(path, ".") {
+		id, err := strconv.Atoi(label)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, valueobject.ID(id))
+	}
+
+	return ids
+}
+
+// JoinNodePath builds ltree path from the ancestor ids.
+func JoinNodePath(ids []valueobject.ID) string {
+	labels := make([]string, len(ids))
+	for i, id := range ids {
+		labels[i] = strconv.Itoa(int(id))
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// ChildPath returns the path which children of the node are stored under.
+func (n FlatNode) ChildPath() string {
+	return JoinNodePath(append(SplitNodePath(n.Path), *n.Id))
+}
+
 type NodeRepo interface {
-	Create(*valueobject.ID, Node) (*Node, error)
+	Create(*valueobject.ID, Node, *valueobject.ID) (*Node, error)
 	Get(*valueobject.ID) (*Node, error)
-	View([]valueobject.ID) (*NodeView, error)
+	View([]valueobject.ID, ExpressionOrder, *valueobject.ID) (*NodeView, error)
 	List(*valueobject.ID) ([]*FlatNode, error)
+	ListTree(*valueobject.ID, *valueobject.ID, *valueobject.ID) ([]*TreeNode, error)
+	GetGroupByNode(*valueobject.ID) (*Group, error)
 	FilterSliceIds([]valueobject.ID) ([]valueobject.ID, error)
-	Update(FlatNode) error
-	AttachExpression(*valueobject.ID, Expression) (*Expression, error)
-	DetachExpression(*valueobject.ID, *valueobject.ID) error
-	TranslationsBySlices([]valueobject.ID) ([]*Translation, error)
+	FilterReadableIds(*valueobject.ID, []valueobject.ID) ([]valueobject.ID, error)
+	Update(FlatNode, *valueobject.ID) error
+	AttachExpression(*valueobject.ID, Expression, *valueobject.ID) (*Expression, error)
+	DetachExpression(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	TranslationsBySlices([]valueobject.ID, *valueobject.ID) ([]*Translation, error)
+	HasManualOrder([]valueobject.ID) (bool, error)
+	HasSmart([]valueobject.ID) (bool, error)
+	UpdateSmartQuery(*valueobject.ID, *SmartQuery) error
+	ReorderExpressions(*valueobject.ID, ExpressionOrder, []valueobject.ID, *valueobject.ID) error
 	AvailableTranslations(*valueobject.ID, *valueobject.ID) ([]*Translation, error)
-	AttachTranslation(*valueobject.ID, *valueobject.ID, Translation) (*Translation, error)
-	DetachTranslation(*valueobject.ID, *valueobject.ID) error
-	AttachText(*valueobject.ID, Text) (*Text, error)
-	DetachText(*valueobject.ID) error
+	AttachTranslation(*valueobject.ID, *valueobject.ID, Translation, *valueobject.ID) (*Translation, error)
+	DetachTranslation(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	AttachExpressions(*valueobject.ID, []*Expression, bool, *valueobject.ID) ([]*BulkExpressionResult, error)
+	DetachExpressions(*valueobject.ID, []valueobject.ID, []valueobject.ID, bool, *valueobject.ID) ([]*BulkDetachResult, error)
+	Copy(*valueobject.ID, *valueobject.ID, string, *valueobject.ID) (*valueobject.ID, error)
+	Merge(*valueobject.ID, []valueobject.ID, *valueobject.ID, bool) (map[valueobject.ID]*valueobject.ID, error)
+	Import(*valueobject.ID, []*ImportRow, bool, *valueobject.ID, ActivityAction) ([]*ImportRowReport, error)
+	ImportNodes(*valueobject.ID, string, []*ImportNode, *valueobject.ID) ([]*ImportedNode, error)
+	AttachText(*valueobject.ID, Text, *valueobject.ID) (*Text, error)
+	GetText(*valueobject.ID) (*Text, error)
+	DetachText(*valueobject.ID, *valueobject.ID) error
+	ListRevisions(*valueobject.ID) ([]*NodeRevision, error)
+	GetRevision(*valueobject.ID, uint) (*NodeRevision, error)
+	ApplySnapshot(*valueobject.ID, *NodeSnapshot, *valueobject.ID) (*NodeRevision, error)
 }
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+func id(v uint) *valueobject.ID {
+	id := valueobject.ID(v)
+	return &id
+}
+
+func equalIds(a []uint, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func treeNode(nodeId uint, path string) *TreeNode {
+	return &TreeNode{FlatNode: FlatNode{Id: id(nodeId), Path: path}}
+}
+
+// flatten lists ids of loaded nodes depth first, unloaded children are marked by zero.
+func flatten(nodes []*TreeNode) []uint {
+	res := []uint{}
+	for _, node := range nodes {
+		res = append(res, uint(*node.Id))
+		if node.HasChildren && node.Children == nil {
+			res = append(res, 0)
+		}
+		res = append(res, flatten(node.Children)...)
+	}
+
+	return res
+}
+
+func TestBuildNodeTree(t *testing.T) {
+	nodes := func() []*TreeNode {
+		return []*TreeNode{
+			treeNode(1, ""),
+			treeNode(2, "1"),
+			treeNode(3, "1.2"),
+			treeNode(4, "1"),
+			treeNode(5, ""),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		rootId *valueobject.ID
+		depth  uint
+		want   []uint
+	}{
+		{"whole tree", nil, 0, []uint{1, 2, 3, 4, 5}},
+		{"top level", nil, 1, []uint{1, 0, 5}},
+		{"two levels", nil, 2, []uint{1, 2, 0, 4, 5}},
+		{"subtree", id(1), 0, []uint{2, 3, 4}},
+		{"subtree children", id(1), 1, []uint{2, 0, 4}},
+		{"leaf", id(3), 0, []uint{}},
+		{"missing root", id(9), 0, []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := flatten(BuildNodeTree(nodes(), tt.rootId, tt.depth))
+			if !equalIds(got, tt.want) {
+				t.Errorf("tree = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
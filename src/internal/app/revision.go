@@ -0,0 +1,219 @@
+package app
+
+import (
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// RevisionBaseline marks the first revision of nodes which existed before the history was kept.
+const RevisionBaseline ActivityAction = "node.baseline"
+
+type SnapshotText struct {
+	Id    *valueobject.ID `json:"id"`
+	Title string          `json:"title"`
+}
+
+type SnapshotTranslation struct {
+	Id      *valueobject.ID `json:"id"`
+	Value   string          `json:"value"`
+	Comment string          `json:"comment"`
+}
+
+type SnapshotExpression struct {
+	Id           *valueobject.ID        `json:"id"`
+	Value        string                 `json:"value"`
+	Position     *int                   `json:"position"`
+	Translations []*SnapshotTranslation `json:"translations"`
+}
+
+// NodeSnapshot is the content of the node at the moment of the revision.
+type NodeSnapshot struct {
+	Name        string                `json:"name"`
+	Text        *SnapshotText         `json:"text"`
+	Expressions []*SnapshotExpression `json:"expressions"`
+}
+
+type NodeRevision struct {
+	Id            *valueobject.ID `json:"id" db:"id"`
+	NodeId        *valueobject.ID `json:"nodeId" db:"node_id"`
+	Number        uint            `json:"number" db:"number"`
+	ActorId       *valueobject.ID `json:"actorId" db:"actor_id"`
+	ActorUsername *string         `json:"actorUsername" db:"actor_username"`
+	Action        ActivityAction  `json:"action" db:"action"`
+	Snapshot      *NodeSnapshot   `json:"snapshot,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt" db:"created_at"`
+}
+
+type NameChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type TextChange struct {
+	Before *SnapshotText `json:"before"`
+	After  *SnapshotText `json:"after"`
+}
+
+type TranslationChange struct {
+	ExpressionId    *valueobject.ID      `json:"expressionId"`
+	ExpressionValue string               `json:"expressionValue"`
+	Translation     *SnapshotTranslation `json:"translation"`
+}
+
+type NodeDiff struct {
+	From                 uint                  `json:"from"`
+	To                   uint                  `json:"to"`
+	Name                 *NameChange           `json:"name,omitempty"`
+	Text                 *TextChange           `json:"text,omitempty"`
+	AttachedExpressions  []*SnapshotExpression `json:"attachedExpressions"`
+	DetachedExpressions  []*SnapshotExpression `json:"detachedExpressions"`
+	AttachedTranslations []*TranslationChange  `json:"attachedTranslations"`
+	DetachedTranslations []*TranslationChange  `json:"detachedTranslations"`
+	Reordered            bool                  `json:"reordered"`
+}
+
+func textId(text *SnapshotText) *valueobject.ID {
+	if text == nil {
+		return nil
+	}
+
+	return text.Id
+}
+
+func sameId(a *valueobject.ID, b *valueobject.ID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func snapshotExpressions(snapshot *NodeSnapshot) map[valueobject.ID]*SnapshotExpression {
+	expressions := make(map[valueobject.ID]*SnapshotExpression)
+	for _, expr := range snapshot.Expressions {
+		expressions[*expr.Id] = expr
+	}
+
+	return expressions
+}
+
+// translationChanges lists translations of the expression in a which are missing in b.
+func translationChanges(a *SnapshotExpression, b *SnapshotExpression) []*TranslationChange {
+	kept := make(map[valueobject.ID]bool)
+	if b != nil {
+		for _, tr := range b.Translations {
+			kept[*tr.Id] = true
+		}
+	}
+
+	changes := []*TranslationChange{}
+	for _, tr := range a.Translations {
+		if !kept[*tr.Id] {
+			changes = append(changes, &TranslationChange{a.Id, a.Value, tr})
+		}
+	}
+
+	return changes
+}
+
+// DiffSnapshots tells what has to change in the snapshot from to get the snapshot to.
+// Translations of attached or detached expressions are listed only within them.
+func DiffSnapshots(from *NodeSnapshot, to *NodeSnapshot) *NodeDiff {
+	diff := &NodeDiff{
+		AttachedExpressions:  []*SnapshotExpression{},
+		DetachedExpressions:  []*SnapshotExpression{},
+		AttachedTranslations: []*TranslationChange{},
+		DetachedTranslations: []*TranslationChange{},
+	}
+
+	if from.Name != to.Name {
+		diff.Name = &NameChange{from.Name, to.Name}
+	}
+
+	if !sameId(textId(from.Text), textId(to.Text)) {
+		diff.Text = &TextChange{from.Text, to.Text}
+	}
+
+	fromExpressions := snapshotExpressions(from)
+	toExpressions := snapshotExpressions(to)
+
+	for _, expr := range to.Expressions {
+		if before, ok := fromExpressions[*expr.Id]; ok {
+			diff.AttachedTranslations = append(diff.AttachedTranslations, translationChanges(expr, before)...)
+		} else {
+			diff.AttachedExpressions = append(diff.AttachedExpressions, expr)
+		}
+	}
+
+	for _, expr := range from.Expressions {
+		if after, ok := toExpressions[*expr.Id]; ok {
+			diff.DetachedTranslations = append(diff.DetachedTranslations, translationChanges(expr, after)...)
+		} else {
+			diff.DetachedExpressions = append(diff.DetachedExpressions, expr)
+		}
+	}
+
+	// Order matters only among expressions kept in both snapshots.
+	kept := []valueobject.ID{}
+	for _, expr := range from.Expressions {
+		if _, ok := toExpressions[*expr.Id]; ok {
+			kept = append(kept, *expr.Id)
+		}
+	}
+	idx := 0
+	for _, expr := range to.Expressions {
+		if _, ok := fromExpressions[*expr.Id]; !ok {
+			continue
+		}
+		if kept[idx] != *expr.Id {
+			diff.Reordered = true
+			break
+		}
+		idx++
+	}
+
+	return diff
+}
+
+// RemapSnapshotExpressions replaces ids of merged expressions and translations with ids of those
+// they were merged into. An expression met twice keeps the first position and gets translations
+// of both, a translation met twice within an expression is kept once.
+func RemapSnapshotExpressions(expressions []*SnapshotExpression, expressionIds map[valueobject.ID]valueobject.ID, translationIds map[valueobject.ID]valueobject.ID) []*SnapshotExpression {
+	remapped := []*SnapshotExpression{}
+	seen := make(map[valueobject.ID]*SnapshotExpression)
+
+	for _, expr := range expressions {
+		exprId := *expr.Id
+		if keepId, ok := expressionIds[exprId]; ok {
+			exprId = keepId
+		}
+
+		target, ok := seen[exprId]
+		if !ok {
+			target = &SnapshotExpression{Id: &exprId, Value: expr.Value, Position: expr.Position, Translations: []*SnapshotTranslation{}}
+			seen[exprId] = target
+			remapped = append(remapped, target)
+		}
+
+		for _, tr := range expr.Translations {
+			trId := *tr.Id
+			if keepId, ok := translationIds[trId]; ok {
+				trId = keepId
+			}
+
+			duplicate := false
+			for _, existing := range target.Translations {
+				if *existing.Id == trId {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				target.Translations = append(target.Translations, &SnapshotTranslation{Id: &trId, Value: tr.Value, Comment: tr.Comment})
+			}
+		}
+	}
+
+	return remapped
+}
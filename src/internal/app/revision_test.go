@@ -0,0 +1,194 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+func expression(exprId uint, translationIds ...uint) *SnapshotExpression {
+	expr := &SnapshotExpression{Id: id(exprId), Translations: []*SnapshotTranslation{}}
+	for _, trId := range translationIds {
+		expr.Translations = append(expr.Translations, &SnapshotTranslation{Id: id(trId)})
+	}
+
+	return expr
+}
+
+// ids lists expression ids, translation changes are listed as expression and translation id pairs.
+func ids(v interface{}) []uint {
+	res := []uint{}
+	switch x := v.(type) {
+	case []*SnapshotExpression:
+		for _, expr := range x {
+			res = append(res, uint(*expr.Id))
+		}
+	case []*TranslationChange:
+		for _, change := range x {
+			res = append(res, uint(*change.ExpressionId), uint(*change.Translation.Id))
+		}
+	}
+
+	return res
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	tests := []struct {
+		name                 string
+		from                 *NodeSnapshot
+		to                   *NodeSnapshot
+		renamed              bool
+		text                 bool
+		attachedExpressions  []uint
+		detachedExpressions  []uint
+		attachedTranslations []uint
+		detachedTranslations []uint
+		reordered            bool
+	}{
+		{
+			name: "same",
+			from: &NodeSnapshot{Name: "a", Text: &SnapshotText{Id: id(1)}, Expressions: []*SnapshotExpression{expression(1, 10)}},
+			to:   &NodeSnapshot{Name: "a", Text: &SnapshotText{Id: id(1)}, Expressions: []*SnapshotExpression{expression(1, 10)}},
+		},
+		{
+			name:    "renamed",
+			from:    &NodeSnapshot{Name: "a"},
+			to:      &NodeSnapshot{Name: "b"},
+			renamed: true,
+		},
+		{
+			name: "text attached",
+			from: &NodeSnapshot{},
+			to:   &NodeSnapshot{Text: &SnapshotText{Id: id(1)}},
+			text: true,
+		},
+		{
+			name: "text detached",
+			from: &NodeSnapshot{Text: &SnapshotText{Id: id(1)}},
+			to:   &NodeSnapshot{},
+			text: true,
+		},
+		{
+			name: "text replaced",
+			from: &NodeSnapshot{Text: &SnapshotText{Id: id(1)}},
+			to:   &NodeSnapshot{Text: &SnapshotText{Id: id(2)}},
+			text: true,
+		},
+		{
+			name:                "expressions attached and detached with their translations",
+			from:                &NodeSnapshot{Expressions: []*SnapshotExpression{expression(1, 10), expression(2, 20)}},
+			to:                  &NodeSnapshot{Expressions: []*SnapshotExpression{expression(2, 20), expression(3, 30)}},
+			attachedExpressions: []uint{3},
+			detachedExpressions: []uint{1},
+		},
+		{
+			name:                 "translations of kept expressions",
+			from:                 &NodeSnapshot{Expressions: []*SnapshotExpression{expression(1, 10, 11), expression(2)}},
+			to:                   &NodeSnapshot{Expressions: []*SnapshotExpression{expression(1, 11, 12), expression(2, 20)}},
+			attachedTranslations: []uint{1, 12, 2, 20},
+			detachedTranslations: []uint{1, 10},
+		},
+		{
+			name:      "reordered",
+			from:      &NodeSnapshot{Expressions: []*SnapshotExpression{expression(1), expression(2), expression(3)}},
+			to:        &NodeSnapshot{Expressions: []*SnapshotExpression{expression(2), expression(1), expression(3)}},
+			reordered: true,
+		},
+		{
+			name:                "order of kept expressions is the same",
+			from:                &NodeSnapshot{Expressions: []*SnapshotExpression{expression(1), expression(2), expression(3)}},
+			to:                  &NodeSnapshot{Expressions: []*SnapshotExpression{expression(4), expression(1), expression(3)}},
+			attachedExpressions: []uint{4},
+			detachedExpressions: []uint{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffSnapshots(tt.from, tt.to)
+
+			if (diff.Name != nil) != tt.renamed {
+				t.Errorf("name change = %v, want %v", diff.Name, tt.renamed)
+			}
+			if diff.Name != nil && (diff.Name.Before != tt.from.Name || diff.Name.After != tt.to.Name) {
+				t.Errorf("name change = %+v", diff.Name)
+			}
+			if (diff.Text != nil) != tt.text {
+				t.Errorf("text change = %v, want %v", diff.Text, tt.text)
+			}
+
+			checks := []struct {
+				field string
+				got   []uint
+				want  []uint
+			}{
+				{"attached expressions", ids(diff.AttachedExpressions), tt.attachedExpressions},
+				{"detached expressions", ids(diff.DetachedExpressions), tt.detachedExpressions},
+				{"attached translations", ids(diff.AttachedTranslations), tt.attachedTranslations},
+				{"detached translations", ids(diff.DetachedTranslations), tt.detachedTranslations},
+			}
+			for _, check := range checks {
+				if check.want == nil {
+					check.want = []uint{}
+				}
+				if !equalIds(check.got, check.want) {
+					t.Errorf("%s = %v, want %v", check.field, check.got, check.want)
+				}
+			}
+
+			if diff.Reordered != tt.reordered {
+				t.Errorf("reordered = %v, want %v", diff.Reordered, tt.reordered)
+			}
+		})
+	}
+}
+
+func TestRemapSnapshotExpressions(t *testing.T) {
+	expressionIds := map[valueobject.ID]valueobject.ID{2: 1, 4: 3}
+	translationIds := map[valueobject.ID]valueobject.ID{20: 10}
+
+	tests := []struct {
+		name         string
+		expressions  []*SnapshotExpression
+		want         []uint
+		translations [][]uint
+	}{
+		{
+			name:         "untouched",
+			expressions:  []*SnapshotExpression{expression(5, 50)},
+			want:         []uint{5},
+			translations: [][]uint{{50}},
+		},
+		{
+			name:         "dropped expression is replaced",
+			expressions:  []*SnapshotExpression{expression(5), expression(4, 40)},
+			want:         []uint{5, 3},
+			translations: [][]uint{{}, {40}},
+		},
+		{
+			name:         "dropped expression meets the kept one",
+			expressions:  []*SnapshotExpression{expression(2, 20, 21), expression(5), expression(1, 10, 11)},
+			want:         []uint{1, 5},
+			translations: [][]uint{{10, 21, 11}, {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RemapSnapshotExpressions(tt.expressions, expressionIds, translationIds)
+
+			if !equalIds(ids(got), tt.want) {
+				t.Fatalf("expressions = %v, want %v", ids(got), tt.want)
+			}
+			for i, expr := range got {
+				trIds := []uint{}
+				for _, tr := range expr.Translations {
+					trIds = append(trIds, uint(*tr.Id))
+				}
+				if !equalIds(trIds, tt.translations[i]) {
+					t.Errorf("translations of %d = %v, want %v", *expr.Id, trIds, tt.translations[i])
+				}
+			}
+		})
+	}
+}
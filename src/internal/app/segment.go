@@ -0,0 +1,25 @@
+package app
+
+import "github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+
+// Token is a piece of segmented content. Pieces between words keep spaces and punctuation,
+// so joined tokens give the content back. Offset counts characters from the content start.
+type Token struct {
+	Value        string          `json:"value"`
+	Offset       int             `json:"offset"`
+	IsWord       bool            `json:"isWord"`
+	ExpressionId *valueobject.ID `json:"expressionId,omitempty"`
+}
+
+// KnownExpression is an expression of the language found among the tokens.
+type KnownExpression struct {
+	Id    *valueobject.ID `json:"id"`
+	Value string          `json:"value"`
+	Count uint            `json:"count"`
+}
+
+type Segmentation struct {
+	LangCode    string             `json:"langCode"`
+	Tokens      []*Token           `json:"tokens"`
+	Expressions []*KnownExpression `json:"expressions"`
+}
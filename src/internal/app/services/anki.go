@@ -0,0 +1,8 @@
+package services
+
+import "github.com/alexkarpovich/lst-api/src/internal/app"
+
+type AnkiService interface {
+	ReadPackage([]byte) (*app.DeckPackage, error)
+	WritePackage(app.DeckPackage) ([]byte, error)
+}
@@ -0,0 +1,8 @@
+package services
+
+import "github.com/alexkarpovich/lst-api/src/internal/app"
+
+type BackupService interface {
+	Pack(*app.GroupBackup) ([]byte, error)
+	Unpack([]byte) (*app.GroupBackup, error)
+}
@@ -0,0 +1,11 @@
+package services
+
+import (
+	"io"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+)
+
+type DictionaryService interface {
+	Parse(io.Reader, app.DictionaryFormat) ([]*app.DictionaryEntry, error)
+}
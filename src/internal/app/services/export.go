@@ -0,0 +1,7 @@
+package services
+
+import "github.com/alexkarpovich/lst-api/src/internal/app"
+
+type ExportService interface {
+	Render(*app.VocabularyExport, app.ExportFormat, app.ExportLayout) ([]byte, error)
+}
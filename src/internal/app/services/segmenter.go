@@ -0,0 +1,12 @@
+package services
+
+import "github.com/alexkarpovich/lst-api/src/internal/app"
+
+// Segmenter splits content into word tokens and the tokens between them.
+type Segmenter interface {
+	Segment(string) ([]*app.Token, error)
+}
+
+type SegmenterService interface {
+	Segmenter(langCode string) Segmenter
+}
@@ -0,0 +1,13 @@
+package services
+
+import "github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+
+// Transcriber makes the transcription of an expression value, it is empty when the value can't be transcribed.
+type Transcriber interface {
+	Transcribe(string) (string, error)
+}
+
+type TranscriberService interface {
+	// Transcriber returns the transcriber of the transcription type or nil when there is none.
+	Transcriber(*valueobject.ID) (Transcriber, error)
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// SmartRule tells which links of the group static slices a smart slice is made of.
+type SmartRule string
+
+const (
+	// SmartRuleFailed takes translations the viewer failed at least Failures times in the last Days
+	SmartRuleFailed SmartRule = "failed"
+	// SmartRuleRecent takes expressions added to slices in the last Days
+	SmartRuleRecent SmartRule = "recent"
+	// SmartRuleNoTranscription takes translations without a transcription of the group type
+	SmartRuleNoTranscription SmartRule = "no-transcription"
+)
+
+// SmartQuery is a stored query of the smart slice. It is evaluated over static slices
+// of the group, limited to subtrees of the source nodes when they are given.
+// Zero Days means no time limit. OwnerId is the member who set the query, failures
+// are counted for whoever views the slice.
+type SmartQuery struct {
+	Rule      SmartRule        `json:"rule"`
+	Days      uint             `json:"days,omitempty"`
+	Failures  uint             `json:"failures,omitempty"`
+	OwnerId   *valueobject.ID  `json:"ownerId,omitempty"`
+	SourceIds []valueobject.ID `json:"sourceIds,omitempty"`
+}
+
+func (q SmartQuery) Value() (driver.Value, error) {
+	return json.Marshal(q)
+}
+
+func (q *SmartQuery) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &q)
+}
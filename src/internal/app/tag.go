@@ -0,0 +1,63 @@
+package app
+
+import (
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// Tag is a user-defined label of the group which nodes and node-expression links can be marked with.
+type Tag struct {
+	Id        *valueobject.ID `json:"id" db:"id"`
+	GroupId   *valueobject.ID `json:"groupId" db:"group_id"`
+	Name      string          `json:"name" db:"name"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
+}
+
+// NodeTags are tags of the node itself and of its expression links by expression id.
+type NodeTags struct {
+	Tags        []*Tag                    `json:"tags"`
+	Expressions map[valueobject.ID][]*Tag `json:"expressions"`
+}
+
+// TagMatch tells whether a saved filter needs any or all of its tags.
+type TagMatch string
+
+const (
+	TagMatchAny TagMatch = "any"
+	TagMatchAll TagMatch = "all"
+)
+
+// SavedFilter is a virtual slice made of group expressions whose links match the tags.
+// A link carries its own tags and the tags of its slice and the slice ancestors.
+// Filter is limited to subtrees of the nodes when they are given.
+type SavedFilter struct {
+	Id        *valueobject.ID  `json:"id" db:"id"`
+	GroupId   *valueobject.ID  `json:"groupId" db:"group_id"`
+	OwnerId   *valueobject.ID  `json:"ownerId" db:"owner_id"`
+	Name      string           `json:"name" db:"name"`
+	TagIds    []valueobject.ID `json:"tagIds" db:"tag_ids"`
+	Match     TagMatch         `json:"match" db:"match"`
+	NodeIds   []valueobject.ID `json:"nodeIds" db:"node_ids"`
+	CreatedAt time.Time        `json:"createdAt" db:"created_at"`
+}
+
+type TagRepo interface {
+	Create(Tag) (*Tag, error)
+	Get(*valueobject.ID) (*Tag, error)
+	List(*valueobject.ID) ([]*Tag, error)
+	Rename(*valueobject.ID, string) error
+	Delete(*valueobject.ID) error
+	NodeTags(*valueobject.ID) (*NodeTags, error)
+	TagNode(*valueobject.ID, *valueobject.ID) error
+	UntagNode(*valueobject.ID, *valueobject.ID) error
+	TagExpression(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	UntagExpression(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	CreateFilter(SavedFilter) (*SavedFilter, error)
+	GetFilter(*valueobject.ID) (*SavedFilter, error)
+	ListFilters(*valueobject.ID, *valueobject.ID) ([]*SavedFilter, error)
+	UpdateFilter(SavedFilter) error
+	DeleteFilter(*valueobject.ID) error
+	FilterExpressions(*SavedFilter) ([]*Expression, error)
+	TranslationsByFilters([]valueobject.ID) ([]*Translation, error)
+}
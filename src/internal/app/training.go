@@ -47,6 +47,7 @@ type Training struct {
 	Type                TrainingType     `json:"type" db:"type"`
 	TranscriptionTypeId *valueobject.ID  `json:"transcriptionTypeId" db:"transcription_type"`
 	Slices              []valueobject.ID `json:"slices" db:"slices"`
+	Filters             []valueobject.ID `json:"filters" db:"filters"`
 	Items               []*TrainingItem  `json:"-"`
 	Meta                *TrainingMeta    `json:"meta" db:"meta"`
 }
@@ -61,5 +62,6 @@ type TrainingRepo interface {
 	NextItem(*valueobject.ID) (*TrainingItem, error)
 	ItemAnswers(*valueobject.ID) ([]*TrainingAnswer, error)
 	MarkItemAsComplete(*valueobject.ID) error
+	MarkItemAsFailed(*valueobject.ID) error
 	HasCreatePermission(*valueobject.ID, []valueobject.ID) bool
 }
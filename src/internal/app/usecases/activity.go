@@ -0,0 +1,57 @@
+package usecases
+
+import (
+	"log"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 200
+)
+
+// logActivity reports failed audit writes without failing the use case itself.
+func logActivity(err error) {
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+type ActivityInteractor struct {
+	ActivityRepo app.ActivityRepo
+	GroupRepo    app.GroupRepo
+}
+
+func NewActivityInteractor(ar app.ActivityRepo, gr app.GroupRepo) *ActivityInteractor {
+	return &ActivityInteractor{ar, gr}
+}
+
+func (i *ActivityInteractor) List(actorId *valueobject.ID, groupId *valueobject.ID, filter app.ActivityFilter) (*app.ActivityPage, error) {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only active member can view group activity.")
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultActivityLimit
+	} else if filter.Limit > maxActivityLimit {
+		filter.Limit = maxActivityLimit
+	}
+
+	activities, err := i.ActivityRepo.List(groupId, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &app.ActivityPage{
+		Items: activities,
+	}
+
+	if uint(len(activities)) == filter.Limit {
+		page.NextCursor = activities[len(activities)-1].Id
+	}
+
+	return page, nil
+}
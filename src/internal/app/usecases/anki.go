@@ -0,0 +1,338 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+const maxNodeName = 128
+
+func ankiDeckTitle(name string) string {
+	parts := strings.Split(name, app.AnkiDeckSeparator)
+	title := []rune(strings.TrimSpace(parts[len(parts)-1]))
+	if len(title) > maxNodeName {
+		title = title[:maxNodeName]
+	}
+
+	if len(title) == 0 {
+		return "Untitled"
+	}
+
+	return string(title)
+}
+
+func ankiDeckParent(name string) string {
+	if idx := strings.LastIndex(name, app.AnkiDeckSeparator); idx >= 0 {
+		return name[:idx]
+	}
+
+	return ""
+}
+
+// ankiImportRows maps note fields to vocabulary rows, line is the note number in the deck.
+func ankiImportRows(notes []*app.DeckNote, opts app.ImportOptions) ([]*app.ImportRow, []*app.ImportRowReport) {
+	rows := []*app.ImportRow{}
+	invalid := []*app.ImportRowReport{}
+
+	for idx, note := range notes {
+		row, empty := importRowFromFields(uint(idx+1), note.Fields, opts)
+		if empty {
+			continue
+		}
+
+		if err := checkImportRow(row); err != nil {
+			invalid = append(invalid, &app.ImportRowReport{
+				Line:       row.Line,
+				Expression: row.Expression,
+				Status:     app.ImportError,
+				Error:      err.Error(),
+			})
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, invalid
+}
+
+// ImportAnki recreates decks of .apkg file inside the folder of the group, top level when no folder given.
+// Decks with subdecks become folders and their own notes go into a same-named slice inside.
+func (i *GroupInteractor) ImportAnki(actorId *valueobject.ID, groupId *valueobject.ID, folderId *valueobject.ID, data []byte, opts app.ImportOptions) (*app.AnkiImportReport, error) {
+	if len(opts.Columns) == 0 {
+		opts.Columns = defaultImportColumns
+	}
+
+	if err := validateImportColumns(opts.Columns); err != nil {
+		return nil, err
+	}
+
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only non-reader user of a group can import decks.", app.UserAdmin, app.UserEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	path := ""
+	if folderId != nil {
+		nodes, err := i.NodeRepo.List(groupId)
+		if err != nil {
+			return nil, err
+		}
+
+		var folder *app.FlatNode
+		for _, n := range nodes {
+			if *n.Id == *folderId {
+				folder = n
+				break
+			}
+		}
+
+		if folder == nil || folder.Type != app.NodeFolder {
+			return nil, errors.New("Target folder doesn't belong to the group.")
+		}
+
+		path = folder.ChildPath()
+	}
+
+	pkg, err := i.Anki.ReadPackage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decks := make(map[string]*app.Deck)
+	needed := make(map[string]bool)
+	hasChildren := make(map[string]bool)
+
+	for _, deck := range pkg.Decks {
+		if len(deck.Notes) == 0 {
+			continue
+		}
+
+		if len(deck.Notes) > maxImportRows {
+			return nil, fmt.Errorf("Deck \"%s\" contains more than %d notes.", deck.Name, maxImportRows)
+		}
+
+		decks[deck.Name] = deck
+		for name := deck.Name; name != ""; name = ankiDeckParent(name) {
+			needed[name] = true
+			if parent := ankiDeckParent(name); parent != "" {
+				hasChildren[parent] = true
+			}
+		}
+	}
+
+	if len(needed) == 0 {
+		return nil, errors.New("Anki package has no notes to import.")
+	}
+
+	// Parent deck names are prefixes of their children, so they always go first.
+	names := []string{}
+	for name := range needed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := []*app.ImportNode{}
+	deckNames := []string{}
+	invalidRows := [][]*app.ImportRowReport{}
+	folderIdx := make(map[string]int)
+
+	for _, name := range names {
+		var parent *int
+		if idx, ok := folderIdx[ankiDeckParent(name)]; ok {
+			parent = &idx
+		}
+
+		if hasChildren[name] {
+			nodes = append(nodes, &app.ImportNode{
+				Type:   app.NodeFolder,
+				Name:   ankiDeckTitle(name),
+				Parent: parent,
+			})
+			deckNames = append(deckNames, "")
+			invalidRows = append(invalidRows, nil)
+
+			idx := len(nodes) - 1
+			folderIdx[name] = idx
+			parent = &idx
+		}
+
+		deck, ok := decks[name]
+		if !ok {
+			continue
+		}
+
+		rows, invalid := ankiImportRows(deck.Notes, opts)
+		nodes = append(nodes, &app.ImportNode{
+			Type:   app.NodeSlice,
+			Name:   ankiDeckTitle(name),
+			Parent: parent,
+			Rows:   rows,
+		})
+		deckNames = append(deckNames, name)
+		invalidRows = append(invalidRows, invalid)
+	}
+
+	imported, err := i.NodeRepo.ImportNodes(groupId, path, nodes, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &app.AnkiImportReport{Decks: []*app.AnkiDeckReport{}}
+
+	for idx, result := range imported {
+		node := result.Node
+
+		logActivity(i.ActivityRepo.Log(app.Activity{
+			GroupId:    groupId,
+			ActorId:    actorId,
+			Action:     app.ActivityNodeCreate,
+			TargetType: app.TargetNode,
+			TargetId:   node.Id,
+			After:      node,
+		}))
+
+		if node.Type == app.NodeFolder {
+			report.Folders++
+			continue
+		}
+		report.Slices++
+
+		deckReport := &app.AnkiDeckReport{
+			Deck:   deckNames[idx],
+			NodeId: node.Id,
+			Report: buildImportReport(result.Reports, invalidRows[idx], false),
+		}
+		report.Decks = append(report.Decks, deckReport)
+
+		logActivity(i.ActivityRepo.Log(app.Activity{
+			GroupId:    groupId,
+			ActorId:    actorId,
+			Action:     app.ActivityNodeImport,
+			TargetType: app.TargetNode,
+			TargetId:   node.Id,
+			After: map[string]interface{}{
+				"deck":      deckNames[idx],
+				"created":   deckReport.Report.Created,
+				"linked":    deckReport.Report.Linked,
+				"duplicate": deckReport.Report.Duplicate,
+				"errors":    deckReport.Report.Errors,
+			},
+		}))
+	}
+
+	return report, nil
+}
+
+// ExportAnki packs the slice or every slice of the folder subtree into .apkg file.
+// Deck names follow folder names from the exported node down to the slice.
+func (i *NodeInteractor) ExportAnki(actorId *valueobject.ID, nodeId *valueobject.ID) (*app.Node, []byte, error) {
+	if err := i.checkReader(actorId, []valueobject.ID{*nodeId}); err != nil {
+		return nil, nil, err
+	}
+
+	root, err := i.NodeRepo.Get(nodeId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	group, err := i.NodeRepo.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes, err := i.NodeRepo.List(group.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rootNode *app.FlatNode
+	for _, node := range nodes {
+		if *node.Id == *nodeId {
+			rootNode = node
+			break
+		}
+	}
+
+	if rootNode == nil {
+		return nil, nil, errors.New("Node doesn't belong to the group.")
+	}
+
+	// Deck names are resolved by the node path below the exported node.
+	names := map[valueobject.ID]string{*rootNode.Id: rootNode.Name}
+	rootDepth := len(app.SplitNodePath(rootNode.Path))
+	subtree := []*app.FlatNode{rootNode}
+	childPath := rootNode.ChildPath()
+
+	for _, node := range nodes {
+		if node.Path == childPath || strings.HasPrefix(node.Path, childPath+".") {
+			names[*node.Id] = node.Name
+			subtree = append(subtree, node)
+		}
+	}
+
+	pkg := app.DeckPackage{Decks: []*app.Deck{}}
+	for _, node := range subtree {
+		if !node.Type.IsSlice() {
+			continue
+		}
+
+		parts := []string{}
+		for _, id := range app.SplitNodePath(node.Path)[rootDepth:] {
+			parts = append(parts, names[id])
+		}
+		parts = append(parts, node.Name)
+
+		view, err := i.NodeRepo.View([]valueobject.ID{*node.Id}, "", actorId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		deck := &app.Deck{
+			Name:  strings.Join(parts, app.AnkiDeckSeparator),
+			Notes: []*app.DeckNote{},
+		}
+
+		for _, expr := range view.Expressions {
+			entry := exportEntry(expr)
+			translations := []string{}
+			transcriptions := entry.Transcriptions
+			seen := make(map[string]bool)
+			for _, value := range transcriptions {
+				seen[value] = true
+			}
+
+			for _, tr := range entry.Translations {
+				translations = append(translations, tr.Value)
+				for _, value := range tr.Transcriptions {
+					if !seen[value] {
+						seen[value] = true
+						transcriptions = append(transcriptions, value)
+					}
+				}
+			}
+
+			deck.Notes = append(deck.Notes, &app.DeckNote{
+				Fields: []string{expr.Value, strings.Join(translations, "; "), strings.Join(transcriptions, "; ")},
+			})
+		}
+
+		pkg.Decks = append(pkg.Decks, deck)
+	}
+
+	if len(pkg.Decks) == 0 {
+		return nil, nil, errors.New("There are no slices to export.")
+	}
+
+	data, err := i.Anki.WritePackage(pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return root, data, nil
+}
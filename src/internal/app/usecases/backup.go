@@ -0,0 +1,177 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/pkg"
+)
+
+// BackupGroup packs the whole group into a versioned archive which can be restored with RestoreGroupBackup.
+func (i *GroupInteractor) BackupGroup(actorId *valueobject.ID, groupId *valueobject.ID) (*app.GroupBackup, []byte, error) {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can backup group.", app.UserAdmin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backup, err := i.GroupRepo.Backup(groupId)
+	if err != nil {
+		log.Println(err)
+		return nil, nil, err
+	}
+
+	data, err := i.Backup.Pack(backup)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityGroupBackup,
+		TargetType: app.TargetGroup,
+		TargetId:   groupId,
+		After: map[string]interface{}{
+			"version": backup.Version,
+			"nodes":   len(backup.Nodes),
+			"members": len(backup.Members),
+		},
+	}))
+
+	return backup, data, nil
+}
+
+// checkBackupNodes validates what the archive tells about nodes, archives can be edited by hand.
+func checkBackupNodes(nodes []*app.BackupNode) error {
+	for _, node := range nodes {
+		switch node.Type {
+		case app.NodeFolder, app.NodeSlice:
+			node.SmartQuery = nil
+		case app.NodeSmart:
+			if node.SmartQuery == nil {
+				return fmt.Errorf("Invalid backup, smart node %d has no query.", node.Key)
+			}
+		default:
+			return fmt.Errorf("Invalid backup, node %d has unknown type.", node.Key)
+		}
+
+		if node.ExpressionOrder != "" {
+			if err := checkExpressionOrder(node.ExpressionOrder); err != nil {
+				return err
+			}
+		}
+
+		node.Name = strings.TrimSpace(node.Name)
+		node.Visibility = app.NodePrivate
+	}
+
+	return nil
+}
+
+// invitedRole caps the archived role at editor, admin rights aren't granted by an archive.
+func invitedRole(role app.UserRole) app.UserRole {
+	switch role {
+	case app.UserAdmin, app.UserEditor:
+		return app.UserEditor
+	}
+
+	return app.UserReader
+}
+
+// RestoreGroupBackup recreates the archived group as a new group with the actor as its admin.
+// When asked, archived members having an account with the same email get invited again.
+func (i *GroupInteractor) RestoreGroupBackup(actorId *valueobject.ID, data []byte, name string, inviteMembers bool) (*app.RestoreReport, error) {
+	backup, err := i.Backup.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkBackupNodes(backup.Nodes); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = backup.Group.Name
+	}
+	if name == "" {
+		return nil, errors.New("Group name is required.")
+	}
+
+	report, err := i.GroupRepo.RestoreBackup(actorId, backup, name)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    report.GroupId,
+		ActorId:    actorId,
+		Action:     app.ActivityGroupRestoreBackup,
+		TargetType: app.TargetGroup,
+		TargetId:   report.GroupId,
+		Before: map[string]interface{}{
+			"version":   backup.Version,
+			"name":      backup.Group.Name,
+			"createdAt": backup.CreatedAt,
+		},
+	}))
+
+	if !inviteMembers {
+		return report, nil
+	}
+
+	actor, err := i.UserRepo.Get(actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, archived := range backup.Members {
+		email := valueobject.EmailAddress(archived.Email)
+		if email == actor.Email {
+			continue
+		}
+
+		user, err := i.UserRepo.FindByEmail(email)
+		if err != nil {
+			report.Skipped = append(report.Skipped, archived.Username)
+			continue
+		}
+
+		// Restored members have to accept the invitation, archived admins are invited as editors.
+		member := app.GroupMember{
+			Id:             user.Id,
+			Role:           invitedRole(archived.Role),
+			Status:         app.MemberPending,
+			Token:          pkg.RandomString(128),
+			TokenExpiresAt: time.Now().Add(3 * 24 * time.Hour),
+		}
+
+		err = i.GroupRepo.AttachUser(report.GroupId, member)
+		if err != nil {
+			log.Println(err)
+			report.Skipped = append(report.Skipped, archived.Username)
+			continue
+		}
+
+		logActivity(i.ActivityRepo.Log(app.Activity{
+			GroupId:    report.GroupId,
+			ActorId:    actorId,
+			Action:     app.ActivityMemberInvite,
+			TargetType: app.TargetMember,
+			TargetId:   user.Id,
+			After:      map[string]interface{}{"role": member.Role, "status": member.Status},
+		}))
+
+		go i.Email.SendGroupInvitation(user.Email, member.Token)
+
+		report.Invited = append(report.Invited, user.Username)
+	}
+
+	return report, nil
+}
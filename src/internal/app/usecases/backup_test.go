@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+)
+
+func TestCheckBackupNodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    app.BackupNode
+		wantErr bool
+	}{
+		{"folder", app.BackupNode{Type: app.NodeFolder}, false},
+		{"slice drops smart query", app.BackupNode{Type: app.NodeSlice, SmartQuery: &app.SmartQuery{}}, false},
+		{"smart", app.BackupNode{Type: app.NodeSmart, SmartQuery: &app.SmartQuery{}}, false},
+		{"smart without query", app.BackupNode{Type: app.NodeSmart}, true},
+		{"unknown type", app.BackupNode{Type: app.NodeType(42)}, true},
+		{"known order", app.BackupNode{Type: app.NodeSlice, ExpressionOrder: app.ExpressionOrderAlpha}, false},
+		{"unknown order", app.BackupNode{Type: app.NodeSlice, ExpressionOrder: "random"}, true},
+		{"public becomes private", app.BackupNode{Type: app.NodeSlice, Visibility: app.NodePublic}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := tt.node
+			err := checkBackupNodes([]*app.BackupNode{&node})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkBackupNodes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if node.Visibility != app.NodePrivate {
+				t.Errorf("visibility = %v, want private", node.Visibility)
+			}
+			if node.Type != app.NodeSmart && node.SmartQuery != nil {
+				t.Errorf("smart query kept for node type %v", node.Type)
+			}
+		})
+	}
+}
+
+func TestInvitedRole(t *testing.T) {
+	tests := []struct {
+		role app.UserRole
+		want app.UserRole
+	}{
+		{app.UserAdmin, app.UserEditor},
+		{app.UserEditor, app.UserEditor},
+		{app.UserReader, app.UserReader},
+		{app.UserRole(42), app.UserReader},
+	}
+
+	for _, tt := range tests {
+		if got := invitedRole(tt.role); got != tt.want {
+			t.Errorf("invitedRole(%v) = %v, want %v", tt.role, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+const maxBulkItems = 1000
+
+func checkBulkMode(mode app.BulkMode) (app.BulkMode, error) {
+	switch mode {
+	case "":
+		return app.BulkAtomic, nil
+	case app.BulkAtomic, app.BulkBestEffort:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("Unknown bulk mode \"%s\".", mode)
+}
+
+func countBulkItem(report *app.BulkReport, status app.BulkItemStatus) {
+	if status == app.BulkFailed {
+		report.Failed++
+	} else {
+		report.Succeeded++
+	}
+}
+
+// AttachExpressions attaches many expressions with nested translations to the node at once.
+// In atomic mode nothing is attached when any item fails, best effort mode keeps succeeded items.
+func (i *NodeInteractor) AttachExpressions(actorId *valueobject.ID, nodeId *valueobject.ID, expressions []*app.Expression, mode app.BulkMode) (*app.BulkReport, error) {
+	mode, err := checkBulkMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expressions) == 0 {
+		return nil, errors.New("No expressions specified.")
+	}
+
+	items := len(expressions)
+	for _, expr := range expressions {
+		if expr == nil {
+			return nil, errors.New("Empty expression specified.")
+		}
+
+		expr.Value = strings.TrimSpace(expr.Value)
+		for _, tr := range expr.Translations {
+			if tr == nil {
+				return nil, errors.New("Empty translation specified.")
+			}
+
+			tr.Value = strings.TrimSpace(tr.Value)
+		}
+		items += len(expr.Translations)
+	}
+
+	if items > maxBulkItems {
+		return nil, fmt.Errorf("Batch can't contain more than %d expressions and translations.", maxBulkItems)
+	}
+
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkStatic([]valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	results, err := i.NodeRepo.AttachExpressions(nodeId, expressions, mode == app.BulkAtomic, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &app.BulkReport{Mode: mode, Expressions: results}
+	for _, result := range results {
+		countBulkItem(report, result.Status)
+		for _, trResult := range result.Translations {
+			countBulkItem(report, trResult.Status)
+		}
+	}
+	report.Applied = mode == app.BulkBestEffort || report.Failed == 0
+
+	if !report.Applied {
+		return report, nil
+	}
+
+	for _, result := range results {
+		if result.Status == app.BulkAttached {
+			logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+				ActorId:    actorId,
+				Action:     app.ActivityExpressionAttach,
+				TargetType: app.TargetExpression,
+				TargetId:   result.Id,
+				After:      map[string]interface{}{"nodeId": nodeId, "expression": result},
+			}))
+		}
+
+		for _, trResult := range result.Translations {
+			if trResult.Status == app.BulkAttached {
+				logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+					ActorId:    actorId,
+					Action:     app.ActivityTranslationAttach,
+					TargetType: app.TargetTranslation,
+					TargetId:   trResult.Id,
+					After:      map[string]interface{}{"nodeId": nodeId, "expressionId": result.Id, "translation": trResult},
+				}))
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// DetachExpressions detaches many expressions, with their translations, and separate translations from the node.
+func (i *NodeInteractor) DetachExpressions(actorId *valueobject.ID, nodeId *valueobject.ID, expressionIds []valueobject.ID, translationIds []valueobject.ID, mode app.BulkMode) (*app.BulkReport, error) {
+	mode, err := checkBulkMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expressionIds)+len(translationIds) == 0 {
+		return nil, errors.New("No expressions or translations specified.")
+	}
+
+	if len(expressionIds)+len(translationIds) > maxBulkItems {
+		return nil, fmt.Errorf("Batch can't contain more than %d expressions and translations.", maxBulkItems)
+	}
+
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkStatic([]valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	results, err := i.NodeRepo.DetachExpressions(nodeId, expressionIds, translationIds, mode == app.BulkAtomic, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &app.BulkReport{Mode: mode, Detached: results}
+	for _, result := range results {
+		countBulkItem(report, result.Status)
+	}
+	report.Applied = mode == app.BulkBestEffort || report.Failed == 0
+
+	if !report.Applied {
+		return report, nil
+	}
+
+	for _, result := range results {
+		if result.Status != app.BulkDetached {
+			continue
+		}
+
+		activity := app.Activity{
+			ActorId:    actorId,
+			Action:     app.ActivityExpressionDetach,
+			TargetType: result.TargetType,
+			TargetId:   result.Id,
+			Before:     map[string]interface{}{"nodeId": nodeId, "expressionId": result.Id},
+		}
+		if result.TargetType == app.TargetTranslation {
+			activity.Action = app.ActivityTranslationDetach
+			activity.Before = map[string]interface{}{"nodeId": nodeId, "translationId": result.Id}
+		}
+
+		logActivity(i.ActivityRepo.LogByNode(nodeId, activity))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,144 @@
+package usecases
+
+import (
+	"errors"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+const (
+	defaultCatalogLimit = 20
+	maxCatalogLimit     = 100
+)
+
+type CatalogInteractor struct {
+	CatalogRepo app.CatalogRepo
+	GroupRepo   app.GroupRepo
+	NodeRepo    app.NodeRepo
+}
+
+func NewCatalogInteractor(cr app.CatalogRepo, gr app.GroupRepo, nr app.NodeRepo) *CatalogInteractor {
+	return &CatalogInteractor{cr, gr, nr}
+}
+
+func (i *CatalogInteractor) Search(filter app.CatalogFilter) ([]*app.CatalogEntry, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultCatalogLimit
+	} else if filter.Limit > maxCatalogLimit {
+		filter.Limit = maxCatalogLimit
+	}
+
+	entries, err := i.CatalogRepo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetGroup returns the group with the nodes which are published or
+// otherwise readable by the actor.
+func (i *CatalogInteractor) GetGroup(actorId *valueobject.ID, groupId *valueobject.ID) (*app.CatalogGroup, error) {
+	group, err := i.GroupRepo.Get(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.Status != app.GroupActive {
+		return nil, errors.New("Group is not found.")
+	}
+
+	nodes, err := i.NodeRepo.List(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []valueobject.ID{}
+	for _, node := range nodes {
+		ids = append(ids, *node.Id)
+	}
+
+	readableIds, err := i.NodeRepo.FilterReadableIds(actorId, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make(map[valueobject.ID]bool)
+	for _, id := range readableIds {
+		readable[id] = true
+	}
+
+	catalogGroup := &app.CatalogGroup{
+		Group: group,
+		Nodes: []*app.FlatNode{},
+	}
+
+	for _, node := range nodes {
+		if readable[*node.Id] {
+			catalogGroup.Nodes = append(catalogGroup.Nodes, node)
+		}
+	}
+
+	if group.Visibility != app.GroupPublic && len(catalogGroup.Nodes) == 0 {
+		return nil, errors.New("Forbidden, group is not published.")
+	}
+
+	isMember := false
+	if actorId != nil {
+		if member, err := i.GroupRepo.FindMemberById(groupId, actorId); err == nil && member.Status == app.MemberActive {
+			isMember = true
+		}
+	}
+
+	if !isMember {
+		group.Members = nil
+	}
+
+	return catalogGroup, nil
+}
+
+func (i *CatalogInteractor) Subscribe(actorId *valueobject.ID, groupId *valueobject.ID) error {
+	published, err := i.CatalogRepo.IsPublished(groupId)
+	if err != nil {
+		return err
+	}
+
+	if !published {
+		return errors.New("Only published group can be subscribed to.")
+	}
+
+	err = i.CatalogRepo.Subscribe(actorId, groupId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *CatalogInteractor) Unsubscribe(actorId *valueobject.ID, groupId *valueobject.ID) error {
+	err := i.CatalogRepo.Unsubscribe(actorId, groupId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *CatalogInteractor) ListSubscriptions(actorId *valueobject.ID) ([]*app.Subscription, error) {
+	subscriptions, err := i.CatalogRepo.ListSubscriptions(actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions {
+		group, err := i.GroupRepo.Get(subscription.GroupId)
+		if err != nil {
+			return nil, err
+		}
+		group.Members = nil
+		subscription.Group = group
+	}
+
+	return subscriptions, nil
+}
@@ -0,0 +1,59 @@
+package usecases
+
+import (
+	"errors"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+const (
+	defaultDuplicateThreshold = 0.6
+	defaultDuplicateLimit     = 50
+	maxDuplicateLimit         = 1000
+)
+
+type DedupeInteractor struct {
+	ExpressionMergeRepo app.ExpressionMergeRepo
+}
+
+func NewDedupeInteractor(emr app.ExpressionMergeRepo) *DedupeInteractor {
+	return &DedupeInteractor{emr}
+}
+
+// FindDuplicates lists likely duplicates of the language, the most similar pairs go first.
+func (i *DedupeInteractor) FindDuplicates(langCode string, threshold float64, limit uint) ([]*app.DuplicateCandidate, error) {
+	if langCode == "" {
+		return nil, errors.New("Language is required.")
+	}
+
+	if threshold == 0 {
+		threshold = defaultDuplicateThreshold
+	} else if threshold < 0 || threshold > 1 {
+		return nil, errors.New("Similarity threshold must be between 0 and 1.")
+	}
+
+	if limit == 0 {
+		limit = defaultDuplicateLimit
+	} else if limit > maxDuplicateLimit {
+		limit = maxDuplicateLimit
+	}
+
+	return i.ExpressionMergeRepo.FindDuplicates(langCode, threshold, limit)
+}
+
+// Merge replaces the dropped expressions with the kept one everywhere.
+func (i *DedupeInteractor) Merge(keepId *valueobject.ID, dropIds []valueobject.ID) error {
+	dropIds = uniqueIds(dropIds)
+	if len(dropIds) == 0 {
+		return errors.New("Nothing to merge.")
+	}
+
+	for _, id := range dropIds {
+		if id == *keepId {
+			return errors.New("Kept expression can't be merged into itself.")
+		}
+	}
+
+	return i.ExpressionMergeRepo.Merge(keepId, dropIds)
+}
@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/app/services"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+const maxDictionaryNameLength = 128
+
+type DictionaryInteractor struct {
+	DictionaryRepo app.DictionaryRepo
+	Parser         services.DictionaryService
+}
+
+func NewDictionaryInteractor(dr app.DictionaryRepo, ds services.DictionaryService) *DictionaryInteractor {
+	return &DictionaryInteractor{dr, ds}
+}
+
+// Import parses the dictionary file and replaces the dictionary of the same name with it.
+func (i *DictionaryInteractor) Import(dictionary app.Dictionary, reader io.Reader) (*app.Dictionary, error) {
+	dictionary.Name = strings.TrimSpace(dictionary.Name)
+	if dictionary.Name == "" {
+		return nil, errors.New("Dictionary name is required.")
+	}
+
+	if len([]rune(dictionary.Name)) > maxDictionaryNameLength {
+		return nil, fmt.Errorf("Dictionary name can't be longer than %d characters.", maxDictionaryNameLength)
+	}
+
+	if dictionary.SourceLangCode == "" || dictionary.NativeLangCode == "" {
+		return nil, errors.New("Dictionary needs source and native languages.")
+	}
+
+	entries, err := i.Parser.Parse(reader, dictionary.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("Dictionary file has no entries.")
+	}
+
+	return i.DictionaryRepo.Import(dictionary, entries)
+}
+
+// suggest lists dictionary entries for the expression, a failed lookup only leaves suggestions out.
+func (i *NodeInteractor) suggest(nodeId *valueobject.ID, expressionId *valueobject.ID) []*app.DictionarySuggestion {
+	suggestions, err := i.DictionaryRepo.Suggest(nodeId, expressionId)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	return suggestions
+}
+
+func (i *NodeInteractor) Suggestions(actorId *valueobject.ID, nodeId *valueobject.ID, expressionId *valueobject.ID) ([]*app.DictionarySuggestion, error) {
+	if err := i.checkReader(actorId, []valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	return i.DictionaryRepo.Suggest(nodeId, expressionId)
+}
+
+// AcceptSuggestion attaches translations and the transcription of the dictionary entry to the expression
+// of the slice like a single imported row. Only the given translations are taken when there are any.
+func (i *NodeInteractor) AcceptSuggestion(actorId *valueobject.ID, nodeId *valueobject.ID, expressionId *valueobject.ID, entryId *valueobject.ID, translations []string, skipTranscription bool) (*app.ImportRowReport, error) {
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkStatic([]valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	sliceIds, err := i.NodeRepo.FilterSliceIds([]valueobject.ID{*nodeId})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sliceIds) == 0 {
+		return nil, errors.New("Suggestions can be accepted into a slice only.")
+	}
+
+	suggestion, err := i.DictionaryRepo.GetSuggestion(nodeId, expressionId, entryId)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Dictionary entry isn't a suggestion for the expression.")
+	}
+
+	expression, err := i.ExpressionRepo.Get(expressionId)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &app.ImportRow{Line: 1, Expression: expression.Value, Translations: suggestion.Translations}
+
+	if len(translations) > 0 {
+		offered := make(map[string]bool)
+		for _, value := range suggestion.Translations {
+			offered[value] = true
+		}
+
+		row.Translations = []string{}
+		for _, value := range translations {
+			if !offered[value] {
+				return nil, fmt.Errorf("Translation \"%s\" isn't offered by the dictionary entry.", value)
+			}
+			row.Translations = append(row.Translations, value)
+		}
+	}
+
+	if suggestion.Transcription != "" && !skipTranscription {
+		row.Transcriptions = []string{suggestion.Transcription}
+	}
+
+	reports, err := i.NodeRepo.Import(nodeId, []*app.ImportRow{row}, false, actorId, app.ActivitySuggestionAccept)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reports) == 0 {
+		return nil, errors.New("Suggestion wasn't accepted.")
+	}
+
+	report := reports[0]
+	if report.Status == app.ImportError {
+		return nil, errors.New(report.Error)
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivitySuggestionAccept,
+		TargetType: app.TargetExpression,
+		TargetId:   expressionId,
+		After: map[string]interface{}{
+			"nodeId":         nodeId,
+			"dictionary":     suggestion.Dictionary,
+			"entryId":        entryId,
+			"translations":   row.Translations,
+			"transcriptions": row.Transcriptions,
+			"status":         report.Status,
+		},
+	}))
+
+	return report, nil
+}
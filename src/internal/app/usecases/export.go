@@ -0,0 +1,150 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+func transcriptionValues(transcriptions []*app.Transcription) []string {
+	values := []string{}
+	for _, tsc := range transcriptions {
+		values = append(values, tsc.Value)
+	}
+
+	return values
+}
+
+func exportEntry(expr *app.Expression) *app.ExportEntry {
+	entry := &app.ExportEntry{
+		Expression:     expr.Value,
+		Transcriptions: transcriptionValues(expr.Transcriptions),
+		Translations:   []*app.ExportTranslation{},
+	}
+
+	for _, tr := range expr.Translations {
+		entry.Translations = append(entry.Translations, &app.ExportTranslation{
+			Value:          tr.Value,
+			Transcriptions: transcriptionValues(tr.Transcriptions),
+			Comment:        tr.Comment,
+		})
+	}
+
+	return entry
+}
+
+// collectExport gathers requested slices and slices under requested folders
+// following the node order of their groups.
+func (i *NodeInteractor) collectExport(actorId *valueobject.ID, ids []valueobject.ID) (*app.VocabularyExport, error) {
+	requested := make(map[valueobject.ID]bool)
+	groupIds := []valueobject.ID{}
+	seenGroups := make(map[valueobject.ID]bool)
+
+	for _, id := range ids {
+		id := id
+		requested[id] = true
+
+		group, err := i.NodeRepo.GetGroupByNode(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seenGroups[*group.Id] {
+			seenGroups[*group.Id] = true
+			groupIds = append(groupIds, *group.Id)
+		}
+	}
+
+	export := &app.VocabularyExport{
+		Slices:    []*app.ExportSlice{},
+		CreatedAt: time.Now(),
+	}
+
+	for _, groupId := range groupIds {
+		groupId := groupId
+		nodes, err := i.NodeRepo.List(&groupId)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make(map[valueobject.ID]string)
+		for _, node := range nodes {
+			names[*node.Id] = node.Name
+		}
+
+		for _, node := range nodes {
+			if !node.Type.IsSlice() {
+				continue
+			}
+
+			ancestors := app.SplitNodePath(node.Path)
+			include := requested[*node.Id]
+			for _, id := range ancestors {
+				include = include || requested[id]
+			}
+
+			if !include {
+				continue
+			}
+
+			folders := []string{}
+			for _, id := range ancestors {
+				folders = append(folders, names[id])
+			}
+
+			view, err := i.NodeRepo.View([]valueobject.ID{*node.Id}, "", actorId)
+			if err != nil {
+				return nil, err
+			}
+
+			slice := &app.ExportSlice{
+				Id:      node.Id,
+				Name:    node.Name,
+				Folders: folders,
+				Entries: []*app.ExportEntry{},
+			}
+			for _, expr := range view.Expressions {
+				slice.Entries = append(slice.Entries, exportEntry(expr))
+			}
+
+			export.Slices = append(export.Slices, slice)
+		}
+	}
+
+	return export, nil
+}
+
+// Export renders expressions of the slices, folders are exported with all their slices.
+func (i *NodeInteractor) Export(actorId *valueobject.ID, ids []valueobject.ID, format app.ExportFormat, layout app.ExportLayout) ([]byte, error) {
+	switch format {
+	case app.ExportJson, app.ExportCsv, app.ExportHtml, app.ExportPdf:
+	default:
+		return nil, fmt.Errorf("Unknown export format \"%s\".", format)
+	}
+
+	if layout == "" {
+		layout = app.ExportList
+	}
+
+	if layout != app.ExportList && layout != app.ExportQuiz {
+		return nil, fmt.Errorf("Unknown export layout \"%s\".", layout)
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("No node ids specified.")
+	}
+
+	if err := i.checkReader(actorId, ids); err != nil {
+		return nil, err
+	}
+
+	export, err := i.collectExport(actorId, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.Exporter.Render(export, format, layout)
+}
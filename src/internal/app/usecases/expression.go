@@ -1,58 +1,113 @@
 package usecases
 
 import (
-	"fmt"
+	"errors"
+	"log"
+	"strings"
 
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/app/services"
 	"github.com/alexkarpovich/lst-api/src/internal/domain"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
 )
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
 type ExpressionInteractor struct {
-	ExpressionRepo domain.ExpressionRepo
+	ExpressionRepo       domain.ExpressionRepo
+	ExpressionDetailRepo app.ExpressionDetailRepo
+	GroupRepo            app.GroupRepo
+	ActivityRepo         app.ActivityRepo
+	Transcribers         services.TranscriberService
+	Segmenters           services.SegmenterService
 }
 
-func NewExpressionInteractor(er domain.ExpressionRepo) *ExpressionInteractor {
-	return &ExpressionInteractor{er}
+func NewExpressionInteractor(er domain.ExpressionRepo, edr app.ExpressionDetailRepo, gr app.GroupRepo, ar app.ActivityRepo, ts services.TranscriberService, ss services.SegmenterService) *ExpressionInteractor {
+	return &ExpressionInteractor{er, edr, gr, ar, ts, ss}
 }
 
-func (i *ExpressionInteractor) Search(langCode string, value string) ([]*domain.Expression, error) {
-	expressions, err := i.ExpressionRepo.Search(langCode, value)
+// Get returns the expression detail, example texts and usage are limited to what the user can see.
+func (i *ExpressionInteractor) Get(actorId *valueobject.ID, expressionId *valueobject.ID) (*app.ExpressionDetail, error) {
+	detail, err := i.ExpressionDetailRepo.Detail(expressionId, actorId)
 	if err != nil {
-		return nil, err
+		log.Println(err)
+		return nil, errors.New("Expression doesn't exist.")
 	}
 
-	return expressions, nil
+	return detail, nil
 }
 
-func (i *ExpressionInteractor) CreateTranscription(expressionId *valueobject.ID, inTranscription domain.Transcription) (*domain.Transcription, error) {
-	transcription, err := i.ExpressionRepo.CreateTranscription(expressionId, inTranscription)
+// Search finds expressions of the language similar to the value or to their transcriptions.
+func (i *ExpressionInteractor) Search(search domain.ExpressionSearch) (*domain.ExpressionSearchPage, error) {
+	search.Value = strings.TrimSpace(search.Value)
+	if search.Value == "" {
+		return nil, errors.New("Search value is required.")
+	}
+
+	if search.Limit == 0 {
+		search.Limit = defaultSearchLimit
+	} else if search.Limit > maxSearchLimit {
+		search.Limit = maxSearchLimit
+	}
+
+	matches, err := i.ExpressionRepo.Search(search)
 	if err != nil {
 		return nil, err
 	}
 
-	return transcription, nil
+	page := &domain.ExpressionSearchPage{
+		Items: matches,
+	}
+
+	if uint(len(matches)) == search.Limit {
+		page.NextCursor = matches[len(matches)-1].Id
+	}
+
+	return page, nil
 }
 
-func (i *ExpressionInteractor) GetTranscriptionMap(expressionId *valueobject.ID, typeId *valueobject.ID) (map[string][]*domain.TranscriptionItem, error) {
-	// expression, err := i.ExpressionRepo.Get(expressionId)
-	// if err != nil {
-	// 	return nil, err
-	// }
+// CreateTranscription adds the transcription to the expression on behalf of an editor
+// of the node the request came through, the activity is logged into the node's group.
+func (i *ExpressionInteractor) CreateTranscription(actorId *valueobject.ID, nodeId *valueobject.ID, expressionId *valueobject.ID, inTranscription domain.Transcription) (*domain.Transcription, error) {
+	if err := checkNodeEditor(i.GroupRepo, actorId, nodeId); err != nil {
+		return nil, err
+	}
 
-	transcriptionMap, err := i.ExpressionRepo.GetTranscriptionMap(typeId, expressionId)
+	transcription, err := i.ExpressionRepo.CreateTranscription(expressionId, inTranscription)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Print(transcriptionMap)
+	logActivity(i.ActivityRepo.LogByExpression(nodeId, expressionId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTranscriptionCreate,
+		TargetType: app.TargetExpression,
+		TargetId:   expressionId,
+		After:      transcription,
+	}))
+
+	return transcription, nil
+}
 
-	// transcriptionParts := []*domain.TranscriptionPart{}
+// GenerateTranscription transcribes the expression with the transcriber of the type.
+func (i *ExpressionInteractor) GenerateTranscription(expressionId *valueobject.ID, typeId *valueobject.ID) (*app.GeneratedTranscription, error) {
+	expression, err := i.ExpressionRepo.Get(expressionId)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Expression doesn't exist.")
+	}
 
-	// for _, exprValue := range exprParts {
-	// 	part := &domain.TranscriptionPart{
+	generated, err := generateTranscription(i.Transcribers, typeId, expression.Value)
+	if err != nil {
+		return nil, err
+	}
 
-	// 	}
-	// }
+	if generated == nil {
+		return nil, errors.New("Expression can't be transcribed automatically.")
+	}
 
-	return transcriptionMap, nil
+	return generated, nil
 }
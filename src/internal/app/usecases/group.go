@@ -13,14 +13,41 @@ import (
 )
 
 type GroupInteractor struct {
-	GroupRepo app.GroupRepo
-	NodeRepo  app.NodeRepo
-	UserRepo  app.UserRepo
-	Email     services.EmailService
+	GroupRepo    app.GroupRepo
+	NodeRepo     app.NodeRepo
+	UserRepo     app.UserRepo
+	ActivityRepo app.ActivityRepo
+	Email        services.EmailService
+	Anki         services.AnkiService
+	Backup       services.BackupService
 }
 
-func NewGroupInteractor(gr app.GroupRepo, fr app.NodeRepo, ur app.UserRepo, es services.EmailService) *GroupInteractor {
-	return &GroupInteractor{gr, fr, ur, es}
+func NewGroupInteractor(gr app.GroupRepo, fr app.NodeRepo, ur app.UserRepo, ar app.ActivityRepo, es services.EmailService, as services.AnkiService, bs services.BackupService) *GroupInteractor {
+	return &GroupInteractor{gr, fr, ur, ar, es, as, bs}
+}
+
+// requireActiveRole returns the member when they have joined the group and hold one of the roles,
+// any role is enough when none is given. Pending invitees are refused with the message.
+func requireActiveRole(groupRepo app.GroupRepo, groupId *valueobject.ID, userId *valueobject.ID, message string, roles ...app.UserRole) (*app.GroupMember, error) {
+	member, err := groupRepo.FindMemberById(groupId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.Status != app.MemberActive {
+		return nil, errors.New(message)
+	}
+
+	if len(roles) == 0 {
+		return member, nil
+	}
+	for _, role := range roles {
+		if member.Role == role {
+			return member, nil
+		}
+	}
+
+	return nil, errors.New(message)
 }
 
 func (i *GroupInteractor) CreateGroup(actorId *valueobject.ID, obj app.Group) (*app.Group, error) {
@@ -41,16 +68,50 @@ func (i *GroupInteractor) CreateGroup(actorId *valueobject.ID, obj app.Group) (*
 	return group, nil
 }
 
+func (i *GroupInteractor) CloneGroup(actorId *valueobject.ID, groupId *valueobject.ID, name string, nodeIds []valueobject.ID) (*app.Group, error) {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only active member can clone group.")
+	if err != nil {
+		return nil, err
+	}
+
+	obj := app.Group{
+		Name: strings.TrimSpace(name),
+	}
+
+	cloneId, err := i.GroupRepo.Clone(actorId, groupId, obj, nodeIds)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    cloneId,
+		ActorId:    actorId,
+		Action:     app.ActivityGroupClone,
+		TargetType: app.TargetGroup,
+		TargetId:   cloneId,
+		Before:     map[string]interface{}{"groupId": groupId, "nodeIds": nodeIds},
+	}))
+
+	group, err := i.GroupRepo.Get(cloneId)
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
 func (i *GroupInteractor) UpdateGroup(actorId *valueobject.ID, obj app.Group) error {
 	var err error
 
-	member, err := i.GroupRepo.FindMemberById(obj.Id, obj.Id)
+	_, err = requireActiveRole(i.GroupRepo, obj.Id, actorId, "Forbidden, only non-reader member can edit group.", app.UserAdmin, app.UserEditor)
 	if err != nil {
 		return err
 	}
 
-	if member.Role == app.UserReader {
-		return errors.New("Forbidden, only non-reader member can edit group.")
+	before, err := i.GroupRepo.Get(obj.Id)
+	if err != nil {
+		return err
 	}
 
 	obj.Name = strings.TrimSpace(obj.Name)
@@ -61,6 +122,16 @@ func (i *GroupInteractor) UpdateGroup(actorId *valueobject.ID, obj app.Group) er
 		return err
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    obj.Id,
+		ActorId:    actorId,
+		Action:     app.ActivityGroupUpdate,
+		TargetType: app.TargetGroup,
+		TargetId:   obj.Id,
+		Before:     before,
+		After:      obj,
+	}))
+
 	return nil
 }
 
@@ -74,24 +145,99 @@ func (i *GroupInteractor) ListGroups(userId *valueobject.ID) ([]*app.Group, erro
 }
 
 func (i *GroupInteractor) MarkGroupAsDeleted(userId *valueobject.ID, groupId *valueobject.ID) error {
-	err := i.GroupRepo.MarkAsDeleted(groupId)
+	_, err := requireActiveRole(i.GroupRepo, groupId, userId, "Forbidden, only admin can delete group.", app.UserAdmin)
 	if err != nil {
-		log.Println(err)
+		return err
+	}
+
+	err = i.GroupRepo.MarkAsDeleted(groupId, userId)
+	if err != nil {
+		return err
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    userId,
+		Action:     app.ActivityGroupDelete,
+		TargetType: app.TargetGroup,
+		TargetId:   groupId,
+	}))
+
 	return nil
 }
 
-func (i *GroupInteractor) InviteUser(actorId *valueobject.ID, groupId *valueobject.ID, userId *valueobject.ID) error {
-	var err error
+func (i *GroupInteractor) ListTrash(userId *valueobject.ID) ([]*app.Group, error) {
+	return i.GroupRepo.ListDeleted(userId, time.Now().Add(-app.GroupTrashRetention))
+}
+
+func (i *GroupInteractor) RestoreGroup(actorId *valueobject.ID, groupId *valueobject.ID) error {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can restore group.", app.UserAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = i.GroupRepo.Restore(groupId, time.Now().Add(-app.GroupTrashRetention))
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityGroupRestore,
+		TargetType: app.TargetGroup,
+		TargetId:   groupId,
+	}))
+
+	return nil
+}
+
+// PurgeDeletedGroups permanently removes groups which stayed in the trash longer than retention period.
+func (i *GroupInteractor) PurgeDeletedGroups() (uint, error) {
+	return i.GroupRepo.Purge(time.Now().Add(-app.GroupTrashRetention))
+}
+
+func (i *GroupInteractor) PublishGroup(actorId *valueobject.ID, groupId *valueobject.ID, visibility app.GroupVisibility) error {
+	switch visibility {
+	case app.GroupPrivate, app.GroupPublic:
+	default:
+		return errors.New("Unknown group visibility.")
+	}
 
-	actor, err := i.GroupRepo.FindMemberById(groupId, actorId)
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can publish group.", app.UserAdmin)
 	if err != nil {
 		return err
 	}
 
-	if actor.Role != app.UserAdmin {
-		return errors.New("Forbidden, only admin can invite member.")
+	group, err := i.GroupRepo.Get(groupId)
+	if err != nil {
+		return err
+	}
+
+	err = i.GroupRepo.UpdateVisibility(groupId, visibility)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityGroupPublish,
+		TargetType: app.TargetGroup,
+		TargetId:   groupId,
+		Before:     map[string]interface{}{"visibility": group.Visibility},
+		After:      map[string]interface{}{"visibility": visibility},
+	}))
+
+	return nil
+}
+
+func (i *GroupInteractor) InviteUser(actorId *valueobject.ID, groupId *valueobject.ID, userId *valueobject.ID) error {
+	var err error
+
+	_, err = requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can invite member.", app.UserAdmin)
+	if err != nil {
+		return err
 	}
 
 	member := app.GroupMember{
@@ -107,6 +253,15 @@ func (i *GroupInteractor) InviteUser(actorId *valueobject.ID, groupId *valueobje
 		log.Println(err)
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityMemberInvite,
+		TargetType: app.TargetMember,
+		TargetId:   userId,
+		After:      member,
+	}))
+
 	user, err := i.UserRepo.Get(userId)
 	if err != nil {
 		return err
@@ -134,28 +289,255 @@ func (i *GroupInteractor) ConfirmInvitation(userId *valueobject.ID, token string
 		return err
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    userId,
+		Action:     app.ActivityMemberJoin,
+		TargetType: app.TargetMember,
+		TargetId:   userId,
+		After:      member,
+	}))
+
 	return nil
 }
 
-func (i *GroupInteractor) DetachMember(groupId *valueobject.ID, userId *valueobject.ID) error {
-	err := i.GroupRepo.DetachMember(groupId, userId)
+func (i *GroupInteractor) CreateJoinLink(actorId *valueobject.ID, groupId *valueobject.ID, inLink app.GroupLink) (*app.GroupLink, error) {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can create join link.", app.UserAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if inLink.Role != app.UserReader && inLink.Role != app.UserEditor {
+		return nil, errors.New("Join link role must be either reader or editor.")
+	}
+
+	if inLink.ExpiresAt.IsZero() {
+		inLink.ExpiresAt = time.Now().Add(7 * 24 * time.Hour)
+	} else if inLink.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("Join link expiration must be in the future.")
+	}
+
+	inLink.GroupId = groupId
+	inLink.AuthorId = actorId
+	inLink.Token = pkg.RandomString(tokenLength)
+	inLink.Status = app.GroupLinkActive
+
+	link, err := i.GroupRepo.CreateLink(inLink)
 	if err != nil {
 		log.Println(err)
+		return nil, err
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityLinkCreate,
+		TargetType: app.TargetLink,
+		TargetId:   link.Id,
+		After: map[string]interface{}{
+			"role":      link.Role,
+			"maxUses":   link.MaxUses,
+			"expiresAt": link.ExpiresAt,
+		},
+	}))
+
+	return link, nil
+}
+
+func (i *GroupInteractor) ListJoinLinks(actorId *valueobject.ID, groupId *valueobject.ID) ([]*app.GroupLink, error) {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can list join links.", app.UserAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := i.GroupRepo.ListLinks(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func (i *GroupInteractor) RevokeJoinLink(actorId *valueobject.ID, groupId *valueobject.ID, linkId *valueobject.ID) error {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can revoke join link.", app.UserAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = i.GroupRepo.RevokeLink(groupId, linkId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityLinkRevoke,
+		TargetType: app.TargetLink,
+		TargetId:   linkId,
+	}))
+
+	return nil
+}
+
+func (i *GroupInteractor) JoinByLink(userId *valueobject.ID, token string) (*app.Group, error) {
+	link, err := i.GroupRepo.FindLinkByToken(token)
+	if err != nil {
+		return nil, errors.New("Join link is invalid, expired or used up.")
+	}
+
+	if member, err := i.GroupRepo.FindMemberById(link.GroupId, userId); err == nil && member.Status == app.MemberActive {
+		return nil, errors.New("You are already a member of this group.")
+	}
+
+	member := app.GroupMember{
+		Id: userId,
+	}
+
+	groupId, err := i.GroupRepo.JoinByLink(token, member)
+	if err != nil {
+		return nil, err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    userId,
+		Action:     app.ActivityMemberJoin,
+		TargetType: app.TargetLink,
+		TargetId:   link.Id,
+	}))
+
+	group, err := i.GroupRepo.Get(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (i *GroupInteractor) RejectInvitation(userId *valueobject.ID, token string) error {
+	groupId, member, err := i.GroupRepo.FindMemberByToken(token)
+	if err != nil {
+		return err
+	}
+
+	if *userId != *member.Id {
+		return errors.New("You don't have permissions to reject invitation.")
+	}
+
+	err = i.GroupRepo.DetachMember(groupId, member.Id)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    userId,
+		Action:     app.ActivityMemberReject,
+		TargetType: app.TargetMember,
+		TargetId:   userId,
+	}))
+
+	return nil
+}
+
+func (i *GroupInteractor) LeaveGroup(userId *valueobject.ID, groupId *valueobject.ID) error {
+	member, err := i.GroupRepo.FindMemberById(groupId, userId)
+	if err != nil {
+		return err
+	}
+
+	err = i.GroupRepo.DetachMember(groupId, userId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    userId,
+		Action:     app.ActivityMemberLeave,
+		TargetType: app.TargetMember,
+		TargetId:   userId,
+		Before:     member,
+	}))
+
+	return nil
+}
+
+func (i *GroupInteractor) DetachMember(actorId *valueobject.ID, groupId *valueobject.ID, userId *valueobject.ID) error {
+	if *actorId == *userId {
+		return i.LeaveGroup(userId, groupId)
+	}
+
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can detach member.", app.UserAdmin)
+	if err != nil {
+		return err
+	}
+
+	member, err := i.GroupRepo.FindMemberById(groupId, userId)
+	if err != nil {
+		return err
+	}
+
+	err = i.GroupRepo.DetachMember(groupId, userId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityMemberDetach,
+		TargetType: app.TargetMember,
+		TargetId:   userId,
+		Before:     member,
+	}))
+
+	return nil
+}
+
+func (i *GroupInteractor) TransferAdmin(actorId *valueobject.ID, groupId *valueobject.ID, memberId *valueobject.ID) error {
+	if *actorId == *memberId {
+		return errors.New("You are already an admin of this group.")
+	}
+
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin can transfer admin role.", app.UserAdmin)
+	if err != nil {
+		return err
+	}
+
+	member, err := requireActiveRole(i.GroupRepo, groupId, memberId, "Admin role can be transferred only to an active member.")
+	if err != nil {
+		return err
+	}
+
+	err = i.GroupRepo.TransferAdmin(groupId, actorId, memberId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityMemberTransferAdmin,
+		TargetType: app.TargetMember,
+		TargetId:   memberId,
+		Before:     member,
+	}))
+
 	return nil
 }
 
 func (i *GroupInteractor) UpdateMemberRole(actorId *valueobject.ID, groupId *valueobject.ID, member app.GroupMember) error {
 	var err error
 
-	actor, err := i.GroupRepo.FindMemberById(groupId, actorId)
+	_, err = requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin of a group can change member roles.", app.UserAdmin)
 	if err != nil {
 		return err
 	}
 
-	if actor.Role != app.UserAdmin {
-		return errors.New("Forbidden, only admin of a group can change member roles.")
+	if member.Role != app.UserAdmin && member.Role != app.UserEditor && member.Role != app.UserReader {
+		return errors.New("Unknown member role.")
 	}
 
 	mbr, err := i.GroupRepo.FindMemberById(groupId, member.Id)
@@ -163,21 +545,46 @@ func (i *GroupInteractor) UpdateMemberRole(actorId *valueobject.ID, groupId *val
 		return err
 	}
 
+	before := *mbr
 	mbr.Role = member.Role
 
-	err = i.GroupRepo.UpdateMember(groupId, member)
+	err = i.GroupRepo.UpdateMember(groupId, *mbr)
 	if err != nil {
 		log.Println(err)
+		return err
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityMemberRole,
+		TargetType: app.TargetMember,
+		TargetId:   member.Id,
+		Before:     before,
+		After:      mbr,
+	}))
+
 	return nil
 }
 
-func (i *GroupInteractor) CreateNode(groupId *valueobject.ID, s app.Node) (*app.Node, error) {
+func (i *GroupInteractor) CreateNode(actorId *valueobject.ID, groupId *valueobject.ID, s app.Node) (*app.Node, error) {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only non-reader user of a group can create node.", app.UserAdmin, app.UserEditor)
+	if err != nil {
+		return nil, err
+	}
+
 	s.Visibility = app.NodePrivate
 	s.Name = strings.TrimSpace(s.Name)
 
-	slice, err := i.NodeRepo.Create(groupId, s)
+	if s.Type == app.NodeSmart {
+		if err := checkSmartQuery(i.NodeRepo, groupId, actorId, s.SmartQuery); err != nil {
+			return nil, err
+		}
+	} else {
+		s.SmartQuery = nil
+	}
+
+	slice, err := i.NodeRepo.Create(groupId, s, actorId)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -188,6 +595,15 @@ func (i *GroupInteractor) CreateNode(groupId *valueobject.ID, s app.Node) (*app.
 		return nil, err
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityNodeCreate,
+		TargetType: app.TargetNode,
+		TargetId:   slice.Id,
+		After:      slice,
+	}))
+
 	return slice, nil
 }
 
@@ -200,16 +616,77 @@ func (i *GroupInteractor) ListNodes(groupId *valueobject.ID) ([]*app.FlatNode, e
 	return folders, nil
 }
 
+func (i *GroupInteractor) NodeTree(actorId *valueobject.ID, groupId *valueobject.ID, rootId *valueobject.ID, depth uint) ([]*app.TreeNode, error) {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only active member can view group tree.")
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := i.NodeRepo.ListTree(groupId, actorId, rootId)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.BuildNodeTree(nodes, rootId, depth), nil
+}
+
 func (i *GroupInteractor) MoveNode(actorId *valueobject.ID, groupId *valueobject.ID, node app.FlatNode, nodeOrder []*valueobject.ID) error {
 	var err error
 
-	actor, err := i.GroupRepo.FindMemberById(groupId, actorId)
+	_, err = requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only non-reader user of a group can change node order.", app.UserAdmin, app.UserEditor)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := i.NodeRepo.List(groupId)
 	if err != nil {
 		return err
 	}
 
-	if actor.Role == app.UserReader {
-		return errors.New("Forbidden, only non-reader user of a group can change node order.")
+	nodeMap := make(map[valueobject.ID]*app.FlatNode)
+	for _, n := range nodes {
+		nodeMap[*n.Id] = n
+	}
+
+	if node.Id == nil || nodeMap[*node.Id] == nil {
+		return errors.New("Node doesn't belong to the group.")
+	}
+	before := nodeMap[*node.Id]
+
+	parentIds := app.SplitNodePath(node.Path)
+	if len(parentIds) > 0 {
+		parent, ok := nodeMap[parentIds[len(parentIds)-1]]
+		if !ok {
+			return errors.New("Target folder doesn't belong to the group.")
+		}
+
+		if parent.Type != app.NodeFolder {
+			return errors.New("Node can be moved into a folder only.")
+		}
+
+		if parent.ChildPath() != app.JoinNodePath(parentIds) {
+			return errors.New("Invalid target path.")
+		}
+	}
+
+	for _, parentId := range parentIds {
+		if parentId == *node.Id {
+			return errors.New("Node can't be moved into itself or its descendant.")
+		}
+	}
+
+	if nodeOrder != nil {
+		if len(nodeOrder) != len(nodes) {
+			return errors.New("Node order must contain every node of the group.")
+		}
+
+		seen := make(map[valueobject.ID]bool)
+		for _, nodeId := range nodeOrder {
+			if nodeId == nil || nodeMap[*nodeId] == nil || seen[*nodeId] {
+				return errors.New("Node order must contain every node of the group.")
+			}
+			seen[*nodeId] = true
+		}
 	}
 
 	err = i.GroupRepo.MoveNode(groupId, node, nodeOrder)
@@ -217,14 +694,79 @@ func (i *GroupInteractor) MoveNode(actorId *valueobject.ID, groupId *valueobject
 		return err
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityNodeMove,
+		TargetType: app.TargetNode,
+		TargetId:   node.Id,
+		Before:     map[string]string{"path": before.Path},
+		After:      map[string]interface{}{"path": node.Path, "nodeOrder": nodeOrder},
+	}))
+
 	return nil
 }
 
-func (i *GroupInteractor) DeleteNode(groupId *valueobject.ID, nodeId *valueobject.ID) error {
-	err := i.GroupRepo.DeleteNode(groupId, nodeId)
+func (i *GroupInteractor) DeleteNode(actorId *valueobject.ID, groupId *valueobject.ID, nodeId *valueobject.ID) error {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only non-reader user of a group can delete node.", app.UserAdmin, app.UserEditor)
+	if err != nil {
+		return err
+	}
+
+	before, err := i.NodeRepo.Get(nodeId)
+	if err != nil {
+		return err
+	}
+
+	err = i.GroupRepo.DeleteNode(groupId, nodeId, actorId)
 	if err != nil {
 		return err
 	}
 
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityNodeDelete,
+		TargetType: app.TargetNode,
+		TargetId:   nodeId,
+		Before:     before,
+	}))
+
 	return nil
 }
+
+func (i *GroupInteractor) ListDeletedNodes(actorId *valueobject.ID, groupId *valueobject.ID) ([]*app.TrashedNode, error) {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only active member can view node trash.")
+	if err != nil {
+		return nil, err
+	}
+
+	return i.GroupRepo.ListDeletedNodes(groupId, time.Now().Add(-app.NodeTrashRetention))
+}
+
+func (i *GroupInteractor) RestoreNode(actorId *valueobject.ID, groupId *valueobject.ID, trashId *valueobject.ID) error {
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only non-reader user of a group can restore node.", app.UserAdmin, app.UserEditor)
+	if err != nil {
+		return err
+	}
+
+	nodeId, err := i.GroupRepo.RestoreNode(groupId, trashId, time.Now().Add(-app.NodeTrashRetention))
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityNodeRestore,
+		TargetType: app.TargetNode,
+		TargetId:   nodeId,
+	}))
+
+	return nil
+}
+
+// PurgeDeletedNodes permanently removes nodes which stayed in the trash longer than retention period.
+func (i *GroupInteractor) PurgeDeletedNodes() (uint, error) {
+	return i.GroupRepo.PurgeNodes(time.Now().Add(-app.NodeTrashRetention))
+}
@@ -0,0 +1,257 @@
+package usecases
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+const (
+	maxImportRows  = 5000
+	maxImportValue = 128
+)
+
+var defaultImportColumns = []app.ImportColumn{app.ImportExpression, app.ImportTranslation}
+
+func validateImportColumns(columns []app.ImportColumn) error {
+	expressionCount := 0
+
+	for _, column := range columns {
+		switch column {
+		case app.ImportExpression:
+			expressionCount++
+		case app.ImportTranslation, app.ImportTranscription, app.ImportComment, app.ImportSkip:
+		default:
+			return fmt.Errorf("Unknown import column \"%s\".", column)
+		}
+	}
+
+	if expressionCount != 1 {
+		return errors.New("Exactly one expression column is required.")
+	}
+
+	return nil
+}
+
+// splitImportValues splits the cell into trimmed non-empty values.
+func splitImportValues(cell string, separator string) []string {
+	values := []string{}
+	parts := []string{cell}
+	if separator != "" {
+		parts = strings.Split(cell, separator)
+	}
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			values = append(values, part)
+		}
+	}
+
+	return values
+}
+
+func checkImportRow(row *app.ImportRow) error {
+	if row.Expression == "" {
+		return errors.New("Expression is empty.")
+	}
+
+	values := append(append([]string{row.Expression}, row.Translations...), row.Transcriptions...)
+	for _, value := range values {
+		if utf8.RuneCountInString(value) > maxImportValue {
+			return fmt.Errorf("Value \"%s\" is longer than %d characters.", value, maxImportValue)
+		}
+	}
+
+	if utf8.RuneCountInString(row.Comment) > 256 {
+		return errors.New("Comment is longer than 256 characters.")
+	}
+
+	return nil
+}
+
+// importRowFromFields maps the fields to row values following the column options.
+func importRowFromFields(line uint, fields []string, opts app.ImportOptions) (*app.ImportRow, bool) {
+	row := &app.ImportRow{Line: line}
+	empty := true
+
+	for idx, cell := range fields {
+		if strings.TrimSpace(cell) != "" {
+			empty = false
+		}
+		if idx >= len(opts.Columns) {
+			continue
+		}
+
+		switch opts.Columns[idx] {
+		case app.ImportExpression:
+			row.Expression = strings.TrimSpace(cell)
+		case app.ImportTranslation:
+			row.Translations = append(row.Translations, splitImportValues(cell, opts.ValueSeparator)...)
+		case app.ImportTranscription:
+			row.Transcriptions = append(row.Transcriptions, splitImportValues(cell, opts.ValueSeparator)...)
+		case app.ImportComment:
+			row.Comment = strings.TrimSpace(cell)
+		}
+	}
+
+	return row, empty
+}
+
+// buildImportReport merges repo and parsing reports ordered by line and counts statuses.
+func buildImportReport(reports []*app.ImportRowReport, invalid []*app.ImportRowReport, dryRun bool) *app.ImportReport {
+	report := &app.ImportReport{
+		DryRun: dryRun,
+		Rows:   append(reports, invalid...),
+	}
+	sort.Slice(report.Rows, func(a, b int) bool {
+		return report.Rows[a].Line < report.Rows[b].Line
+	})
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case app.ImportCreated:
+			report.Created++
+		case app.ImportLinked:
+			report.Linked++
+		case app.ImportDuplicate:
+			report.Duplicate++
+		case app.ImportError:
+			report.Errors++
+		}
+	}
+
+	return report
+}
+
+// parseImportRows reads delimited vocabulary rows. Malformed rows are reported
+// instead of failing the whole import.
+func parseImportRows(reader io.Reader, opts app.ImportOptions) ([]*app.ImportRow, []*app.ImportRowReport, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = opts.Delimiter
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	rows := []*app.ImportRow{}
+	invalid := []*app.ImportRowReport{}
+	isHeader := opts.HasHeader
+
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			parseErr, ok := err.(*csv.ParseError)
+			if !ok {
+				return nil, nil, err
+			}
+			invalid = append(invalid, &app.ImportRowReport{
+				Line:   uint(parseErr.StartLine),
+				Status: app.ImportError,
+				Error:  parseErr.Err.Error(),
+			})
+			continue
+		}
+
+		if isHeader {
+			isHeader = false
+			continue
+		}
+
+		fieldLine, _ := csvReader.FieldPos(0)
+		line := uint(fieldLine)
+
+		if len(rows)+len(invalid) >= maxImportRows {
+			return nil, nil, fmt.Errorf("File contains more than %d rows.", maxImportRows)
+		}
+
+		row, empty := importRowFromFields(line, record, opts)
+
+		if empty {
+			continue
+		}
+
+		if err = checkImportRow(row); err != nil {
+			invalid = append(invalid, &app.ImportRowReport{
+				Line:       line,
+				Expression: row.Expression,
+				Status:     app.ImportError,
+				Error:      err.Error(),
+			})
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, invalid, nil
+}
+
+// Import attaches vocabulary from CSV/TSV file to the slice.
+func (i *NodeInteractor) Import(actorId *valueobject.ID, nodeId *valueobject.ID, reader io.Reader, opts app.ImportOptions) (*app.ImportReport, error) {
+	if len(opts.Columns) == 0 {
+		opts.Columns = defaultImportColumns
+	}
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+
+	if err := validateImportColumns(opts.Columns); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkStatic([]valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	sliceIds, err := i.NodeRepo.FilterSliceIds([]valueobject.ID{*nodeId})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sliceIds) == 0 {
+		return nil, errors.New("Vocabulary can be imported into a slice only.")
+	}
+
+	rows, invalid, err := parseImportRows(reader, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := i.NodeRepo.Import(nodeId, rows, opts.DryRun, actorId, app.ActivityNodeImport)
+	if err != nil {
+		return nil, err
+	}
+
+	report := buildImportReport(reports, invalid, opts.DryRun)
+
+	if !opts.DryRun {
+		logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+			ActorId:    actorId,
+			Action:     app.ActivityNodeImport,
+			TargetType: app.TargetNode,
+			TargetId:   nodeId,
+			After: map[string]interface{}{
+				"created":   report.Created,
+				"linked":    report.Linked,
+				"duplicate": report.Duplicate,
+				"errors":    report.Errors,
+			},
+		}))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,142 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+)
+
+func TestValidateImportColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []app.ImportColumn
+		wantErr bool
+	}{
+		{"default", defaultImportColumns, false},
+		{"all kinds", []app.ImportColumn{app.ImportSkip, app.ImportExpression, app.ImportTranscription, app.ImportTranslation, app.ImportComment}, false},
+		{"no expression", []app.ImportColumn{app.ImportTranslation}, true},
+		{"two expressions", []app.ImportColumn{app.ImportExpression, app.ImportExpression}, true},
+		{"unknown", []app.ImportColumn{app.ImportExpression, "meaning"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateImportColumns(tt.columns); (err != nil) != tt.wantErr {
+				t.Errorf("validateImportColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseImportRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		opts    app.ImportOptions
+		rows    []app.ImportRow
+		invalid []uint
+	}{
+		{
+			name:    "default columns",
+			content: "hello,привет\nworld,мир\n",
+			opts:    app.ImportOptions{Columns: defaultImportColumns, Delimiter: ','},
+			rows: []app.ImportRow{
+				{Line: 1, Expression: "hello", Translations: []string{"привет"}},
+				{Line: 2, Expression: "world", Translations: []string{"мир"}},
+			},
+		},
+		{
+			name:    "header and empty rows are skipped",
+			content: "expression,translation\n\n , \nhello,привет\n",
+			opts:    app.ImportOptions{Columns: defaultImportColumns, Delimiter: ',', HasHeader: true},
+			rows: []app.ImportRow{
+				{Line: 4, Expression: "hello", Translations: []string{"привет"}},
+			},
+		},
+		{
+			name:    "separated values and all columns",
+			content: "x\t你好\tnǐ hǎo\thi; hello ;\tgreeting\n",
+			opts: app.ImportOptions{
+				Columns:        []app.ImportColumn{app.ImportSkip, app.ImportExpression, app.ImportTranscription, app.ImportTranslation, app.ImportComment},
+				Delimiter:      '\t',
+				ValueSeparator: ";",
+			},
+			rows: []app.ImportRow{
+				{Line: 1, Expression: "你好", Transcriptions: []string{"nǐ hǎo"}, Translations: []string{"hi", "hello"}, Comment: "greeting"},
+			},
+		},
+		{
+			name:    "quoted cells",
+			content: "\"hello, world\",\"привет, мир\"\n",
+			opts:    app.ImportOptions{Columns: defaultImportColumns, Delimiter: ','},
+			rows: []app.ImportRow{
+				{Line: 1, Expression: "hello, world", Translations: []string{"привет, мир"}},
+			},
+		},
+		{
+			name:    "malformed rows are reported",
+			content: ",привет\n" + strings.Repeat("a", maxImportValue+1) + ",b\nhello,привет\n",
+			opts:    app.ImportOptions{Columns: defaultImportColumns, Delimiter: ','},
+			rows: []app.ImportRow{
+				{Line: 3, Expression: "hello", Translations: []string{"привет"}},
+			},
+			invalid: []uint{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, invalid, err := parseImportRows(strings.NewReader(tt.content), tt.opts)
+			if err != nil {
+				t.Fatalf("parseImportRows() error = %v", err)
+			}
+
+			if len(rows) != len(tt.rows) {
+				t.Fatalf("rows = %d, want %d", len(rows), len(tt.rows))
+			}
+			for i, row := range rows {
+				want := tt.rows[i]
+				if row.Line != want.Line || row.Expression != want.Expression || row.Comment != want.Comment ||
+					strings.Join(row.Translations, "|") != strings.Join(want.Translations, "|") ||
+					strings.Join(row.Transcriptions, "|") != strings.Join(want.Transcriptions, "|") {
+					t.Errorf("row %d = %+v, want %+v", i, *row, want)
+				}
+			}
+
+			if len(invalid) != len(tt.invalid) {
+				t.Fatalf("invalid rows = %d, want %d", len(invalid), len(tt.invalid))
+			}
+			for i, report := range invalid {
+				if report.Line != tt.invalid[i] || report.Status != app.ImportError || report.Error == "" {
+					t.Errorf("invalid row %d = %+v, want line %d", i, *report, tt.invalid[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseImportRowsLimit(t *testing.T) {
+	content := strings.Repeat("a,b\n", maxImportRows+1)
+	opts := app.ImportOptions{Columns: defaultImportColumns, Delimiter: ','}
+
+	if _, _, err := parseImportRows(strings.NewReader(content), opts); err == nil {
+		t.Error("parseImportRows() accepted more than maxImportRows rows")
+	}
+}
+
+func TestBuildImportReport(t *testing.T) {
+	reports := []*app.ImportRowReport{{Line: 3, Status: app.ImportCreated}, {Line: 1, Status: app.ImportLinked}, {Line: 4, Status: app.ImportDuplicate}}
+	invalid := []*app.ImportRowReport{{Line: 2, Status: app.ImportError}}
+
+	report := buildImportReport(reports, invalid, true)
+
+	if !report.DryRun || report.Created != 1 || report.Linked != 1 || report.Duplicate != 1 || report.Errors != 1 {
+		t.Errorf("report = %+v", *report)
+	}
+	for i, row := range report.Rows {
+		if row.Line != uint(i+1) {
+			t.Errorf("row %d is on line %d", i, row.Line)
+		}
+	}
+}
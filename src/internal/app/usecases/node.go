@@ -2,10 +2,11 @@ package usecases
 
 import (
 	"errors"
+	"fmt"
 	"log"
-	"strings"
 
 	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/app/services"
 	"github.com/alexkarpovich/lst-api/src/internal/domain"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
 )
@@ -14,14 +15,60 @@ type NodeInteractor struct {
 	NodeRepo       app.NodeRepo
 	GroupRepo      app.GroupRepo
 	ExpressionRepo domain.ExpressionRepo
+	DictionaryRepo app.DictionaryRepo
+	ActivityRepo   app.ActivityRepo
+	Anki           services.AnkiService
+	Exporter       services.ExportService
+	Transcribers   services.TranscriberService
+	Segmenters     services.SegmenterService
 }
 
-func NewNodeInteractor(pr app.NodeRepo, gr app.GroupRepo, er domain.ExpressionRepo) *NodeInteractor {
-	return &NodeInteractor{pr, gr, er}
+func NewNodeInteractor(pr app.NodeRepo, gr app.GroupRepo, er domain.ExpressionRepo, dr app.DictionaryRepo, ar app.ActivityRepo, as services.AnkiService, xs services.ExportService, ts services.TranscriberService, ss services.SegmenterService) *NodeInteractor {
+	return &NodeInteractor{pr, gr, er, dr, ar, as, xs, ts, ss}
+}
+
+func (i *NodeInteractor) checkEditor(actorId *valueobject.ID, nodeId *valueobject.ID) error {
+	return checkNodeEditor(i.GroupRepo, actorId, nodeId)
+}
+
+// checkNodeEditor allows the change to admins and editors of the group holding the node.
+func checkNodeEditor(groupRepo app.GroupRepo, actorId *valueobject.ID, nodeId *valueobject.ID) error {
+	member, err := groupRepo.FindMemberByNodeId(nodeId, actorId)
+	if err != nil {
+		return err
+	}
+
+	if member.Status != app.MemberActive || member.Role == app.UserReader {
+		return errors.New("Fobidden, only admin or editor can edit node.")
+	}
+
+	return nil
+}
+
+// checkReader allows reading nodes to group members and to anybody when
+// the nodes are published to the catalog.
+func (i *NodeInteractor) checkReader(actorId *valueobject.ID, nodeIds []valueobject.ID) error {
+	readableIds, err := i.NodeRepo.FilterReadableIds(actorId, nodeIds)
+	if err != nil {
+		return err
+	}
+
+	readable := make(map[valueobject.ID]bool)
+	for _, id := range readableIds {
+		readable[id] = true
+	}
+
+	for _, id := range nodeIds {
+		if !readable[id] {
+			return errors.New("Forbidden, node is neither shared with you nor published.")
+		}
+	}
+
+	return nil
 }
 
 func (i *NodeInteractor) Create(groupId *valueobject.ID, s app.Node) (*app.Node, error) {
-	slice, err := i.NodeRepo.Create(groupId, s)
+	slice, err := i.NodeRepo.Create(groupId, s, nil)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -30,7 +77,11 @@ func (i *NodeInteractor) Create(groupId *valueobject.ID, s app.Node) (*app.Node,
 	return slice, nil
 }
 
-func (i *NodeInteractor) Get(nodeId *valueobject.ID) (*app.Node, error) {
+func (i *NodeInteractor) Get(actorId *valueobject.ID, nodeId *valueobject.ID) (*app.Node, error) {
+	if err := i.checkReader(actorId, []valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
 	node, err := i.NodeRepo.Get(nodeId)
 	if err != nil {
 		return nil, err
@@ -39,8 +90,37 @@ func (i *NodeInteractor) Get(nodeId *valueobject.ID) (*app.Node, error) {
 	return node, nil
 }
 
-func (i *NodeInteractor) View(ids []valueobject.ID) (*app.NodeView, error) {
-	nodesView, err := i.NodeRepo.View(ids)
+func checkNodeVisibility(visibility app.NodeVisibility) error {
+	switch visibility {
+	case app.NodePrivate, app.NodePublic:
+		return nil
+	}
+
+	return errors.New("Unknown node visibility.")
+}
+
+func checkExpressionOrder(order app.ExpressionOrder) error {
+	switch order {
+	case app.ExpressionOrderManual, app.ExpressionOrderCreated, app.ExpressionOrderAlpha, app.ExpressionOrderDifficulty:
+		return nil
+	}
+
+	return fmt.Errorf("Unknown expression order \"%s\".", order)
+}
+
+// View lists expressions of the nodes, a single node follows its configured order when no order is given.
+func (i *NodeInteractor) View(actorId *valueobject.ID, ids []valueobject.ID, order app.ExpressionOrder) (*app.NodeView, error) {
+	if order != "" {
+		if err := checkExpressionOrder(order); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := i.checkReader(actorId, ids); err != nil {
+		return nil, err
+	}
+
+	nodesView, err := i.NodeRepo.View(ids, order, actorId)
 	if err != nil {
 		return nil, err
 	}
@@ -49,46 +129,158 @@ func (i *NodeInteractor) View(ids []valueobject.ID) (*app.NodeView, error) {
 }
 
 func (i *NodeInteractor) Update(actorId *valueobject.ID, node app.FlatNode) error {
-	member, err := i.GroupRepo.FindMemberByNodeId(node.Id, actorId)
+	if err := checkNodeVisibility(node.Visibility); err != nil {
+		return err
+	}
+
+	err := i.checkEditor(actorId, node.Id)
 	if err != nil {
 		return err
 	}
 
-	if member.Role == app.UserReader {
-		return errors.New("Fobidden, only admin or editor can edit node.")
+	before, err := i.NodeRepo.Get(node.Id)
+	if err != nil {
+		return err
 	}
 
-	err = i.NodeRepo.Update(node)
+	err = i.NodeRepo.Update(node, actorId)
 	if err != nil {
 		return err
 	}
 
+	logActivity(i.ActivityRepo.LogByNode(node.Id, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityNodeUpdate,
+		TargetType: app.TargetNode,
+		TargetId:   node.Id,
+		Before:     map[string]interface{}{"name": before.Name, "visibility": before.Visibility},
+		After:      map[string]interface{}{"name": node.Name, "visibility": node.Visibility},
+	}))
+
 	return nil
 }
 
-func (i *NodeInteractor) AttachExpression(nodeId *valueobject.ID, inExpr app.Expression) (*app.Expression, error) {
+func (i *NodeInteractor) AttachExpression(actorId *valueobject.ID, nodeId *valueobject.ID, inExpr app.Expression) (*app.Expression, error) {
 	if inExpr.Id == nil {
-		inExpr.Value = strings.TrimSpace(inExpr.Value)
+		inExpr.Value = domain.NormalizeValue(inExpr.Value)
 
 		if inExpr.Value == "" {
 			return nil, errors.New("You need to specify expression id or value.")
 		}
 	}
 
-	expression, err := i.NodeRepo.AttachExpression(nodeId, inExpr)
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkStatic([]valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	expression, err := i.NodeRepo.AttachExpression(nodeId, inExpr, actorId)
 	if err != nil {
 		return nil, err
 	}
 
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityExpressionAttach,
+		TargetType: app.TargetExpression,
+		TargetId:   expression.Id,
+		After:      map[string]interface{}{"nodeId": nodeId, "expression": expression},
+	}))
+
+	expression.Suggestions = i.suggest(nodeId, expression.Id)
+	expression.Generated = i.prefillTranscription(nodeId, expression)
+
 	return expression, nil
 }
 
-func (i *NodeInteractor) DetachExpression(nodeId *valueobject.ID, expressionId *valueobject.ID) error {
-	err := i.NodeRepo.DetachExpression(nodeId, expressionId)
+func (i *NodeInteractor) DetachExpression(actorId *valueobject.ID, nodeId *valueobject.ID, expressionId *valueobject.ID) error {
+	err := i.checkEditor(actorId, nodeId)
 	if err != nil {
 		return err
 	}
 
+	err = i.checkStatic([]valueobject.ID{*nodeId})
+	if err != nil {
+		return err
+	}
+
+	err = i.NodeRepo.DetachExpression(nodeId, expressionId, actorId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityExpressionDetach,
+		TargetType: app.TargetExpression,
+		TargetId:   expressionId,
+		Before:     map[string]interface{}{"nodeId": nodeId, "expressionId": expressionId},
+	}))
+
+	return nil
+}
+
+// ReorderExpressions configures how expressions of the slice are listed and used by trainings.
+// Manual order takes positions from the given expressions, the others follow them.
+func (i *NodeInteractor) ReorderExpressions(actorId *valueobject.ID, nodeId *valueobject.ID, order app.ExpressionOrder, expressionIds []valueobject.ID) error {
+	if err := checkExpressionOrder(order); err != nil {
+		return err
+	}
+
+	if len(expressionIds) > 0 && order != app.ExpressionOrderManual {
+		return errors.New("Expression positions can be set only for the manual order.")
+	}
+
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return err
+	}
+
+	node, err := i.NodeRepo.Get(nodeId)
+	if err != nil {
+		return err
+	}
+
+	if !node.Type.IsSlice() {
+		return errors.New("Only slice expressions can be ordered.")
+	}
+
+	if node.Type == app.NodeSmart && order == app.ExpressionOrderManual {
+		return errors.New("Smart slice contents are computed from its query and can't be ordered manually.")
+	}
+
+	attached := make(map[valueobject.ID]bool)
+	for _, expr := range node.Expressions {
+		attached[*expr.Id] = true
+	}
+
+	seen := make(map[valueobject.ID]bool)
+	for _, id := range expressionIds {
+		if !attached[id] {
+			return fmt.Errorf("Expression %d isn't attached to the slice.", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("Expression %d is listed more than once.", id)
+		}
+		seen[id] = true
+	}
+
+	err = i.NodeRepo.ReorderExpressions(nodeId, order, expressionIds, actorId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityNodeUpdate,
+		TargetType: app.TargetNode,
+		TargetId:   nodeId,
+		Before:     map[string]interface{}{"expressionOrder": node.ExpressionOrder},
+		After:      map[string]interface{}{"expressionOrder": order, "expressionIds": expressionIds},
+	}))
+
 	return nil
 }
 
@@ -101,46 +293,263 @@ func (i *NodeInteractor) AvailableTranslations(nodeId *valueobject.ID, expressio
 	return translations, nil
 }
 
-func (i *NodeInteractor) AttachTranslation(nodeId *valueobject.ID, expressionId *valueobject.ID, inTranslation app.Translation) (*app.Translation, error) {
+func (i *NodeInteractor) AttachTranslation(actorId *valueobject.ID, nodeId *valueobject.ID, expressionId *valueobject.ID, inTranslation app.Translation) (*app.Translation, error) {
 	if inTranslation.Id == nil {
-		inTranslation.Value = strings.TrimSpace(inTranslation.Value)
+		inTranslation.Value = domain.NormalizeValue(inTranslation.Value)
 
 		if inTranslation.Value == "" {
 			return nil, errors.New("You need to specify translation id or value.")
 		}
 	}
 
-	translation, err := i.NodeRepo.AttachTranslation(nodeId, expressionId, inTranslation)
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkStatic([]valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	translation, err := i.NodeRepo.AttachTranslation(nodeId, expressionId, inTranslation, actorId)
 	if err != nil {
 		return nil, err
 	}
 
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTranslationAttach,
+		TargetType: app.TargetTranslation,
+		TargetId:   translation.Id,
+		After:      map[string]interface{}{"nodeId": nodeId, "expressionId": expressionId, "translation": translation},
+	}))
+
 	return translation, nil
 }
 
-func (i *NodeInteractor) DetachTranslation(nodeId *valueobject.ID, translationId *valueobject.ID) error {
-	err := i.NodeRepo.DetachTranslation(nodeId, translationId)
+func (i *NodeInteractor) DetachTranslation(actorId *valueobject.ID, nodeId *valueobject.ID, translationId *valueobject.ID) error {
+	err := i.checkEditor(actorId, nodeId)
+	if err != nil {
+		return err
+	}
+
+	err = i.checkStatic([]valueobject.ID{*nodeId})
+	if err != nil {
+		return err
+	}
+
+	err = i.NodeRepo.DetachTranslation(nodeId, translationId, actorId)
 	if err != nil {
 		return err
 	}
 
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTranslationDetach,
+		TargetType: app.TargetTranslation,
+		TargetId:   translationId,
+		Before:     map[string]interface{}{"nodeId": nodeId, "translationId": translationId},
+	}))
+
 	return nil
 }
 
-func (i *NodeInteractor) AttachText(nodeId *valueobject.ID, inText app.Text) (*app.Text, error) {
-	text, err := i.NodeRepo.AttachText(nodeId, inText)
+func (i *NodeInteractor) AttachText(actorId *valueobject.ID, nodeId *valueobject.ID, inText app.Text) (*app.Text, error) {
+	err := i.checkEditor(actorId, nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := i.NodeRepo.AttachText(nodeId, inText, actorId)
 	if err != nil {
 		return nil, err
 	}
 
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTextAttach,
+		TargetType: app.TargetText,
+		TargetId:   text.Id,
+		After:      map[string]interface{}{"nodeId": nodeId, "text": text},
+	}))
+
 	return text, nil
 }
 
 func (i *NodeInteractor) DetachText(actorId *valueobject.ID, nodeId *valueobject.ID) error {
-	err := i.NodeRepo.DetachText(nodeId)
+	err := i.checkEditor(actorId, nodeId)
 	if err != nil {
 		return err
 	}
 
+	node, err := i.NodeRepo.Get(nodeId)
+	if err != nil {
+		return err
+	}
+
+	err = i.NodeRepo.DetachText(nodeId, actorId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTextDetach,
+		TargetType: app.TargetText,
+		TargetId:   node.TextId,
+		Before:     map[string]interface{}{"nodeId": nodeId, "textId": node.TextId},
+	}))
+
+	return nil
+}
+
+// CopyNode duplicates the node with its subtree into the folder of the target group,
+// top level when no folder given. Groups must share the language pair.
+func (i *NodeInteractor) CopyNode(actorId *valueobject.ID, nodeId *valueobject.ID, groupId *valueobject.ID, folderId *valueobject.ID) (*app.Node, error) {
+	if err := i.checkReader(actorId, []valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only non-reader user of a group can copy node into it.", app.UserAdmin, app.UserEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := i.NodeRepo.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := i.GroupRepo.Get(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	if source.TargetLangCode != target.TargetLangCode || source.NativeLangCode != target.NativeLangCode {
+		return nil, errors.New("Node can be copied only into a group with the same language pair.")
+	}
+
+	path := ""
+	if folderId != nil {
+		nodes, err := i.NodeRepo.List(groupId)
+		if err != nil {
+			return nil, err
+		}
+
+		var folder *app.FlatNode
+		for _, n := range nodes {
+			if *n.Id == *folderId {
+				folder = n
+				break
+			}
+		}
+
+		if folder == nil || folder.Type != app.NodeFolder {
+			return nil, errors.New("Target folder doesn't belong to the group.")
+		}
+
+		path = folder.ChildPath()
+	}
+
+	copyId, err := i.NodeRepo.Copy(nodeId, groupId, path, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityNodeCopy,
+		TargetType: app.TargetNode,
+		TargetId:   copyId,
+		Before:     map[string]interface{}{"nodeId": nodeId},
+		After:      map[string]interface{}{"nodeId": copyId, "path": path},
+	}))
+
+	return i.NodeRepo.Get(copyId)
+}
+
+// MergeNodes moves attachments of the source slices into the target slice
+// and optionally deletes emptied sources into the trash.
+func (i *NodeInteractor) MergeNodes(actorId *valueobject.ID, targetId *valueobject.ID, sourceIds []valueobject.ID, removeSources bool) error {
+	if targetId == nil || len(sourceIds) == 0 {
+		return errors.New("You need to specify target and source slices.")
+	}
+
+	ids := []valueobject.ID{*targetId}
+	seen := map[valueobject.ID]bool{*targetId: true}
+	for _, id := range sourceIds {
+		if seen[id] {
+			return errors.New("Source slices must be unique and differ from the target.")
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		id := id
+		if err := i.checkEditor(actorId, &id); err != nil {
+			return err
+		}
+	}
+
+	sliceIds, err := i.NodeRepo.FilterSliceIds(ids)
+	if err != nil {
+		return err
+	}
+
+	if len(sliceIds) != len(ids) {
+		return errors.New("Only slices can be merged.")
+	}
+
+	if err := i.checkStatic(ids); err != nil {
+		return err
+	}
+
+	target, err := i.NodeRepo.GetGroupByNode(targetId)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range sourceIds {
+		id := id
+		source, err := i.NodeRepo.GetGroupByNode(&id)
+		if err != nil {
+			return err
+		}
+
+		if source.TargetLangCode != target.TargetLangCode || source.NativeLangCode != target.NativeLangCode {
+			return errors.New("Only slices with the same language pair can be merged.")
+		}
+	}
+
+	removedFrom, err := i.NodeRepo.Merge(targetId, sourceIds, actorId, removeSources)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(targetId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityNodeMerge,
+		TargetType: app.TargetNode,
+		TargetId:   targetId,
+		After:      map[string]interface{}{"sourceIds": sourceIds},
+	}))
+
+	for _, id := range sourceIds {
+		id := id
+		groupId, ok := removedFrom[id]
+		if !ok {
+			continue
+		}
+
+		logActivity(i.ActivityRepo.Log(app.Activity{
+			GroupId:    groupId,
+			ActorId:    actorId,
+			Action:     app.ActivityNodeDelete,
+			TargetType: app.TargetNode,
+			TargetId:   &id,
+		}))
+	}
+
 	return nil
 }
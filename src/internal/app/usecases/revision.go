@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+func (i *NodeInteractor) checkMember(actorId *valueobject.ID, nodeId *valueobject.ID) error {
+	member, err := i.GroupRepo.FindMemberByNodeId(nodeId, actorId)
+	if err != nil {
+		return err
+	}
+
+	if member.Status != app.MemberActive {
+		return errors.New("Forbidden, only active member can see node history.")
+	}
+
+	return nil
+}
+
+func (i *NodeInteractor) getRevision(nodeId *valueobject.ID, number uint) (*app.NodeRevision, error) {
+	revision, err := i.NodeRepo.GetRevision(nodeId, number)
+	if err != nil {
+		log.Println(err)
+		return nil, fmt.Errorf("Revision %d of the node doesn't exist.", number)
+	}
+
+	return revision, nil
+}
+
+// History lists revisions of the node content, the latest first.
+func (i *NodeInteractor) History(actorId *valueobject.ID, nodeId *valueobject.ID) ([]*app.NodeRevision, error) {
+	if err := i.checkMember(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	return i.NodeRepo.ListRevisions(nodeId)
+}
+
+// DiffRevisions tells what changed in the node between two revisions, in either direction.
+func (i *NodeInteractor) DiffRevisions(actorId *valueobject.ID, nodeId *valueobject.ID, from uint, to uint) (*app.NodeDiff, error) {
+	if err := i.checkMember(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := i.getRevision(nodeId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := i.getRevision(nodeId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := app.DiffSnapshots(fromRevision.Snapshot, toRevision.Snapshot)
+	diff.From = from
+	diff.To = to
+
+	return diff, nil
+}
+
+// RevertNode brings the node content back to the revision, which is recorded as a new revision.
+func (i *NodeInteractor) RevertNode(actorId *valueobject.ID, nodeId *valueobject.ID, number uint) (*app.NodeRevision, error) {
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkStatic([]valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	revision, err := i.getRevision(nodeId, number)
+	if err != nil {
+		return nil, err
+	}
+
+	reverted, err := i.NodeRepo.ApplySnapshot(nodeId, revision.Snapshot, actorId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityNodeRevert,
+		TargetType: app.TargetNode,
+		TargetId:   nodeId,
+		After:      map[string]interface{}{"revision": number},
+	}))
+
+	return reverted, nil
+}
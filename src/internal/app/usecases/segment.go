@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/app/services"
+	"github.com/alexkarpovich/lst-api/src/internal/domain"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// maxSegmentedLength keeps the number of looked up candidate words of the content moderate.
+const maxSegmentedLength = 5000
+
+// segment splits the content into tokens and links word tokens to expressions of the language.
+func segment(ss services.SegmenterService, er domain.ExpressionRepo, langCode string, content string) (*app.Segmentation, error) {
+	if utf8.RuneCountInString(content) > maxSegmentedLength {
+		return nil, fmt.Errorf("Content can't be longer than %d characters.", maxSegmentedLength)
+	}
+
+	tokens, err := ss.Segmenter(langCode).Segment(content)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []string{}
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		value := strings.ToLower(token.Value)
+		if token.IsWord && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	segmentation := &app.Segmentation{
+		LangCode:    langCode,
+		Tokens:      tokens,
+		Expressions: []*app.KnownExpression{},
+	}
+
+	if len(values) == 0 {
+		return segmentation, nil
+	}
+
+	found, err := er.FindByValues(langCode, values)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]*app.KnownExpression)
+	for _, token := range tokens {
+		expression, ok := found[strings.ToLower(token.Value)]
+		if !token.IsWord || !ok {
+			continue
+		}
+
+		token.ExpressionId = expression.Id
+
+		if _, ok := known[expression.Value]; !ok {
+			known[expression.Value] = &app.KnownExpression{Id: expression.Id, Value: expression.Value}
+			segmentation.Expressions = append(segmentation.Expressions, known[expression.Value])
+		}
+		known[expression.Value].Count++
+	}
+
+	return segmentation, nil
+}
+
+// Segment splits a long expression or any other content of the language.
+func (i *ExpressionInteractor) Segment(langCode string, content string) (*app.Segmentation, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("Content is required.")
+	}
+
+	return segment(i.Segmenters, i.ExpressionRepo, langCode, content)
+}
+
+// SegmentText splits the text attached to the node, the text is in the target language of the group.
+func (i *NodeInteractor) SegmentText(actorId *valueobject.ID, nodeId *valueobject.ID) (*app.Segmentation, error) {
+	if err := i.checkReader(actorId, []valueobject.ID{*nodeId}); err != nil {
+		return nil, err
+	}
+
+	text, err := i.NodeRepo.GetText(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if text == nil {
+		return nil, errors.New("Node has no text.")
+	}
+
+	group, err := i.NodeRepo.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	return segment(i.Segmenters, i.ExpressionRepo, group.TargetLangCode, text.Content)
+}
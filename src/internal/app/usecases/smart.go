@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// checkSmartQuery validates the query of the group smart slice and makes the actor its owner.
+// Failures are counted for each viewer when the slice is read, so nobody sees training results of other members.
+func checkSmartQuery(nodeRepo app.NodeRepo, groupId *valueobject.ID, actorId *valueobject.ID, query *app.SmartQuery) error {
+	if query == nil {
+		return errors.New("Smart slice needs a query.")
+	}
+
+	switch query.Rule {
+	case app.SmartRuleFailed:
+		if query.Failures == 0 {
+			return errors.New("Failed rule needs at least one failure.")
+		}
+	case app.SmartRuleRecent:
+		if query.Days == 0 {
+			return errors.New("Recent rule needs the number of days.")
+		}
+	case app.SmartRuleNoTranscription:
+	default:
+		return fmt.Errorf("Unknown smart rule \"%s\".", query.Rule)
+	}
+
+	query.OwnerId = actorId
+
+	query.SourceIds = uniqueIds(query.SourceIds)
+	for _, id := range query.SourceIds {
+		id := id
+		group, err := nodeRepo.GetGroupByNode(&id)
+		if err != nil || *group.Id != *groupId {
+			return fmt.Errorf("Source node %d doesn't belong to the group.", id)
+		}
+	}
+
+	return nil
+}
+
+// checkStatic denies editing contents of smart slices, they are computed from their queries.
+func (i *NodeInteractor) checkStatic(nodeIds []valueobject.ID) error {
+	smart, err := i.NodeRepo.HasSmart(nodeIds)
+	if err != nil {
+		return err
+	}
+
+	if smart {
+		return errors.New("Smart slice contents are computed from its query and can't be edited.")
+	}
+
+	return nil
+}
+
+// UpdateSmartQuery replaces the query of the smart slice, its contents follow on the next read.
+func (i *NodeInteractor) UpdateSmartQuery(actorId *valueobject.ID, nodeId *valueobject.ID, query *app.SmartQuery) (*app.Node, error) {
+	if err := i.checkEditor(actorId, nodeId); err != nil {
+		return nil, err
+	}
+
+	node, err := i.NodeRepo.Get(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.Type != app.NodeSmart {
+		return nil, errors.New("Only smart slices have a query.")
+	}
+
+	group, err := i.NodeRepo.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSmartQuery(i.NodeRepo, group.Id, actorId, query); err != nil {
+		return nil, err
+	}
+
+	err = i.NodeRepo.UpdateSmartQuery(nodeId, query)
+	if err != nil {
+		return nil, err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityNodeUpdate,
+		TargetType: app.TargetNode,
+		TargetId:   nodeId,
+		Before:     map[string]interface{}{"smartQuery": node.SmartQuery},
+		After:      map[string]interface{}{"smartQuery": query},
+	}))
+
+	return i.NodeRepo.Get(nodeId)
+}
@@ -0,0 +1,440 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+const (
+	maxTagNameLength    = 64
+	maxFilterNameLength = 128
+)
+
+type TagInteractor struct {
+	TagRepo      app.TagRepo
+	GroupRepo    app.GroupRepo
+	NodeRepo     app.NodeRepo
+	ActivityRepo app.ActivityRepo
+}
+
+func NewTagInteractor(tgr app.TagRepo, gr app.GroupRepo, nr app.NodeRepo, ar app.ActivityRepo) *TagInteractor {
+	return &TagInteractor{tgr, gr, nr, ar}
+}
+
+func (i *TagInteractor) checkMember(actorId *valueobject.ID, groupId *valueobject.ID, editor bool) error {
+	if editor {
+		_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only admin or editor can manage tags.", app.UserAdmin, app.UserEditor)
+		return err
+	}
+
+	_, err := requireActiveRole(i.GroupRepo, groupId, actorId, "Forbidden, only active member of the group can do this.")
+	return err
+}
+
+func checkTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("Tag name is required.")
+	}
+
+	if len([]rune(name)) > maxTagNameLength {
+		return "", fmt.Errorf("Tag name can't be longer than %d characters.", maxTagNameLength)
+	}
+
+	return name, nil
+}
+
+func (i *TagInteractor) getTag(tagId *valueobject.ID) (*app.Tag, error) {
+	tag, err := i.TagRepo.Get(tagId)
+	if err != nil {
+		return nil, errors.New("Tag doesn't exist.")
+	}
+
+	return tag, nil
+}
+
+// nodeTag checks the actor can edit the node and the tag belongs to the group of the node.
+func (i *TagInteractor) nodeTag(actorId *valueobject.ID, nodeId *valueobject.ID, tagId *valueobject.ID) (*app.Tag, error) {
+	group, err := i.NodeRepo.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.checkMember(actorId, group.Id, true); err != nil {
+		return nil, err
+	}
+
+	tag, err := i.getTag(tagId)
+	if err != nil {
+		return nil, err
+	}
+
+	if *tag.GroupId != *group.Id {
+		return nil, errors.New("Tag belongs to another group.")
+	}
+
+	return tag, nil
+}
+
+func (i *TagInteractor) CreateTag(actorId *valueobject.ID, groupId *valueobject.ID, name string) (*app.Tag, error) {
+	name, err := checkTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.checkMember(actorId, groupId, true); err != nil {
+		return nil, err
+	}
+
+	tag, err := i.TagRepo.Create(app.Tag{GroupId: groupId, Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     app.ActivityTagCreate,
+		TargetType: app.TargetTag,
+		TargetId:   tag.Id,
+		After:      tag,
+	}))
+
+	return tag, nil
+}
+
+func (i *TagInteractor) ListTags(actorId *valueobject.ID, groupId *valueobject.ID) ([]*app.Tag, error) {
+	if err := i.checkMember(actorId, groupId, false); err != nil {
+		return nil, err
+	}
+
+	return i.TagRepo.List(groupId)
+}
+
+func (i *TagInteractor) RenameTag(actorId *valueobject.ID, tagId *valueobject.ID, name string) error {
+	name, err := checkTagName(name)
+	if err != nil {
+		return err
+	}
+
+	tag, err := i.getTag(tagId)
+	if err != nil {
+		return err
+	}
+
+	if err := i.checkMember(actorId, tag.GroupId, true); err != nil {
+		return err
+	}
+
+	err = i.TagRepo.Rename(tagId, name)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    tag.GroupId,
+		ActorId:    actorId,
+		Action:     app.ActivityTagUpdate,
+		TargetType: app.TargetTag,
+		TargetId:   tagId,
+		Before:     map[string]interface{}{"name": tag.Name},
+		After:      map[string]interface{}{"name": name},
+	}))
+
+	return nil
+}
+
+// DeleteTag removes the tag everywhere, saved filters just stop matching it.
+func (i *TagInteractor) DeleteTag(actorId *valueobject.ID, tagId *valueobject.ID) error {
+	tag, err := i.getTag(tagId)
+	if err != nil {
+		return err
+	}
+
+	if err := i.checkMember(actorId, tag.GroupId, true); err != nil {
+		return err
+	}
+
+	err = i.TagRepo.Delete(tagId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.Log(app.Activity{
+		GroupId:    tag.GroupId,
+		ActorId:    actorId,
+		Action:     app.ActivityTagDelete,
+		TargetType: app.TargetTag,
+		TargetId:   tagId,
+		Before:     tag,
+	}))
+
+	return nil
+}
+
+func (i *TagInteractor) NodeTags(actorId *valueobject.ID, nodeId *valueobject.ID) (*app.NodeTags, error) {
+	group, err := i.NodeRepo.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.checkMember(actorId, group.Id, false); err != nil {
+		return nil, err
+	}
+
+	return i.TagRepo.NodeTags(nodeId)
+}
+
+func (i *TagInteractor) TagNode(actorId *valueobject.ID, nodeId *valueobject.ID, tagId *valueobject.ID) error {
+	tag, err := i.nodeTag(actorId, nodeId, tagId)
+	if err != nil {
+		return err
+	}
+
+	err = i.TagRepo.TagNode(nodeId, tagId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTagAttach,
+		TargetType: app.TargetTag,
+		TargetId:   tagId,
+		After:      map[string]interface{}{"nodeId": nodeId, "name": tag.Name},
+	}))
+
+	return nil
+}
+
+func (i *TagInteractor) UntagNode(actorId *valueobject.ID, nodeId *valueobject.ID, tagId *valueobject.ID) error {
+	tag, err := i.nodeTag(actorId, nodeId, tagId)
+	if err != nil {
+		return err
+	}
+
+	err = i.TagRepo.UntagNode(nodeId, tagId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTagDetach,
+		TargetType: app.TargetTag,
+		TargetId:   tagId,
+		Before:     map[string]interface{}{"nodeId": nodeId, "name": tag.Name},
+	}))
+
+	return nil
+}
+
+func (i *TagInteractor) TagExpression(actorId *valueobject.ID, nodeId *valueobject.ID, expressionId *valueobject.ID, tagId *valueobject.ID) error {
+	tag, err := i.nodeTag(actorId, nodeId, tagId)
+	if err != nil {
+		return err
+	}
+
+	smart, err := i.NodeRepo.HasSmart([]valueobject.ID{*nodeId})
+	if err != nil {
+		return err
+	}
+
+	if smart {
+		return errors.New("Links of smart slices are computed from their queries and can't be tagged.")
+	}
+
+	err = i.TagRepo.TagExpression(nodeId, expressionId, tagId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTagAttach,
+		TargetType: app.TargetTag,
+		TargetId:   tagId,
+		After:      map[string]interface{}{"nodeId": nodeId, "expressionId": expressionId, "name": tag.Name},
+	}))
+
+	return nil
+}
+
+func (i *TagInteractor) UntagExpression(actorId *valueobject.ID, nodeId *valueobject.ID, expressionId *valueobject.ID, tagId *valueobject.ID) error {
+	tag, err := i.nodeTag(actorId, nodeId, tagId)
+	if err != nil {
+		return err
+	}
+
+	err = i.TagRepo.UntagExpression(nodeId, expressionId, tagId)
+	if err != nil {
+		return err
+	}
+
+	logActivity(i.ActivityRepo.LogByNode(nodeId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTagDetach,
+		TargetType: app.TargetTag,
+		TargetId:   tagId,
+		Before:     map[string]interface{}{"nodeId": nodeId, "expressionId": expressionId, "name": tag.Name},
+	}))
+
+	return nil
+}
+
+// checkFilter normalizes the filter and makes sure its tags and nodes belong to the group.
+func (i *TagInteractor) checkFilter(filter *app.SavedFilter) error {
+	filter.Name = strings.TrimSpace(filter.Name)
+	if filter.Name == "" {
+		return errors.New("Filter name is required.")
+	}
+
+	if len([]rune(filter.Name)) > maxFilterNameLength {
+		return fmt.Errorf("Filter name can't be longer than %d characters.", maxFilterNameLength)
+	}
+
+	switch filter.Match {
+	case "":
+		filter.Match = app.TagMatchAny
+	case app.TagMatchAny, app.TagMatchAll:
+	default:
+		return fmt.Errorf("Unknown tag match \"%s\".", filter.Match)
+	}
+
+	filter.TagIds = uniqueIds(filter.TagIds)
+	if len(filter.TagIds) == 0 {
+		return errors.New("Filter needs at least one tag.")
+	}
+
+	tags, err := i.TagRepo.List(filter.GroupId)
+	if err != nil {
+		return err
+	}
+
+	groupTags := make(map[valueobject.ID]bool)
+	for _, tag := range tags {
+		groupTags[*tag.Id] = true
+	}
+
+	for _, id := range filter.TagIds {
+		if !groupTags[id] {
+			return fmt.Errorf("Tag %d doesn't belong to the group.", id)
+		}
+	}
+
+	filter.NodeIds = uniqueIds(filter.NodeIds)
+	if len(filter.NodeIds) == 0 {
+		return nil
+	}
+
+	nodes, err := i.NodeRepo.List(filter.GroupId)
+	if err != nil {
+		return err
+	}
+
+	groupNodes := make(map[valueobject.ID]bool)
+	for _, node := range nodes {
+		groupNodes[*node.Id] = true
+	}
+
+	for _, id := range filter.NodeIds {
+		if !groupNodes[id] {
+			return fmt.Errorf("Node %d doesn't belong to the group.", id)
+		}
+	}
+
+	return nil
+}
+
+// uniqueIds returns sorted ids without repeats.
+func uniqueIds(ids []valueobject.ID) []valueobject.ID {
+	unique := []valueobject.ID{}
+	seen := make(map[valueobject.ID]bool)
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	sort.Slice(unique, func(a, b int) bool { return unique[a] < unique[b] })
+
+	return unique
+}
+
+// getFilter returns the filter when the actor owns it and is still an active member of its group.
+func (i *TagInteractor) getFilter(actorId *valueobject.ID, filterId *valueobject.ID) (*app.SavedFilter, error) {
+	filter, err := i.TagRepo.GetFilter(filterId)
+	if err != nil {
+		return nil, errors.New("Saved filter doesn't exist.")
+	}
+
+	if *filter.OwnerId != *actorId {
+		return nil, errors.New("Forbidden, only filter owner can do this.")
+	}
+
+	if err := i.checkMember(actorId, filter.GroupId, false); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// CreateFilter saves a personal virtual slice in the group, readers can save filters too.
+func (i *TagInteractor) CreateFilter(actorId *valueobject.ID, filter app.SavedFilter) (*app.SavedFilter, error) {
+	if err := i.checkMember(actorId, filter.GroupId, false); err != nil {
+		return nil, err
+	}
+
+	filter.OwnerId = actorId
+	if err := i.checkFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	return i.TagRepo.CreateFilter(filter)
+}
+
+func (i *TagInteractor) ListFilters(actorId *valueobject.ID, groupId *valueobject.ID) ([]*app.SavedFilter, error) {
+	if err := i.checkMember(actorId, groupId, false); err != nil {
+		return nil, err
+	}
+
+	return i.TagRepo.ListFilters(groupId, actorId)
+}
+
+func (i *TagInteractor) UpdateFilter(actorId *valueobject.ID, filter app.SavedFilter) error {
+	current, err := i.getFilter(actorId, filter.Id)
+	if err != nil {
+		return err
+	}
+
+	filter.GroupId = current.GroupId
+	if err := i.checkFilter(&filter); err != nil {
+		return err
+	}
+
+	return i.TagRepo.UpdateFilter(filter)
+}
+
+func (i *TagInteractor) DeleteFilter(actorId *valueobject.ID, filterId *valueobject.ID) error {
+	if _, err := i.getFilter(actorId, filterId); err != nil {
+		return err
+	}
+
+	return i.TagRepo.DeleteFilter(filterId)
+}
+
+// ViewFilter lists expressions of the virtual slice with their translations.
+func (i *TagInteractor) ViewFilter(actorId *valueobject.ID, filterId *valueobject.ID) ([]*app.Expression, error) {
+	filter, err := i.getFilter(actorId, filterId)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.TagRepo.FilterExpressions(filter)
+}
@@ -12,34 +12,71 @@ import (
 type TrainingInteractor struct {
 	TrainingRepo    app.TrainingRepo
 	NodeRepo        app.NodeRepo
+	TagRepo         app.TagRepo
+	GroupRepo       app.GroupRepo
 	TrainingService services.TrainingService
 }
 
-func NewTrainingInteractor(tr app.TrainingRepo, nr app.NodeRepo, ts services.TrainingService) *TrainingInteractor {
-	return &TrainingInteractor{tr, nr, ts}
+func NewTrainingInteractor(tr app.TrainingRepo, nr app.NodeRepo, tgr app.TagRepo, gr app.GroupRepo, ts services.TrainingService) *TrainingInteractor {
+	return &TrainingInteractor{tr, nr, tgr, gr, ts}
 }
 
+// checkFilters allows training on saved filters of the owner within groups the owner is still active in.
+func (i *TrainingInteractor) checkFilters(ownerId *valueobject.ID, filterIds []valueobject.ID) error {
+	for _, id := range filterIds {
+		id := id
+		filter, err := i.TagRepo.GetFilter(&id)
+		if err != nil {
+			return errors.New("Saved filter doesn't exist.")
+		}
+
+		if *filter.OwnerId != *ownerId {
+			return errors.New("Forbidden, only filter owner can train on it.")
+		}
+
+		_, err = requireActiveRole(i.GroupRepo, filter.GroupId, ownerId, "Forbidden, only active member of the group can do this.")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetOrCreate returns the training on the slices and saved filters, which act as virtual slices.
 func (i *TrainingInteractor) GetOrCreate(inTraining app.Training) (*app.Training, error) {
-	sliceOnlyIds, err := i.NodeRepo.FilterSliceIds(inTraining.Slices)
-	if err != nil {
-		return nil, err
+	var err error
+
+	sliceOnlyIds := []valueobject.ID{}
+	if len(inTraining.Slices) > 0 {
+		sliceOnlyIds, err = i.NodeRepo.FilterSliceIds(inTraining.Slices)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if len(sliceOnlyIds) == 0 {
-		return nil, errors.New("There must be at least one node.")
+	filterIds := uniqueIds(inTraining.Filters)
+
+	if len(sliceOnlyIds)+len(filterIds) == 0 {
+		return nil, errors.New("There must be at least one node or saved filter.")
 	}
 
 	if !i.TrainingRepo.HasCreatePermission(inTraining.OwnerId, sliceOnlyIds) {
 		return nil, errors.New("Forbidden, only user which has at least read role can do this.")
 	}
 
+	if err := i.checkFilters(inTraining.OwnerId, filterIds); err != nil {
+		return nil, err
+	}
+
 	inTraining.Slices = sliceOnlyIds
+	inTraining.Filters = filterIds
 
 	if trn, err := i.TrainingRepo.GetBySlices(inTraining); err == nil && trn != nil {
 		return trn, nil
 	}
 
-	trainingService := training.NewService(i.NodeRepo, i.TrainingRepo, inTraining)
+	trainingService := training.NewService(i.NodeRepo, i.TrainingRepo, i.TagRepo, inTraining)
 	training, err := trainingService.Create()
 	if err != nil {
 		return nil, err
@@ -98,7 +135,7 @@ func (i *TrainingInteractor) Next(actorId *valueobject.ID, trainingId *valueobje
 		return nil, errors.New("Forbidden, only training owner can do this.")
 	}
 
-	trainingService := training.NewService(i.NodeRepo, i.TrainingRepo, *trn)
+	trainingService := training.NewService(i.NodeRepo, i.TrainingRepo, i.TagRepo, *trn)
 	trainingItem, err := trainingService.NextItem()
 	if err != nil {
 		return nil, err
@@ -130,7 +167,7 @@ func (i *TrainingInteractor) ItemAnswers(actorId *valueobject.ID, itemId *valueo
 		return nil, errors.New("Forbidden, only training owner can do this.")
 	}
 
-	trainingService := training.NewService(i.NodeRepo, i.TrainingRepo, *trn)
+	trainingService := training.NewService(i.NodeRepo, i.TrainingRepo, i.TagRepo, *trn)
 	answers, err := trainingService.ItemAnswers(itemId)
 	if err != nil {
 		return nil, err
@@ -156,3 +193,22 @@ func (i *TrainingInteractor) MarkItemAsComplete(actorId *valueobject.ID, itemId
 
 	return nil
 }
+
+// MarkItemAsFailed records a failed answer of the item, smart slices count them.
+func (i *TrainingInteractor) MarkItemAsFailed(actorId *valueobject.ID, itemId *valueobject.ID) error {
+	training, err := i.TrainingRepo.GetByItemId(itemId)
+	if err != nil {
+		return err
+	}
+
+	if *training.OwnerId != *actorId {
+		return errors.New("Forbidden, only training owner can do this.")
+	}
+
+	err = i.TrainingRepo.MarkItemAsFailed(itemId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
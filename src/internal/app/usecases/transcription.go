@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"log"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/app/services"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// generateTranscription returns nil when the type has no transcriber or the value isn't transcribable.
+func generateTranscription(ts services.TranscriberService, typeId *valueobject.ID, value string) (*app.GeneratedTranscription, error) {
+	transcriber, err := ts.Transcriber(typeId)
+	if err != nil || transcriber == nil {
+		return nil, err
+	}
+
+	transcription, err := transcriber.Transcribe(value)
+	if err != nil || transcription == "" {
+		return nil, err
+	}
+
+	return &app.GeneratedTranscription{TypeId: typeId, Value: transcription}, nil
+}
+
+// prefillTranscription generates the transcription of the group type for an expression which has none yet.
+// Failures only leave the transcription out.
+func (i *NodeInteractor) prefillTranscription(nodeId *valueobject.ID, expression *app.Expression) *app.GeneratedTranscription {
+	group, err := i.NodeRepo.GetGroupByNode(nodeId)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	if group.TranscriptionTypeId == nil {
+		return nil
+	}
+
+	exists, err := i.ExpressionRepo.HasTranscription(expression.Id, group.TranscriptionTypeId)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	if exists {
+		return nil
+	}
+
+	value := expression.Value
+	if value == "" {
+		stored, err := i.ExpressionRepo.Get(expression.Id)
+		if err != nil {
+			log.Println(err)
+			return nil
+		}
+		value = stored.Value
+	}
+
+	generated, err := generateTranscription(i.Transcribers, group.TranscriptionTypeId, value)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	return generated
+}
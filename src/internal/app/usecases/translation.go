@@ -1,32 +1,59 @@
 package usecases
 
 import (
+	"github.com/alexkarpovich/lst-api/src/internal/app"
 	"github.com/alexkarpovich/lst-api/src/internal/domain"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
 )
 
 type TranslationInteractor struct {
 	TranslationRepo domain.TranslationRepo
+	GroupRepo       app.GroupRepo
+	ActivityRepo    app.ActivityRepo
 }
 
-func NewTranslationInteractor(tr domain.TranslationRepo) *TranslationInteractor {
-	return &TranslationInteractor{tr}
+func NewTranslationInteractor(tr domain.TranslationRepo, gr app.GroupRepo, ar app.ActivityRepo) *TranslationInteractor {
+	return &TranslationInteractor{tr, gr, ar}
 }
 
-func (i *TranslationInteractor) AttachTranscription(actorId *valueobject.ID, translationId *valueobject.ID, transcriptionId *valueobject.ID) error {
+func (i *TranslationInteractor) AttachTranscription(actorId *valueobject.ID, nodeId *valueobject.ID, translationId *valueobject.ID, transcriptionId *valueobject.ID) error {
+	if err := checkNodeEditor(i.GroupRepo, actorId, nodeId); err != nil {
+		return err
+	}
+
 	err := i.TranslationRepo.AttachTranscription(translationId, transcriptionId)
 	if err != nil {
 		return err
 	}
 
+	logActivity(i.ActivityRepo.LogByTranslation(nodeId, translationId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTranscriptionAttach,
+		TargetType: app.TargetTranslation,
+		TargetId:   translationId,
+		After:      map[string]interface{}{"transcriptionId": transcriptionId},
+	}))
+
 	return nil
 }
 
-func (i *TranslationInteractor) DetachTranscription(actorId *valueobject.ID, translationId *valueobject.ID, transcriptionId *valueobject.ID) error {
+func (i *TranslationInteractor) DetachTranscription(actorId *valueobject.ID, nodeId *valueobject.ID, translationId *valueobject.ID, transcriptionId *valueobject.ID) error {
+	if err := checkNodeEditor(i.GroupRepo, actorId, nodeId); err != nil {
+		return err
+	}
+
 	err := i.TranslationRepo.DetachTranscription(translationId, transcriptionId)
 	if err != nil {
 		return err
 	}
 
+	logActivity(i.ActivityRepo.LogByTranslation(nodeId, translationId, app.Activity{
+		ActorId:    actorId,
+		Action:     app.ActivityTranscriptionDetach,
+		TargetType: app.TargetTranslation,
+		TargetId:   translationId,
+		Before:     map[string]interface{}{"transcriptionId": transcriptionId},
+	}))
+
 	return nil
 }
@@ -21,15 +21,44 @@ type TranscriptionItem struct {
 	Value string          `json:"value" db:"value"`
 }
 
-type TranscriptionPart struct {
-	Expression     Expression           `json:"expression"`
+// ExpressionSearch looks expressions of the language up by their values or transcriptions.
+// Translations are given in the native language when it is set.
+type ExpressionSearch struct {
+	UserId         *valueobject.ID
+	LangCode       string
+	NativeLangCode string
+	Value          string
+	Cursor         *valueobject.ID
+	Limit          uint
+}
+
+type TranslationItem struct {
+	Id       *valueobject.ID `json:"id"`
+	LangCode string          `json:"langCode"`
+	Value    string          `json:"value"`
+	Comment  string          `json:"comment"`
+}
+
+// ExpressionMatch is a found expression, rank grows with similarity and exact prefix matches go first.
+type ExpressionMatch struct {
+	Id             *valueobject.ID      `json:"id"`
+	LangCode       string               `json:"langCode"`
+	Value          string               `json:"value"`
+	Rank           float64              `json:"rank"`
 	Transcriptions []*TranscriptionItem `json:"transcriptions"`
+	Translations   []*TranslationItem   `json:"translations"`
+}
+
+type ExpressionSearchPage struct {
+	Items      []*ExpressionMatch `json:"items"`
+	NextCursor *valueobject.ID    `json:"nextCursor"`
 }
 
 type ExpressionRepo interface {
 	Create(*Expression) (*Expression, error)
 	Get(*valueobject.ID) (*Expression, error)
-	Search(string, string) ([]*Expression, error)
+	Search(ExpressionSearch) ([]*ExpressionMatch, error)
 	CreateTranscription(*valueobject.ID, Transcription) (*Transcription, error)
-	GetTranscriptionMap(*valueobject.ID, *valueobject.ID) (map[string][]*TranscriptionItem, error)
+	HasTranscription(*valueobject.ID, *valueobject.ID) (bool, error)
+	FindByValues(string, []string) (map[string]*Expression, error)
 }
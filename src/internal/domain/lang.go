@@ -9,11 +9,13 @@ type Language struct {
 }
 
 type TranscriptionType struct {
-	Id   *valueobject.ID `json:"id" db:"id"`
-	Name string          `json:"name" db:"name"`
+	Id       *valueobject.ID `json:"id" db:"id"`
+	Name     string          `json:"name" db:"name"`
+	LangCode string          `json:"langCode,omitempty" db:"lang"`
 }
 
 type LangRepo interface {
 	List() ([]*Language, error)
 	ListTranscriptionTypes(string) ([]*TranscriptionType, error)
+	GetTranscriptionType(*valueobject.ID) (*TranscriptionType, error)
 }
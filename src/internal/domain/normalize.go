@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// trailingPunctuation doesn't make a different expression at the end of the value.
+const trailingPunctuation = ".,;:!?。、，；：！？…"
+
+// NormalizeValue applies the NFKC normalization, which folds full-width and other compatibility
+// forms, collapses whitespace and drops trailing punctuation. Values are compared ignoring
+// the case on top of it.
+func NormalizeValue(value string) string {
+	folded := norm.NFKC.String(value)
+
+	return strings.TrimRightFunc(strings.Join(strings.Fields(folded), " "), func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(trailingPunctuation, r)
+	})
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"hello", "hello"},
+		{"  hello   world  ", "hello world"},
+		{"hello!", "hello"},
+		{"hello world ...", "hello world"},
+		{"你好。", "你好"},
+		{"ＯＫ", "OK"},
+		{"ﾃｽﾄ", "テスト"},
+		{"ｶﾞ", "ガ"},
+		{"a　b", "a b"},
+		{"é", "é"},
+		{"?!.", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeValue(tt.value); got != tt.want {
+			t.Errorf("NormalizeValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
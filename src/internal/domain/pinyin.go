@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// numberedSyllable matches a pinyin syllable with the tone number as CC-CEDICT writes it,
+// e.g. "hao3", "lu:4" or "r5".
+var numberedSyllable = regexp.MustCompile(`(?i)[a-zü:]+[1-5]`)
+
+// toneMarks lists the vowel with the mark of tones from the first to the fourth.
+var toneMarks = map[rune][4]string{
+	'a': {"ā", "á", "ǎ", "à"},
+	'e': {"ē", "é", "ě", "è"},
+	'i': {"ī", "í", "ǐ", "ì"},
+	'o': {"ō", "ó", "ǒ", "ò"},
+	'u': {"ū", "ú", "ǔ", "ù"},
+	'ü': {"ǖ", "ǘ", "ǚ", "ǜ"},
+	'A': {"Ā", "Á", "Ǎ", "À"},
+	'E': {"Ē", "É", "Ě", "È"},
+	'I': {"Ī", "Í", "Ǐ", "Ì"},
+	'O': {"Ō", "Ó", "Ǒ", "Ò"},
+	'U': {"Ū", "Ú", "Ǔ", "Ù"},
+	'Ü': {"Ǖ", "Ǘ", "Ǚ", "Ǜ"},
+}
+
+// combiningToneMarks mark syllabic m and n which have no precomposed letters for every tone.
+var combiningToneMarks = [4]string{"\u0304", "\u0301", "\u030c", "\u0300"}
+
+// PinyinToneMarks converts numbered pinyin to pinyin with tone marks, "ni3 hao3" becomes "nǐ hǎo"
+// and "lu:4" becomes "lǜ". The neutral tone number is dropped, text without numbers is kept.
+func PinyinToneMarks(value string) string {
+	return numberedSyllable.ReplaceAllStringFunc(value, func(syllable string) string {
+		tone := int(syllable[len(syllable)-1] - '0')
+		letters := []rune(strings.NewReplacer("u:", "ü", "U:", "Ü", "v", "ü", "V", "Ü").Replace(syllable[:len(syllable)-1]))
+		if tone == 5 {
+			return string(letters)
+		}
+
+		// The mark goes on a or e, on o of ou and on the last vowel otherwise.
+		pos := -1
+		for i, r := range letters {
+			lower := unicode.ToLower(r)
+			if lower == 'a' || lower == 'e' || (lower == 'o' && i+1 < len(letters) && unicode.ToLower(letters[i+1]) == 'u') {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			for i := len(letters) - 1; i >= 0; i-- {
+				if _, ok := toneMarks[letters[i]]; ok {
+					pos = i
+					break
+				}
+			}
+		}
+
+		if pos < 0 {
+			if lower := unicode.ToLower(letters[0]); lower == 'm' || lower == 'n' {
+				return string(letters[:1]) + combiningToneMarks[tone-1] + string(letters[1:])
+			}
+			return string(letters)
+		}
+
+		return string(letters[:pos]) + toneMarks[letters[pos]][tone-1] + string(letters[pos+1:])
+	})
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestPinyinToneMarks(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"ni3 hao3", "nǐ hǎo"},
+		{"zhong1 guo2", "zhōng guó"},
+		{"xie4 xie5", "xiè xie"},
+		{"lu:4", "lǜ"},
+		{"nu:3 er2", "nǚ ér"},
+		{"lv4", "lǜ"},
+		{"Bei3 jing1", "Běi jīng"},
+		{"LU:4", "LǛ"},
+		{"gou3", "gǒu"},
+		{"liu4 gui4", "liù guì"},
+		{"xiao3 hai2 r5", "xiǎo hái r"},
+		{"m2", "m\u0301"},
+		{"ng3", "n\u030cg"},
+		{"A1 Q", "Ā Q"},
+		{"nǐ hǎo", "nǐ hǎo"},
+		{"ka3 la1 O K", "kǎ lā O K"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := PinyinToneMarks(tt.value); got != tt.want {
+			t.Errorf("PinyinToneMarks(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
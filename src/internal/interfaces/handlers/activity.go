@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+type ActivityInteractor interface {
+	List(*valueobject.ID, *valueobject.ID, app.ActivityFilter) (*app.ActivityPage, error)
+}
+
+type activityHandler struct {
+	BaseHanlder
+	activityInteractor ActivityInteractor
+}
+
+func ConfigureActivityHandler(ai ActivityInteractor, r *mux.Router) {
+	h := &activityHandler{
+		BaseHanlder: BaseHanlder{
+			router: r,
+		},
+		activityInteractor: ai,
+	}
+
+	h.router.HandleFunc("/me/groups/{group_id}/activity", h.List()).Methods("GET")
+}
+
+func parseOptionalId(value string) (*valueobject.ID, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	idArg, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	id := valueobject.ID(idArg)
+
+	return &id, nil
+}
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (i *activityHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		queryParams := r.URL.Query()
+		filter := app.ActivityFilter{
+			Action:     app.ActivityAction(queryParams.Get("action")),
+			TargetType: app.ActivityTarget(queryParams.Get("target_type")),
+		}
+
+		if filter.ActorId, err = parseOptionalId(queryParams.Get("actor_id")); err != nil {
+			utils.SendJsonError(w, "Invalid actor id", http.StatusBadRequest)
+			return
+		}
+		if filter.TargetId, err = parseOptionalId(queryParams.Get("target_id")); err != nil {
+			utils.SendJsonError(w, "Invalid target id", http.StatusBadRequest)
+			return
+		}
+		if filter.Cursor, err = parseOptionalId(queryParams.Get("cursor")); err != nil {
+			utils.SendJsonError(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		if filter.Since, err = parseOptionalTime(queryParams.Get("since")); err != nil {
+			utils.SendJsonError(w, "Invalid since date, RFC3339 expected", http.StatusBadRequest)
+			return
+		}
+		if filter.Until, err = parseOptionalTime(queryParams.Get("until")); err != nil {
+			utils.SendJsonError(w, "Invalid until date, RFC3339 expected", http.StatusBadRequest)
+			return
+		}
+		if limit := queryParams.Get("limit"); limit != "" {
+			limitArg, err := strconv.Atoi(limit)
+			if err != nil || limitArg < 0 {
+				utils.SendJsonError(w, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+			filter.Limit = uint(limitArg)
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error activity list user context")
+			return
+		}
+
+		page, err := i.activityInteractor.List(user.Id, &groupId, filter)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, page, http.StatusOK)
+	}
+}
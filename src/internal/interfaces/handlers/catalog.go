@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+type CatalogInteractor interface {
+	Search(app.CatalogFilter) ([]*app.CatalogEntry, error)
+	GetGroup(*valueobject.ID, *valueobject.ID) (*app.CatalogGroup, error)
+	Subscribe(*valueobject.ID, *valueobject.ID) error
+	Unsubscribe(*valueobject.ID, *valueobject.ID) error
+	ListSubscriptions(*valueobject.ID) ([]*app.Subscription, error)
+}
+
+type catalogHandler struct {
+	BaseHanlder
+	catalogInteractor CatalogInteractor
+}
+
+func ConfigureCatalogHandler(ci CatalogInteractor, r *mux.Router) {
+	h := &catalogHandler{
+		BaseHanlder: BaseHanlder{
+			router: r,
+		},
+		catalogInteractor: ci,
+	}
+
+	h.router.HandleFunc("/catalog", h.Search()).Methods("GET")
+	h.router.HandleFunc("/catalog/groups/{group_id}", h.GetGroup()).Methods("GET")
+	h.router.HandleFunc("/catalog/groups/{group_id}/subscribe", h.Subscribe()).Methods("POST")
+	h.router.HandleFunc("/catalog/groups/{group_id}/unsubscribe", h.Unsubscribe()).Methods("POST")
+	h.router.HandleFunc("/me/subscriptions", h.ListSubscriptions()).Methods("GET")
+}
+
+func parseOptionalUint(value string) (uint, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(number), nil
+}
+
+func (i *catalogHandler) Search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+
+		queryParams := r.URL.Query()
+		filter := app.CatalogFilter{
+			TargetLangCode: queryParams.Get("target"),
+			NativeLangCode: queryParams.Get("native"),
+			Search:         queryParams.Get("search"),
+		}
+
+		if filter.MinSize, err = parseOptionalUint(queryParams.Get("min_size")); err != nil {
+			utils.SendJsonError(w, "Invalid min size", http.StatusBadRequest)
+			return
+		}
+		if filter.MaxSize, err = parseOptionalUint(queryParams.Get("max_size")); err != nil {
+			utils.SendJsonError(w, "Invalid max size", http.StatusBadRequest)
+			return
+		}
+		if filter.Limit, err = parseOptionalUint(queryParams.Get("limit")); err != nil {
+			utils.SendJsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		if filter.Offset, err = parseOptionalUint(queryParams.Get("offset")); err != nil {
+			utils.SendJsonError(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+
+		entries, err := i.catalogInteractor.Search(filter)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, entries, http.StatusOK)
+	}
+}
+
+func (i *catalogHandler) GetGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		var userId *valueobject.ID
+		if user := utils.LoggedInUser(r); user != nil {
+			userId = user.Id
+		}
+
+		group, err := i.catalogInteractor.GetGroup(userId, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, group, http.StatusOK)
+	}
+}
+
+func (i *catalogHandler) Subscribe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			utils.SendJsonError(w, "You need to be logged in to subscribe.", http.StatusBadRequest)
+			return
+		}
+
+		err = i.catalogInteractor.Subscribe(user.Id, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *catalogHandler) Unsubscribe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error unsubscribe user context")
+			return
+		}
+
+		err = i.catalogInteractor.Unsubscribe(user.Id, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *catalogHandler) ListSubscriptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error subscriptions user context")
+			return
+		}
+
+		subscriptions, err := i.catalogInteractor.ListSubscriptions(user.Id)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, subscriptions, http.StatusOK)
+	}
+}
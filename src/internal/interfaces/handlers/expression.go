@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/alexkarpovich/lst-api/src/internal/app"
 	"github.com/alexkarpovich/lst-api/src/internal/domain"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
 	"github.com/alexkarpovich/lst-api/src/internal/utils"
@@ -12,9 +14,11 @@ import (
 )
 
 type ExpressionInteractor interface {
-	Search(string, string) ([]*domain.Expression, error)
-	CreateTranscription(*valueobject.ID, domain.Transcription) (*domain.Transcription, error)
-	GetTranscriptionMap(*valueobject.ID, *valueobject.ID) (map[string][]*domain.TranscriptionItem, error)
+	Get(*valueobject.ID, *valueobject.ID) (*app.ExpressionDetail, error)
+	Search(domain.ExpressionSearch) (*domain.ExpressionSearchPage, error)
+	CreateTranscription(*valueobject.ID, *valueobject.ID, *valueobject.ID, domain.Transcription) (*domain.Transcription, error)
+	GenerateTranscription(*valueobject.ID, *valueobject.ID) (*app.GeneratedTranscription, error)
+	Segment(string, string) (*app.Segmentation, error)
 }
 
 type expressionHanlder struct {
@@ -35,26 +39,90 @@ func ConfigureExpressionHandler(ei ExpressionInteractor, r *mux.Router) {
 		Queries("lang", "{[a-z]{2}}").
 		Queries("search", "{.+}").
 		Methods("GET")
+	h.router.HandleFunc("/x/segment", h.Segment()).Methods("POST")
+	h.router.HandleFunc("/x/{expression_id}", h.Get()).Methods("GET")
 	h.router.
-		HandleFunc("/x/{expression_id}/transcription-map", h.GetTranscriptionMap()).
+		HandleFunc("/x/{expression_id}/generated-transcription", h.GenerateTranscription()).
 		Queries("type", "{\\d+}").
 		Methods("GET")
-	h.router.HandleFunc("/x/{expression_id}/transcriptions", h.CreateTranscription()).Methods("POST")
+	// Deprecated: the transcription map is replaced by the generated transcription,
+	// the route is kept for clients which haven't moved to it yet.
+	h.router.
+		HandleFunc("/x/{expression_id}/transcription-map", h.GenerateTranscription()).
+		Queries("type", "{\\d+}").
+		Methods("GET")
+	h.router.
+		HandleFunc("/x/{expression_id}/transcriptions", h.CreateTranscription()).
+		Queries("node", "{\\d+}").
+		Methods("POST")
 	// h.router.HandleFunc("/me/group/{groupId}/slice", h.ListSlices()).Methods("GET")
 }
 
 func (i *expressionHanlder) Search() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		langCode := r.FormValue("lang")
-		searchValue := r.FormValue("search")
+		var err error
 
-		expressions, err := i.expressionInteractor.Search(langCode, searchValue)
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error expression search context")
+			return
+		}
+
+		queryParams := r.URL.Query()
+		search := domain.ExpressionSearch{
+			UserId:         user.Id,
+			LangCode:       queryParams.Get("lang"),
+			NativeLangCode: queryParams.Get("native"),
+			Value:          queryParams.Get("search"),
+		}
+
+		if search.Cursor, err = parseOptionalId(queryParams.Get("cursor")); err != nil {
+			utils.SendJsonError(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+
+		if limit := queryParams.Get("limit"); limit != "" {
+			limitArg, err := strconv.Atoi(limit)
+			if err != nil || limitArg < 0 {
+				utils.SendJsonError(w, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+			search.Limit = uint(limitArg)
+		}
+
+		page, err := i.expressionInteractor.Search(search)
 		if err != nil {
 			utils.SendJsonError(w, err, http.StatusBadRequest)
 			return
 		}
 
-		utils.SendJson(w, expressions, http.StatusOK)
+		utils.SendJson(w, page, http.StatusOK)
+	}
+}
+
+func (i *expressionHanlder) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		expressionIdArg, err := strconv.Atoi(vars["expression_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid expression id", http.StatusBadRequest)
+			return
+		}
+		expressionId := valueobject.ID(expressionIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error expression get context")
+			return
+		}
+
+		detail, err := i.expressionInteractor.Get(user.Id, &expressionId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, detail, http.StatusOK)
 	}
 }
 
@@ -75,17 +143,30 @@ func (i *expressionHanlder) CreateTranscription() http.HandlerFunc {
 		}
 		expressionId := valueobject.ID(expressionIdArg)
 
+		nodeIdArg, err := strconv.Atoi(r.FormValue("node"))
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
 		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
 			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
 			return
 		}
 
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error user context")
+			return
+		}
+
 		inTranscription := domain.Transcription{
 			Type:  s.Type,
 			Value: s.Value,
 		}
 
-		transcription, err := i.expressionInteractor.CreateTranscription(&expressionId, inTranscription)
+		transcription, err := i.expressionInteractor.CreateTranscription(user.Id, &nodeId, &expressionId, inTranscription)
 		if err != nil {
 			utils.SendJsonError(w, err, http.StatusBadRequest)
 			return
@@ -95,7 +176,7 @@ func (i *expressionHanlder) CreateTranscription() http.HandlerFunc {
 	}
 }
 
-func (i *expressionHanlder) GetTranscriptionMap() http.HandlerFunc {
+func (i *expressionHanlder) GenerateTranscription() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		typeIdArg, err := strconv.Atoi(r.FormValue("type"))
 		if err != nil {
@@ -112,12 +193,36 @@ func (i *expressionHanlder) GetTranscriptionMap() http.HandlerFunc {
 		}
 		expressionId := valueobject.ID(expressionIdArg)
 
-		transcriptionMap, err := i.expressionInteractor.GetTranscriptionMap(&expressionId, &typeId)
+		generated, err := i.expressionInteractor.GenerateTranscription(&expressionId, &typeId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, generated, http.StatusOK)
+	}
+}
+
+func (i *expressionHanlder) Segment() http.HandlerFunc {
+	type request struct {
+		LangCode string `json:"langCode"`
+		Content  string `json:"content"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		segmentation, err := i.expressionInteractor.Segment(s.LangCode, s.Content)
 		if err != nil {
 			utils.SendJsonError(w, err, http.StatusBadRequest)
 			return
 		}
 
-		utils.SendJson(w, transcriptionMap, http.StatusOK)
+		utils.SendJson(w, segmentation, http.StatusOK)
 	}
 }
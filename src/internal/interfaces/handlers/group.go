@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/alexkarpovich/lst-api/src/internal/app"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
@@ -15,16 +19,33 @@ import (
 type GroupInteractor interface {
 	CreateGroup(*valueobject.ID, app.Group) (*app.Group, error)
 	UpdateGroup(*valueobject.ID, app.Group) error
+	CloneGroup(*valueobject.ID, *valueobject.ID, string, []valueobject.ID) (*app.Group, error)
 	ListGroups(*valueobject.ID) ([]*app.Group, error)
 	MarkGroupAsDeleted(*valueobject.ID, *valueobject.ID) error
-	CreateNode(*valueobject.ID, app.Node) (*app.Node, error)
+	ListTrash(*valueobject.ID) ([]*app.Group, error)
+	RestoreGroup(*valueobject.ID, *valueobject.ID) error
+	PublishGroup(*valueobject.ID, *valueobject.ID, app.GroupVisibility) error
+	CreateNode(*valueobject.ID, *valueobject.ID, app.Node) (*app.Node, error)
 	ListNodes(*valueobject.ID) ([]*app.FlatNode, error)
+	NodeTree(*valueobject.ID, *valueobject.ID, *valueobject.ID, uint) ([]*app.TreeNode, error)
 	MoveNode(*valueobject.ID, *valueobject.ID, app.FlatNode, []*valueobject.ID) error
-	DeleteNode(*valueobject.ID, *valueobject.ID) error
+	DeleteNode(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	ListDeletedNodes(*valueobject.ID, *valueobject.ID) ([]*app.TrashedNode, error)
+	RestoreNode(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
 	InviteUser(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
 	ConfirmInvitation(*valueobject.ID, string) error
-	DetachMember(*valueobject.ID, *valueobject.ID) error
+	RejectInvitation(*valueobject.ID, string) error
+	LeaveGroup(*valueobject.ID, *valueobject.ID) error
+	DetachMember(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	TransferAdmin(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
 	UpdateMemberRole(*valueobject.ID, *valueobject.ID, app.GroupMember) error
+	CreateJoinLink(*valueobject.ID, *valueobject.ID, app.GroupLink) (*app.GroupLink, error)
+	ListJoinLinks(*valueobject.ID, *valueobject.ID) ([]*app.GroupLink, error)
+	RevokeJoinLink(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	JoinByLink(*valueobject.ID, string) (*app.Group, error)
+	ImportAnki(*valueobject.ID, *valueobject.ID, *valueobject.ID, []byte, app.ImportOptions) (*app.AnkiImportReport, error)
+	BackupGroup(*valueobject.ID, *valueobject.ID) (*app.GroupBackup, []byte, error)
+	RestoreGroupBackup(*valueobject.ID, []byte, string, bool) (*app.RestoreReport, error)
 }
 
 type groupHanlder struct {
@@ -42,16 +63,33 @@ func ConfigureGroupHandler(pi GroupInteractor, r *mux.Router) {
 
 	h.router.HandleFunc("/me/groups", h.CreateGroup()).Methods("POST")
 	h.router.HandleFunc("/me/groups", h.ListGroups()).Methods("GET")
+	h.router.HandleFunc("/me/groups/trash", h.ListTrash()).Methods("GET")
 	h.router.HandleFunc("/me/groups/confirm-invitation/{token}", h.ConfirmInvitation()).Methods("POST")
+	h.router.HandleFunc("/me/groups/reject-invitation/{token}", h.RejectInvitation()).Methods("POST")
+	h.router.HandleFunc("/me/groups/join/{token}", h.JoinByLink()).Methods("POST")
+	h.router.HandleFunc("/me/groups/restore-backup", h.RestoreGroupBackup()).Methods("POST")
 	h.router.HandleFunc("/me/groups/{group_id}", h.UpdateGroup()).Methods("POST")
 	h.router.HandleFunc("/me/groups/{group_id}", h.DeleteGroup()).Methods("DELETE")
+	h.router.HandleFunc("/me/groups/{group_id}/restore", h.RestoreGroup()).Methods("POST")
+	h.router.HandleFunc("/me/groups/{group_id}/clone", h.CloneGroup()).Methods("POST")
+	h.router.HandleFunc("/me/groups/{group_id}/backup", h.BackupGroup()).Methods("GET")
+	h.router.HandleFunc("/me/groups/{group_id}/publish", h.PublishGroup()).Methods("POST")
 	h.router.HandleFunc("/me/groups/{group_id}/nodes", h.CreateNode()).Methods("POST")
 	h.router.HandleFunc("/me/groups/{group_id}/nodes", h.ListNodes()).Methods("GET")
+	h.router.HandleFunc("/me/groups/{group_id}/tree", h.NodeTree()).Methods("GET")
 	h.router.HandleFunc("/me/groups/{group_id}/nodes/{node_id}", h.DeleteNode()).Methods("DELETE")
+	h.router.HandleFunc("/me/groups/{group_id}/trash", h.ListDeletedNodes()).Methods("GET")
+	h.router.HandleFunc("/me/groups/{group_id}/trash/{trash_id}/restore", h.RestoreNode()).Methods("POST")
 	h.router.HandleFunc("/me/groups/{group_id}/move-node", h.MoveNode()).Methods("POST")
+	h.router.HandleFunc("/me/groups/{group_id}/import/anki", h.ImportAnki()).Methods("POST")
 	h.router.HandleFunc("/me/groups/{group_id}/invite-user/{user_id}", h.InviteUser()).Methods("POST")
 	h.router.HandleFunc("/me/groups/{group_id}/detach-member/{member_id}", h.DetachMember()).Methods("POST")
 	h.router.HandleFunc("/me/groups/{group_id}/update-role", h.UpdateMemberRole()).Methods("POST")
+	h.router.HandleFunc("/me/groups/{group_id}/leave", h.LeaveGroup()).Methods("POST")
+	h.router.HandleFunc("/me/groups/{group_id}/transfer-admin", h.TransferAdmin()).Methods("POST")
+	h.router.HandleFunc("/me/groups/{group_id}/links", h.CreateJoinLink()).Methods("POST")
+	h.router.HandleFunc("/me/groups/{group_id}/links", h.ListJoinLinks()).Methods("GET")
+	h.router.HandleFunc("/me/groups/{group_id}/links/{link_id}/revoke", h.RevokeJoinLink()).Methods("POST")
 }
 
 func (i *groupHanlder) CreateGroup() http.HandlerFunc {
@@ -142,6 +180,80 @@ func (i *groupHanlder) UpdateGroup() http.HandlerFunc {
 	}
 }
 
+func (i *groupHanlder) CloneGroup() http.HandlerFunc {
+	type request struct {
+		Name    string           `json:"name"`
+		NodeIds []valueobject.ID `json:"nodeIds"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		if err = json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error group clone context")
+			return
+		}
+
+		group, err := i.groupInteractor.CloneGroup(user.Id, &groupId, s.Name, s.NodeIds)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, group, http.StatusOK)
+	}
+}
+
+func (i *groupHanlder) PublishGroup() http.HandlerFunc {
+	type request struct {
+		Visibility *app.GroupVisibility `json:"visibility"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		if err = json.NewDecoder(r.Body).Decode(&s); err != nil || s.Visibility == nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error group publish context")
+			return
+		}
+
+		if err = i.groupInteractor.PublishGroup(user.Id, &groupId, *s.Visibility); err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
 func (i *groupHanlder) DeleteGroup() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
@@ -169,6 +281,49 @@ func (i *groupHanlder) DeleteGroup() http.HandlerFunc {
 	}
 }
 
+func (i *groupHanlder) RestoreGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error group restore context")
+			return
+		}
+
+		if err = i.groupInteractor.RestoreGroup(user.Id, &groupId); err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *groupHanlder) ListTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error group trash user context")
+			return
+		}
+
+		groups, err := i.groupInteractor.ListTrash(user.Id)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, groups, http.StatusOK)
+	}
+}
+
 func (i *groupHanlder) ListGroups() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		user := utils.LoggedInUser(r)
@@ -189,9 +344,10 @@ func (i *groupHanlder) ListGroups() http.HandlerFunc {
 
 func (i *groupHanlder) CreateNode() http.HandlerFunc {
 	type request struct {
-		Type       app.NodeType `json:"type"`
-		Name       string       `json:"name"`
-		ParentPath string       `json:"parentPath"`
+		Type       app.NodeType    `json:"type"`
+		Name       string          `json:"name"`
+		ParentPath string          `json:"parentPath"`
+		SmartQuery *app.SmartQuery `json:"smartQuery"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -211,15 +367,22 @@ func (i *groupHanlder) CreateNode() http.HandlerFunc {
 			return
 		}
 
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node create context")
+			return
+		}
+
 		inNode := app.Node{
-			Type: s.Type,
-			Name: s.Name,
-			Path: s.ParentPath,
+			Type:       s.Type,
+			Name:       s.Name,
+			Path:       s.ParentPath,
+			SmartQuery: s.SmartQuery,
 		}
 
-		node, err := i.groupInteractor.CreateNode(&groupId, inNode)
+		node, err := i.groupInteractor.CreateNode(user.Id, &groupId, inNode)
 		if err != nil {
-			utils.SendJsonError(w, "Create slice error", http.StatusBadRequest)
+			utils.SendJsonError(w, err, http.StatusBadRequest)
 			return
 		}
 
@@ -247,6 +410,44 @@ func (i *groupHanlder) ListNodes() http.HandlerFunc {
 	}
 }
 
+func (i *groupHanlder) NodeTree() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		queryParams := r.URL.Query()
+		rootId, err := parseOptionalId(queryParams.Get("root"))
+		if err != nil {
+			utils.SendJsonError(w, "Invalid root id", http.StatusBadRequest)
+			return
+		}
+		depth, err := parseOptionalUint(queryParams.Get("depth"))
+		if err != nil {
+			utils.SendJsonError(w, "Invalid depth", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error group tree user context")
+			return
+		}
+
+		tree, err := i.groupInteractor.NodeTree(user.Id, &groupId, rootId, depth)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, tree, http.StatusOK)
+	}
+}
+
 func (i *groupHanlder) MoveNode() http.HandlerFunc {
 	type request struct {
 		NodeId     *valueobject.ID   `json:"nodeId"`
@@ -292,6 +493,64 @@ func (i *groupHanlder) MoveNode() http.HandlerFunc {
 	}
 }
 
+func (i *groupHanlder) ListDeletedNodes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node trash user context")
+			return
+		}
+
+		nodes, err := i.groupInteractor.ListDeletedNodes(user.Id, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, nodes, http.StatusOK)
+	}
+}
+
+func (i *groupHanlder) RestoreNode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		trashIdArg, err := strconv.Atoi(vars["trash_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid trash id", http.StatusBadRequest)
+			return
+		}
+		trashId := valueobject.ID(trashIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node restore user context")
+			return
+		}
+
+		if err = i.groupInteractor.RestoreNode(user.Id, &groupId, &trashId); err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
 func (i *groupHanlder) DeleteNode() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -309,7 +568,13 @@ func (i *groupHanlder) DeleteNode() http.HandlerFunc {
 		}
 		nodeId := valueobject.ID(nodeIdArg)
 
-		err = i.groupInteractor.DeleteNode(&groupId, &nodeId)
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node delete context")
+			return
+		}
+
+		err = i.groupInteractor.DeleteNode(user.Id, &groupId, &nodeId)
 		if err != nil {
 			utils.SendJsonError(w, "Delete node error", http.StatusBadRequest)
 			return
@@ -373,6 +638,90 @@ func (i *groupHanlder) ConfirmInvitation() http.HandlerFunc {
 	}
 }
 
+func (i *groupHanlder) RejectInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		token := vars["token"]
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			utils.SendJsonError(w, "You don't have permissions to reject invitation.", http.StatusBadRequest)
+			return
+		}
+
+		err := i.groupInteractor.RejectInvitation(user.Id, token)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *groupHanlder) LeaveGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Printf("error group leave user context")
+			return
+		}
+
+		err = i.groupInteractor.LeaveGroup(user.Id, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *groupHanlder) TransferAdmin() http.HandlerFunc {
+	type request struct {
+		MemberId *valueobject.ID `json:"memberId"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil || s.MemberId == nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Printf("error group transfer admin user context")
+			return
+		}
+
+		err = i.groupInteractor.TransferAdmin(user.Id, &groupId, s.MemberId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
 func (i *groupHanlder) DetachMember() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -383,7 +732,7 @@ func (i *groupHanlder) DetachMember() http.HandlerFunc {
 		}
 		groupId := valueobject.ID(groupIdArg)
 
-		memberIdArg, err := strconv.Atoi(vars["memberId"])
+		memberIdArg, err := strconv.Atoi(vars["member_id"])
 		if err != nil {
 			utils.SendJsonError(w, "Invalid member id", http.StatusBadRequest)
 			return
@@ -396,7 +745,7 @@ func (i *groupHanlder) DetachMember() http.HandlerFunc {
 			return
 		}
 
-		err = i.groupInteractor.DetachMember(&groupId, &memberId)
+		err = i.groupInteractor.DetachMember(user.Id, &groupId, &memberId)
 		if err != nil {
 			utils.SendJsonError(w, err, http.StatusBadRequest)
 			return
@@ -448,3 +797,257 @@ func (i *groupHanlder) UpdateMemberRole() http.HandlerFunc {
 		utils.SendJson(w, "Success", http.StatusOK)
 	}
 }
+
+func (i *groupHanlder) CreateJoinLink() http.HandlerFunc {
+	type request struct {
+		Role      app.UserRole `json:"role"`
+		MaxUses   uint         `json:"maxUses"`
+		ExpiresAt time.Time    `json:"expiresAt"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Printf("error group link user context")
+			return
+		}
+
+		inLink := app.GroupLink{
+			Role:      s.Role,
+			MaxUses:   s.MaxUses,
+			ExpiresAt: s.ExpiresAt,
+		}
+
+		link, err := i.groupInteractor.CreateJoinLink(user.Id, &groupId, inLink)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, link, http.StatusOK)
+	}
+}
+
+func (i *groupHanlder) ListJoinLinks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Printf("error group link user context")
+			return
+		}
+
+		links, err := i.groupInteractor.ListJoinLinks(user.Id, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, links, http.StatusOK)
+	}
+}
+
+func (i *groupHanlder) RevokeJoinLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		linkIdArg, err := strconv.Atoi(vars["link_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid link id", http.StatusBadRequest)
+			return
+		}
+		linkId := valueobject.ID(linkIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Printf("error group link user context")
+			return
+		}
+
+		err = i.groupInteractor.RevokeJoinLink(user.Id, &groupId, &linkId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *groupHanlder) JoinByLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		token := vars["token"]
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			utils.SendJsonError(w, "You need to be logged in to join a group.", http.StatusBadRequest)
+			return
+		}
+
+		group, err := i.groupInteractor.JoinByLink(user.Id, token)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, group, http.StatusOK)
+	}
+}
+
+const maxAnkiFileSize = 50 << 20
+
+func (i *groupHanlder) ImportAnki() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		queryParams := r.URL.Query()
+		opts := app.ImportOptions{
+			Columns:        importColumns(queryParams.Get("columns")),
+			ValueSeparator: ";",
+		}
+
+		if separator, ok := queryParams["separator"]; ok {
+			opts.ValueSeparator = separator[0]
+		}
+
+		folderId, err := parseOptionalId(queryParams.Get("folder_id"))
+		if err != nil {
+			utils.SendJsonError(w, "Invalid folder id", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error group import anki context")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxAnkiFileSize)
+		reader, err := uploadedFile(r)
+		if err != nil {
+			utils.SendJsonError(w, "Invalid import file", http.StatusBadRequest)
+			return
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			utils.SendJsonError(w, "Invalid import file", http.StatusBadRequest)
+			return
+		}
+
+		report, err := i.groupInteractor.ImportAnki(user.Id, &groupId, folderId, data, opts)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, report, http.StatusOK)
+	}
+}
+
+const maxBackupFileSize = 200 << 20
+
+func (i *groupHanlder) BackupGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error group backup context")
+			return
+		}
+
+		backup, data, err := i.groupInteractor.BackupGroup(user.Id, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		name := fmt.Sprintf("%s-backup-%s.zip", backup.Group.Name, backup.CreatedAt.Format("2006-01-02"))
+		filename := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", filename)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(http.StatusOK)
+
+		if _, err = w.Write(data); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (i *groupHanlder) RestoreGroupBackup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queryParams := r.URL.Query()
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error group restore backup context")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBackupFileSize)
+		reader, err := uploadedFile(r)
+		if err != nil {
+			utils.SendJsonError(w, "Invalid backup file", http.StatusBadRequest)
+			return
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			utils.SendJsonError(w, "Invalid backup file", http.StatusBadRequest)
+			return
+		}
+
+		report, err := i.groupInteractor.RestoreGroupBackup(user.Id, data, queryParams.Get("name"), queryParams.Get("invite_members") == "true")
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, report, http.StatusOK)
+	}
+}
@@ -3,9 +3,12 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"log"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/alexkarpovich/lst-api/src/internal/app"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
@@ -14,16 +17,31 @@ import (
 )
 
 type NodeInteractor interface {
-	Get(*valueobject.ID) (*app.Node, error)
-	View([]valueobject.ID) (*app.NodeView, error)
+	Get(*valueobject.ID, *valueobject.ID) (*app.Node, error)
+	View(*valueobject.ID, []valueobject.ID, app.ExpressionOrder) (*app.NodeView, error)
 	Update(*valueobject.ID, app.FlatNode) error
-	AttachExpression(*valueobject.ID, app.Expression) (*app.Expression, error)
-	DetachExpression(*valueobject.ID, *valueobject.ID) error
+	AttachExpression(*valueobject.ID, *valueobject.ID, app.Expression) (*app.Expression, error)
+	DetachExpression(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	Suggestions(*valueobject.ID, *valueobject.ID, *valueobject.ID) ([]*app.DictionarySuggestion, error)
+	AcceptSuggestion(*valueobject.ID, *valueobject.ID, *valueobject.ID, *valueobject.ID, []string, bool) (*app.ImportRowReport, error)
 	AvailableTranslations(*valueobject.ID, *valueobject.ID) ([]*app.Translation, error)
-	AttachTranslation(*valueobject.ID, *valueobject.ID, app.Translation) (*app.Translation, error)
-	DetachTranslation(*valueobject.ID, *valueobject.ID) error
-	AttachText(*valueobject.ID, app.Text) (*app.Text, error)
+	AttachTranslation(*valueobject.ID, *valueobject.ID, *valueobject.ID, app.Translation) (*app.Translation, error)
+	DetachTranslation(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	AttachExpressions(*valueobject.ID, *valueobject.ID, []*app.Expression, app.BulkMode) (*app.BulkReport, error)
+	DetachExpressions(*valueobject.ID, *valueobject.ID, []valueobject.ID, []valueobject.ID, app.BulkMode) (*app.BulkReport, error)
+	ReorderExpressions(*valueobject.ID, *valueobject.ID, app.ExpressionOrder, []valueobject.ID) error
+	UpdateSmartQuery(*valueobject.ID, *valueobject.ID, *app.SmartQuery) (*app.Node, error)
+	AttachText(*valueobject.ID, *valueobject.ID, app.Text) (*app.Text, error)
 	DetachText(*valueobject.ID, *valueobject.ID) error
+	SegmentText(*valueobject.ID, *valueobject.ID) (*app.Segmentation, error)
+	CopyNode(*valueobject.ID, *valueobject.ID, *valueobject.ID, *valueobject.ID) (*app.Node, error)
+	MergeNodes(*valueobject.ID, *valueobject.ID, []valueobject.ID, bool) error
+	Import(*valueobject.ID, *valueobject.ID, io.Reader, app.ImportOptions) (*app.ImportReport, error)
+	ExportAnki(*valueobject.ID, *valueobject.ID) (*app.Node, []byte, error)
+	Export(*valueobject.ID, []valueobject.ID, app.ExportFormat, app.ExportLayout) ([]byte, error)
+	History(*valueobject.ID, *valueobject.ID) ([]*app.NodeRevision, error)
+	DiffRevisions(*valueobject.ID, *valueobject.ID, uint, uint) (*app.NodeDiff, error)
+	RevertNode(*valueobject.ID, *valueobject.ID, uint) (*app.NodeRevision, error)
 }
 
 type nodeHandler struct {
@@ -42,6 +60,8 @@ func ConfigureNodeHandler(fi NodeInteractor, r *mux.Router) {
 	h.router.HandleFunc("/me/nodes", h.View()).
 		Queries("ids", "{[0-9]+}").
 		Methods("GET")
+	h.router.HandleFunc("/me/nodes/merge", h.MergeNodes()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/export", h.Export()).Methods("GET")
 	h.router.HandleFunc("/me/nodes/{node_id}", h.Get()).Methods("GET")
 	h.router.HandleFunc("/me/nodes/{node_id}", h.Update()).Methods("POST")
 	h.router.HandleFunc("/me/nodes/{node_id}/translations", h.AvailableTranslations()).
@@ -49,10 +69,25 @@ func ConfigureNodeHandler(fi NodeInteractor, r *mux.Router) {
 		Methods("GET")
 	h.router.HandleFunc("/me/nodes/{node_id}/attach-expression", h.AttachExpression()).Methods("POST")
 	h.router.HandleFunc("/me/nodes/{node_id}/detach-expression/{expression_id}", h.DetachExpression()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/expressions/{expression_id}/suggestions", h.Suggestions()).Methods("GET")
+	h.router.HandleFunc("/me/nodes/{node_id}/expressions/{expression_id}/suggestions/{entry_id}/accept", h.AcceptSuggestion()).Methods("POST")
 	h.router.HandleFunc("/me/nodes/{node_id}/attach-translation", h.AttachTranslation()).Methods("POST")
 	h.router.HandleFunc("/me/nodes/{node_id}/detach-translation/{translation_id}", h.DetachTranslation()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/attach-expressions", h.AttachExpressions()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/detach-expressions", h.DetachExpressions()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/reorder-expressions", h.ReorderExpressions()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/smart-query", h.UpdateSmartQuery()).Methods("POST")
 	h.router.HandleFunc("/me/nodes/{node_id}/attach-text", h.AttachText()).Methods("POST")
 	h.router.HandleFunc("/me/nodes/{node_id}/detach-text", h.DetachText()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/text/tokens", h.SegmentText()).Methods("GET")
+	h.router.HandleFunc("/me/nodes/{node_id}/copy", h.CopyNode()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/import", h.Import()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/export/anki", h.ExportAnki()).Methods("GET")
+	h.router.HandleFunc("/me/nodes/{node_id}/history", h.History()).Methods("GET")
+	h.router.HandleFunc("/me/nodes/{node_id}/history/diff", h.DiffRevisions()).
+		Queries("from", "{[0-9]+}", "to", "{[0-9]+}").
+		Methods("GET")
+	h.router.HandleFunc("/me/nodes/{node_id}/history/{number}/revert", h.RevertNode()).Methods("POST")
 }
 
 func (i *nodeHandler) View() http.HandlerFunc {
@@ -76,7 +111,12 @@ func (i *nodeHandler) View() http.HandlerFunc {
 			return
 		}
 
-		slice, err := i.NodeInteractor.View(ids)
+		var userId *valueobject.ID
+		if user := utils.LoggedInUser(r); user != nil {
+			userId = user.Id
+		}
+
+		slice, err := i.NodeInteractor.View(userId, ids, app.ExpressionOrder(queryParams.Get("order")))
 		if err != nil {
 			utils.SendJsonError(w, err, http.StatusBadRequest)
 			return
@@ -98,7 +138,12 @@ func (i *nodeHandler) Get() http.HandlerFunc {
 		}
 		nodeId := valueobject.ID(nodeIdArg)
 
-		slice, err := i.NodeInteractor.Get(&nodeId)
+		var userId *valueobject.ID
+		if user := utils.LoggedInUser(r); user != nil {
+			userId = user.Id
+		}
+
+		slice, err := i.NodeInteractor.Get(userId, &nodeId)
 		if err != nil {
 			utils.SendJsonError(w, err, http.StatusBadRequest)
 			return
@@ -177,12 +222,19 @@ func (i *nodeHandler) AttachExpression() http.HandlerFunc {
 			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
 			return
 		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error attach expression context")
+			return
+		}
+
 		inExpr := app.Expression{
 			Id:    s.Id,
 			Value: s.Value,
 		}
 
-		expression, err := i.NodeInteractor.AttachExpression(&nodeId, inExpr)
+		expression, err := i.NodeInteractor.AttachExpression(user.Id, &nodeId, inExpr)
 		if err != nil {
 			utils.SendJsonError(w, "Attach expression error", http.StatusBadRequest)
 			return
@@ -210,7 +262,13 @@ func (i *nodeHandler) DetachExpression() http.HandlerFunc {
 		nodeId := valueobject.ID(nodeIdArg)
 		expressionId := valueobject.ID(expressionIdArg)
 
-		err = i.NodeInteractor.DetachExpression(&nodeId, &expressionId)
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error detach expression context")
+			return
+		}
+
+		err = i.NodeInteractor.DetachExpression(user.Id, &nodeId, &expressionId)
 		if err != nil {
 			utils.SendJsonError(w, "Detach expression error", http.StatusBadRequest)
 			return
@@ -278,13 +336,20 @@ func (i *nodeHandler) AttachTranslation() http.HandlerFunc {
 			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
 			return
 		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error attach translation context")
+			return
+		}
+
 		inTranslation := app.Translation{
 			Id:      s.Translation.Id,
 			Comment: s.Translation.Comment,
 			Value:   s.Translation.Value,
 		}
 
-		translation, err := i.NodeInteractor.AttachTranslation(&nodeId, s.ExpressionId, inTranslation)
+		translation, err := i.NodeInteractor.AttachTranslation(user.Id, &nodeId, s.ExpressionId, inTranslation)
 		if err != nil {
 			utils.SendJsonError(w, "Attach translation error", http.StatusBadRequest)
 			return
@@ -312,7 +377,13 @@ func (i *nodeHandler) DetachTranslation() http.HandlerFunc {
 		nodeId := valueobject.ID(nodeIdArg)
 		translationId := valueobject.ID(translationIdArg)
 
-		err = i.NodeInteractor.DetachTranslation(&nodeId, &translationId)
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error detach translation context")
+			return
+		}
+
+		err = i.NodeInteractor.DetachTranslation(user.Id, &nodeId, &translationId)
 		if err != nil {
 			utils.SendJsonError(w, "Detach expression error", http.StatusBadRequest)
 			return
@@ -357,7 +428,7 @@ func (i *nodeHandler) AttachText() http.HandlerFunc {
 			Content:  s.Content,
 		}
 
-		text, err := i.NodeInteractor.AttachText(&nodeId, inText)
+		text, err := i.NodeInteractor.AttachText(user.Id, &nodeId, inText)
 		if err != nil {
 			utils.SendJsonError(w, "Attach text error", http.StatusBadRequest)
 			return
@@ -394,3 +465,619 @@ func (i *nodeHandler) DetachText() http.HandlerFunc {
 		utils.SendJson(w, "Success", http.StatusOK)
 	}
 }
+
+func (i *nodeHandler) CopyNode() http.HandlerFunc {
+	type request struct {
+		GroupId  *valueobject.ID `json:"groupId"`
+		FolderId *valueobject.ID `json:"folderId"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		if err = json.NewDecoder(r.Body).Decode(&s); err != nil || s.GroupId == nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node copy context")
+			return
+		}
+
+		node, err := i.NodeInteractor.CopyNode(user.Id, &nodeId, s.GroupId, s.FolderId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, node, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) MergeNodes() http.HandlerFunc {
+	type request struct {
+		TargetId      *valueobject.ID  `json:"targetId"`
+		SourceIds     []valueobject.ID `json:"sourceIds"`
+		RemoveSources bool             `json:"removeSources"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node merge context")
+			return
+		}
+
+		err := i.NodeInteractor.MergeNodes(user.Id, s.TargetId, s.SourceIds, s.RemoveSources)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+const maxImportFileSize = 5 << 20
+
+func importColumns(columns string) []app.ImportColumn {
+	result := []app.ImportColumn{}
+	if columns == "" {
+		return result
+	}
+
+	for _, column := range strings.Split(columns, ",") {
+		result = append(result, app.ImportColumn(strings.TrimSpace(column)))
+	}
+
+	return result
+}
+
+// uploadedFile returns the "file" field of multipart form or the raw request body.
+func uploadedFile(r *http.Request) (io.ReadCloser, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		return file, err
+	}
+
+	return r.Body, nil
+}
+
+func (i *nodeHandler) Import() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		queryParams := r.URL.Query()
+		opts := app.ImportOptions{
+			Delimiter:      ',',
+			ValueSeparator: ";",
+			HasHeader:      queryParams.Get("header") == "true",
+			DryRun:         queryParams.Get("dry_run") == "true",
+		}
+
+		switch queryParams.Get("format") {
+		case "", "csv":
+		case "tsv":
+			opts.Delimiter = '\t'
+		default:
+			utils.SendJsonError(w, "Invalid format, csv or tsv expected", http.StatusBadRequest)
+			return
+		}
+
+		if separator, ok := queryParams["separator"]; ok {
+			opts.ValueSeparator = separator[0]
+		}
+
+		opts.Columns = importColumns(queryParams.Get("columns"))
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node import context")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+		reader, err := uploadedFile(r)
+		if err != nil {
+			utils.SendJsonError(w, "Invalid import file", http.StatusBadRequest)
+			return
+		}
+		defer reader.Close()
+
+		report, err := i.NodeInteractor.Import(user.Id, &nodeId, reader, opts)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, report, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) ExportAnki() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node export anki context")
+			return
+		}
+
+		node, data, err := i.NodeInteractor.ExportAnki(user.Id, &nodeId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		filename := mime.FormatMediaType("attachment", map[string]string{"filename": node.Name + ".apkg"})
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", filename)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(http.StatusOK)
+
+		if _, err = w.Write(data); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (i *nodeHandler) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queryParams := r.URL.Query()
+		ids := []valueobject.ID{}
+
+		for _, idsArg := range queryParams["ids"] {
+			for _, idStr := range strings.Split(idsArg, ",") {
+				id, err := strconv.Atoi(strings.TrimSpace(idStr))
+				if err != nil {
+					utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+					return
+				}
+				ids = append(ids, valueobject.ID(id))
+			}
+		}
+
+		format := app.ExportFormat(queryParams.Get("format"))
+		if format == "" {
+			format = app.ExportJson
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node export context")
+			return
+		}
+
+		data, err := i.NodeInteractor.Export(user.Id, ids, format, app.ExportLayout(queryParams.Get("layout")))
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		if format != app.ExportJson {
+			filename := mime.FormatMediaType("attachment", map[string]string{"filename": "vocabulary." + string(format)})
+			w.Header().Set("Content-Disposition", filename)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(http.StatusOK)
+
+		if _, err = w.Write(data); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (i *nodeHandler) AttachExpressions() http.HandlerFunc {
+	type translation struct {
+		Id      *valueobject.ID `json:"id"`
+		Value   string          `json:"value"`
+		Comment string          `json:"comment"`
+	}
+	type expression struct {
+		Id           *valueobject.ID `json:"id"`
+		Value        string          `json:"value"`
+		Translations []translation   `json:"translations"`
+	}
+	type request struct {
+		Mode        app.BulkMode `json:"mode"`
+		Expressions []expression `json:"expressions"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error attach expressions context")
+			return
+		}
+
+		expressions := []*app.Expression{}
+		for _, inExpr := range s.Expressions {
+			expr := &app.Expression{
+				Id:           inExpr.Id,
+				Value:        inExpr.Value,
+				Translations: []*app.Translation{},
+			}
+			for _, inTranslation := range inExpr.Translations {
+				expr.Translations = append(expr.Translations, &app.Translation{
+					Id:      inTranslation.Id,
+					Value:   inTranslation.Value,
+					Comment: inTranslation.Comment,
+				})
+			}
+			expressions = append(expressions, expr)
+		}
+
+		report, err := i.NodeInteractor.AttachExpressions(user.Id, &nodeId, expressions, s.Mode)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, report, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) DetachExpressions() http.HandlerFunc {
+	type request struct {
+		Mode           app.BulkMode     `json:"mode"`
+		ExpressionIds  []valueobject.ID `json:"expressionIds"`
+		TranslationIds []valueobject.ID `json:"translationIds"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error detach expressions context")
+			return
+		}
+
+		report, err := i.NodeInteractor.DetachExpressions(user.Id, &nodeId, s.ExpressionIds, s.TranslationIds, s.Mode)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, report, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) ReorderExpressions() http.HandlerFunc {
+	type request struct {
+		Order         app.ExpressionOrder `json:"order"`
+		ExpressionIds []valueobject.ID    `json:"expressionIds"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error reorder expressions context")
+			return
+		}
+
+		if s.Order == "" {
+			s.Order = app.ExpressionOrderManual
+		}
+
+		err = i.NodeInteractor.ReorderExpressions(user.Id, &nodeId, s.Order, s.ExpressionIds)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) UpdateSmartQuery() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s app.SmartQuery
+
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error update smart query context")
+			return
+		}
+
+		node, err := i.NodeInteractor.UpdateSmartQuery(user.Id, &nodeId, &s)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, node, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) SegmentText() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error segment text context")
+			return
+		}
+
+		segmentation, err := i.NodeInteractor.SegmentText(user.Id, &nodeId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, segmentation, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) History() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node history context")
+			return
+		}
+
+		revisions, err := i.NodeInteractor.History(user.Id, &nodeId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, revisions, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) DiffRevisions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		queryParams := r.URL.Query()
+		from, err := strconv.ParseUint(queryParams.Get("from"), 10, 32)
+		if err != nil {
+			utils.SendJsonError(w, "Invalid from revision", http.StatusBadRequest)
+			return
+		}
+		to, err := strconv.ParseUint(queryParams.Get("to"), 10, 32)
+		if err != nil {
+			utils.SendJsonError(w, "Invalid to revision", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node history diff context")
+			return
+		}
+
+		diff, err := i.NodeInteractor.DiffRevisions(user.Id, &nodeId, uint(from), uint(to))
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, diff, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) RevertNode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		number, err := strconv.ParseUint(vars["number"], 10, 32)
+		if err != nil {
+			utils.SendJsonError(w, "Invalid revision number", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node revert context")
+			return
+		}
+
+		revision, err := i.NodeInteractor.RevertNode(user.Id, &nodeId, uint(number))
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, revision, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) Suggestions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid folder id", http.StatusBadRequest)
+			return
+		}
+		expressionIdArg, err := strconv.Atoi(vars["expression_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid expression id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+		expressionId := valueobject.ID(expressionIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error suggestions context")
+			return
+		}
+
+		suggestions, err := i.NodeInteractor.Suggestions(user.Id, &nodeId, &expressionId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, suggestions, http.StatusOK)
+	}
+}
+
+func (i *nodeHandler) AcceptSuggestion() http.HandlerFunc {
+	type acceptSuggestionSchema struct {
+		Translations      []string `json:"translations"`
+		SkipTranscription bool     `json:"skipTranscription"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid folder id", http.StatusBadRequest)
+			return
+		}
+		expressionIdArg, err := strconv.Atoi(vars["expression_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid expression id", http.StatusBadRequest)
+			return
+		}
+		entryIdArg, err := strconv.Atoi(vars["entry_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid dictionary entry id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+		expressionId := valueobject.ID(expressionIdArg)
+		entryId := valueobject.ID(entryIdArg)
+
+		s := &acceptSuggestionSchema{}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(s); err != nil {
+				utils.SendJsonError(w, err, http.StatusBadRequest)
+				return
+			}
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error accept suggestion context")
+			return
+		}
+
+		report, err := i.NodeInteractor.AcceptSuggestion(user.Id, &nodeId, &expressionId, &entryId, s.Translations, s.SkipTranscription)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, report, http.StatusOK)
+	}
+}
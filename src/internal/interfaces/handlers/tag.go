@@ -0,0 +1,514 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+type TagInteractor interface {
+	CreateTag(*valueobject.ID, *valueobject.ID, string) (*app.Tag, error)
+	ListTags(*valueobject.ID, *valueobject.ID) ([]*app.Tag, error)
+	RenameTag(*valueobject.ID, *valueobject.ID, string) error
+	DeleteTag(*valueobject.ID, *valueobject.ID) error
+	NodeTags(*valueobject.ID, *valueobject.ID) (*app.NodeTags, error)
+	TagNode(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	UntagNode(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	TagExpression(*valueobject.ID, *valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	UntagExpression(*valueobject.ID, *valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	CreateFilter(*valueobject.ID, app.SavedFilter) (*app.SavedFilter, error)
+	ListFilters(*valueobject.ID, *valueobject.ID) ([]*app.SavedFilter, error)
+	UpdateFilter(*valueobject.ID, app.SavedFilter) error
+	DeleteFilter(*valueobject.ID, *valueobject.ID) error
+	ViewFilter(*valueobject.ID, *valueobject.ID) ([]*app.Expression, error)
+}
+
+type tagHandler struct {
+	BaseHanlder
+	TagInteractor TagInteractor
+}
+
+func ConfigureTagHandler(ti TagInteractor, r *mux.Router) {
+	h := &tagHandler{
+		BaseHanlder: BaseHanlder{
+			router: r,
+		},
+		TagInteractor: ti,
+	}
+
+	h.router.HandleFunc("/me/groups/{group_id}/tags", h.ListTags()).Methods("GET")
+	h.router.HandleFunc("/me/groups/{group_id}/tags", h.CreateTag()).Methods("POST")
+	h.router.HandleFunc("/me/groups/{group_id}/filters", h.ListFilters()).Methods("GET")
+	h.router.HandleFunc("/me/groups/{group_id}/filters", h.CreateFilter()).Methods("POST")
+	h.router.HandleFunc("/me/tags/{tag_id}", h.RenameTag()).Methods("POST")
+	h.router.HandleFunc("/me/tags/{tag_id}", h.DeleteTag()).Methods("DELETE")
+	h.router.HandleFunc("/me/nodes/{node_id}/tags", h.NodeTags()).Methods("GET")
+	h.router.HandleFunc("/me/nodes/{node_id}/tags/{tag_id}", h.TagNode()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/tags/{tag_id}", h.UntagNode()).Methods("DELETE")
+	h.router.HandleFunc("/me/nodes/{node_id}/expressions/{expression_id}/tags/{tag_id}", h.TagExpression()).Methods("POST")
+	h.router.HandleFunc("/me/nodes/{node_id}/expressions/{expression_id}/tags/{tag_id}", h.UntagExpression()).Methods("DELETE")
+	h.router.HandleFunc("/me/filters/{filter_id}", h.UpdateFilter()).Methods("POST")
+	h.router.HandleFunc("/me/filters/{filter_id}", h.DeleteFilter()).Methods("DELETE")
+	h.router.HandleFunc("/me/filters/{filter_id}/view", h.ViewFilter()).Methods("GET")
+}
+
+func (i *tagHandler) ListTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error list tags context")
+			return
+		}
+
+		tags, err := i.TagInteractor.ListTags(user.Id, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, tags, http.StatusOK)
+	}
+}
+
+func (i *tagHandler) CreateTag() http.HandlerFunc {
+	type request struct {
+		Name string `json:"name"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error create tag context")
+			return
+		}
+
+		tag, err := i.TagInteractor.CreateTag(user.Id, &groupId, s.Name)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, tag, http.StatusOK)
+	}
+}
+
+func (i *tagHandler) RenameTag() http.HandlerFunc {
+	type request struct {
+		Name string `json:"name"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s request
+
+		vars := mux.Vars(r)
+		tagIdArg, err := strconv.Atoi(vars["tag_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid tag id", http.StatusBadRequest)
+			return
+		}
+		tagId := valueobject.ID(tagIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error rename tag context")
+			return
+		}
+
+		err = i.TagInteractor.RenameTag(user.Id, &tagId, s.Name)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *tagHandler) DeleteTag() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		tagIdArg, err := strconv.Atoi(vars["tag_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid tag id", http.StatusBadRequest)
+			return
+		}
+		tagId := valueobject.ID(tagIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error delete tag context")
+			return
+		}
+
+		err = i.TagInteractor.DeleteTag(user.Id, &tagId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *tagHandler) NodeTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error node tags context")
+			return
+		}
+
+		tags, err := i.TagInteractor.NodeTags(user.Id, &nodeId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, tags, http.StatusOK)
+	}
+}
+
+func (i *tagHandler) TagNode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+		tagIdArg, err := strconv.Atoi(vars["tag_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid tag id", http.StatusBadRequest)
+			return
+		}
+		tagId := valueobject.ID(tagIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error tag node context")
+			return
+		}
+
+		err = i.TagInteractor.TagNode(user.Id, &nodeId, &tagId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *tagHandler) UntagNode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+		tagIdArg, err := strconv.Atoi(vars["tag_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid tag id", http.StatusBadRequest)
+			return
+		}
+		tagId := valueobject.ID(tagIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error untag node context")
+			return
+		}
+
+		err = i.TagInteractor.UntagNode(user.Id, &nodeId, &tagId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *tagHandler) TagExpression() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+		expressionIdArg, err := strconv.Atoi(vars["expression_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid expression id", http.StatusBadRequest)
+			return
+		}
+		expressionId := valueobject.ID(expressionIdArg)
+		tagIdArg, err := strconv.Atoi(vars["tag_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid tag id", http.StatusBadRequest)
+			return
+		}
+		tagId := valueobject.ID(tagIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error tag expression context")
+			return
+		}
+
+		err = i.TagInteractor.TagExpression(user.Id, &nodeId, &expressionId, &tagId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *tagHandler) UntagExpression() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nodeIdArg, err := strconv.Atoi(vars["node_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+		expressionIdArg, err := strconv.Atoi(vars["expression_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid expression id", http.StatusBadRequest)
+			return
+		}
+		expressionId := valueobject.ID(expressionIdArg)
+		tagIdArg, err := strconv.Atoi(vars["tag_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid tag id", http.StatusBadRequest)
+			return
+		}
+		tagId := valueobject.ID(tagIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error untag expression context")
+			return
+		}
+
+		err = i.TagInteractor.UntagExpression(user.Id, &nodeId, &expressionId, &tagId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+type filterRequest struct {
+	Name    string           `json:"name"`
+	TagIds  []valueobject.ID `json:"tagIds"`
+	Match   app.TagMatch     `json:"match"`
+	NodeIds []valueobject.ID `json:"nodeIds"`
+}
+
+func (i *tagHandler) ListFilters() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error list filters context")
+			return
+		}
+
+		filters, err := i.TagInteractor.ListFilters(user.Id, &groupId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, filters, http.StatusOK)
+	}
+}
+
+func (i *tagHandler) CreateFilter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s filterRequest
+
+		vars := mux.Vars(r)
+		groupIdArg, err := strconv.Atoi(vars["group_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid group id", http.StatusBadRequest)
+			return
+		}
+		groupId := valueobject.ID(groupIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error create filter context")
+			return
+		}
+
+		filter, err := i.TagInteractor.CreateFilter(user.Id, app.SavedFilter{
+			GroupId: &groupId,
+			Name:    s.Name,
+			TagIds:  s.TagIds,
+			Match:   s.Match,
+			NodeIds: s.NodeIds,
+		})
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, filter, http.StatusOK)
+	}
+}
+
+func (i *tagHandler) UpdateFilter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s filterRequest
+
+		vars := mux.Vars(r)
+		filterIdArg, err := strconv.Atoi(vars["filter_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid filter id", http.StatusBadRequest)
+			return
+		}
+		filterId := valueobject.ID(filterIdArg)
+
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			utils.SendJsonError(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error update filter context")
+			return
+		}
+
+		err = i.TagInteractor.UpdateFilter(user.Id, app.SavedFilter{
+			Id:      &filterId,
+			Name:    s.Name,
+			TagIds:  s.TagIds,
+			Match:   s.Match,
+			NodeIds: s.NodeIds,
+		})
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *tagHandler) DeleteFilter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		filterIdArg, err := strconv.Atoi(vars["filter_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid filter id", http.StatusBadRequest)
+			return
+		}
+		filterId := valueobject.ID(filterIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error delete filter context")
+			return
+		}
+
+		err = i.TagInteractor.DeleteFilter(user.Id, &filterId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
+
+func (i *tagHandler) ViewFilter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		filterIdArg, err := strconv.Atoi(vars["filter_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid filter id", http.StatusBadRequest)
+			return
+		}
+		filterId := valueobject.ID(filterIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error view filter context")
+			return
+		}
+
+		expressions, err := i.TagInteractor.ViewFilter(user.Id, &filterId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, expressions, http.StatusOK)
+	}
+}
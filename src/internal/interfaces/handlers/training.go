@@ -21,6 +21,7 @@ type TrainingInteractor interface {
 	GetItem(*valueobject.ID, *valueobject.ID) (*app.TrainingItem, error)
 	ItemAnswers(*valueobject.ID, *valueobject.ID) ([]*app.TrainingAnswer, error)
 	MarkItemAsComplete(*valueobject.ID, *valueobject.ID) error
+	MarkItemAsFailed(*valueobject.ID, *valueobject.ID) error
 }
 
 type trainingHandler struct {
@@ -44,6 +45,7 @@ func ConfigureTrainingHandler(ti TrainingInteractor, r *mux.Router) {
 	h.router.HandleFunc("/me/training-items/{item_id}", h.GetItem()).Methods("GET")
 	h.router.HandleFunc("/me/training-items/{item_id}/answers", h.ItemAnswers()).Methods("GET")
 	h.router.HandleFunc("/me/training-items/{item_id}/complete", h.Complete()).Methods("POST")
+	h.router.HandleFunc("/me/training-items/{item_id}/fail", h.Fail()).Methods("POST")
 }
 
 func (i *trainingHandler) Create() http.HandlerFunc {
@@ -51,6 +53,7 @@ func (i *trainingHandler) Create() http.HandlerFunc {
 		Type                app.TrainingType `json:"type"`
 		TranscriptionTypeId *valueobject.ID  `json:"transcriptionTypeId"`
 		Slices              []valueobject.ID `json:"slices"`
+		Filters             []valueobject.ID `json:"filters"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -72,6 +75,7 @@ func (i *trainingHandler) Create() http.HandlerFunc {
 			Type:                s.Type,
 			TranscriptionTypeId: s.TranscriptionTypeId,
 			Slices:              s.Slices,
+			Filters:             s.Filters,
 		}
 
 		training, err := i.trainingInteractor.GetOrCreate(inTraining)
@@ -257,3 +261,29 @@ func (i *trainingHandler) Complete() http.HandlerFunc {
 		utils.SendJson(w, "Success", http.StatusOK)
 	}
 }
+
+func (i *trainingHandler) Fail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		itemIdArg, err := strconv.Atoi(vars["item_id"])
+		if err != nil {
+			utils.SendJsonError(w, "Invalid training id", http.StatusBadRequest)
+			return
+		}
+		itemId := valueobject.ID(itemIdArg)
+
+		user := utils.LoggedInUser(r)
+		if user == nil {
+			log.Println("error user context")
+			return
+		}
+
+		err = i.trainingInteractor.MarkItemAsFailed(user.Id, &itemId)
+		if err != nil {
+			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		utils.SendJson(w, "Success", http.StatusOK)
+	}
+}
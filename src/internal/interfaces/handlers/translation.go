@@ -11,8 +11,8 @@ import (
 )
 
 type TranslationInteractor interface {
-	AttachTranscription(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
-	DetachTranscription(*valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	AttachTranscription(*valueobject.ID, *valueobject.ID, *valueobject.ID, *valueobject.ID) error
+	DetachTranscription(*valueobject.ID, *valueobject.ID, *valueobject.ID, *valueobject.ID) error
 }
 
 type translationHanlder struct {
@@ -28,8 +28,14 @@ func ConfigureTranslationHandler(ti TranslationInteractor, r *mux.Router) {
 		translationInteractor: ti,
 	}
 
-	h.router.HandleFunc("/translations/{translation_id}/transcriptions/{transcription_id}", h.AttachTranscription()).Methods("POST")
-	h.router.HandleFunc("/translations/{translation_id}/transcriptions/{transcription_id}", h.DetachTranscription()).Methods("DELETE")
+	h.router.
+		HandleFunc("/translations/{translation_id}/transcriptions/{transcription_id}", h.AttachTranscription()).
+		Queries("node", "{\\d+}").
+		Methods("POST")
+	h.router.
+		HandleFunc("/translations/{translation_id}/transcriptions/{transcription_id}", h.DetachTranscription()).
+		Queries("node", "{\\d+}").
+		Methods("DELETE")
 	// h.router.HandleFunc("/me/group/{groupId}/slice", h.ListSlices()).Methods("GET")
 }
 
@@ -50,15 +56,23 @@ func (i *translationHanlder) AttachTranscription() http.HandlerFunc {
 		}
 		transcriptionId := valueobject.ID(transcriptionIdArg)
 
+		nodeIdArg, err := strconv.Atoi(r.FormValue("node"))
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
 		user := utils.LoggedInUser(r)
 		if user == nil {
 			log.Println("error user context")
 			return
 		}
 
-		err = i.translationInteractor.AttachTranscription(user.Id, &translationId, &transcriptionId)
+		err = i.translationInteractor.AttachTranscription(user.Id, &nodeId, &translationId, &transcriptionId)
 		if err != nil {
 			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
 		}
 
 		utils.SendJson(w, "Success", http.StatusOK)
@@ -82,15 +96,23 @@ func (i *translationHanlder) DetachTranscription() http.HandlerFunc {
 		}
 		transcriptionId := valueobject.ID(transcriptionIdArg)
 
+		nodeIdArg, err := strconv.Atoi(r.FormValue("node"))
+		if err != nil {
+			utils.SendJsonError(w, "Invalid node id", http.StatusBadRequest)
+			return
+		}
+		nodeId := valueobject.ID(nodeIdArg)
+
 		user := utils.LoggedInUser(r)
 		if user == nil {
 			log.Println("error user context")
 			return
 		}
 
-		err = i.translationInteractor.DetachTranscription(user.Id, &translationId, &transcriptionId)
+		err = i.translationInteractor.DetachTranscription(user.Id, &nodeId, &translationId, &transcriptionId)
 		if err != nil {
 			utils.SendJsonError(w, err, http.StatusBadRequest)
+			return
 		}
 
 		utils.SendJson(w, "Success", http.StatusOK)
@@ -23,24 +23,33 @@ func configureRouter(repos *repos.Repos, services *services.Services) http.Handl
 	userInterector := usecases.NewUserInteractor(repos.User)
 	app_handlers.ConfigureUserHandler(userInterector, baseRouter)
 
-	groupInterector := usecases.NewGroupInteractor(repos.Group, repos.Node, repos.User, services.Email)
+	groupInterector := usecases.NewGroupInteractor(repos.Group, repos.Node, repos.User, repos.Activity, services.Email, services.Anki, services.Backup)
 	app_handlers.ConfigureGroupHandler(groupInterector, baseRouter)
 
-	nodeInterector := usecases.NewNodeInteractor(repos.Node, repos.Group, repos.Expression)
+	nodeInterector := usecases.NewNodeInteractor(repos.Node, repos.Group, repos.Expression, repos.Dictionary, repos.Activity, services.Anki, services.Export, services.Transcriber, services.Segmenter)
 	app_handlers.ConfigureNodeHandler(nodeInterector, baseRouter)
 
-	expressionInterector := usecases.NewExpressionInteractor(repos.Expression)
+	expressionInterector := usecases.NewExpressionInteractor(repos.Expression, repos.ExpressionDetail, repos.Group, repos.Activity, services.Transcriber, services.Segmenter)
 	app_handlers.ConfigureExpressionHandler(expressionInterector, baseRouter)
 
-	translationInterector := usecases.NewTranslationInteractor(repos.Translation)
+	translationInterector := usecases.NewTranslationInteractor(repos.Translation, repos.Group, repos.Activity)
 	app_handlers.ConfigureTranslationHandler(translationInterector, baseRouter)
 
 	langInterector := usecases.NewLangInteractor(repos.Lang)
 	app_handlers.ConfigureLangHandler(langInterector, baseRouter)
 
-	trainingInterector := usecases.NewTrainingInteractor(repos.Training, repos.Node, services.Training)
+	trainingInterector := usecases.NewTrainingInteractor(repos.Training, repos.Node, repos.Tag, repos.Group, services.Training)
 	app_handlers.ConfigureTrainingHandler(trainingInterector, baseRouter)
 
+	activityInterector := usecases.NewActivityInteractor(repos.Activity, repos.Group)
+	app_handlers.ConfigureActivityHandler(activityInterector, baseRouter)
+
+	catalogInterector := usecases.NewCatalogInteractor(repos.Catalog, repos.Group, repos.Node)
+	app_handlers.ConfigureCatalogHandler(catalogInterector, baseRouter)
+
+	tagInterector := usecases.NewTagInteractor(repos.Tag, repos.Group, repos.Node, repos.Activity)
+	app_handlers.ConfigureTagHandler(tagInterector, baseRouter)
+
 	return baseRouter
 }
 
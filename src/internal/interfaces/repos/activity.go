@@ -0,0 +1,154 @@
+package repos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/db"
+)
+
+type ActivityRepo struct {
+	db db.DB
+}
+
+func NewActivityRepo(db db.DB) *ActivityRepo {
+	return &ActivityRepo{db}
+}
+
+func marshalPayload(payload interface{}) ([]byte, error) {
+	if payload == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(payload)
+}
+
+// insert writes the activity once per group returned by groupQuery,
+// which selects group ids using groupArgs as its parameters $1..$n.
+func (r *ActivityRepo) insert(groupQuery string, obj app.Activity, groupArgs ...interface{}) error {
+	before, err := marshalPayload(obj.Before)
+	if err != nil {
+		return err
+	}
+
+	after, err := marshalPayload(obj.After)
+	if err != nil {
+		return err
+	}
+
+	n := len(groupArgs)
+	query := fmt.Sprintf(`
+		INSERT INTO group_activity (group_id, actor_id, action, target_type, target_id, before, after)
+		SELECT DISTINCT g.group_id, $%d::int, $%d, $%d, $%d::int, $%d::jsonb, $%d::jsonb FROM (%s) g(group_id)
+	`, n+1, n+2, n+3, n+4, n+5, n+6, groupQuery)
+
+	args := append(groupArgs, obj.ActorId, obj.Action, obj.TargetType, obj.TargetId, before, after)
+	_, err = r.db.Db().Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *ActivityRepo) Log(obj app.Activity) error {
+	return r.insert(`SELECT $1::int`, obj, obj.GroupId)
+}
+
+func (r *ActivityRepo) LogByNode(nodeId *valueobject.ID, obj app.Activity) error {
+	return r.insert(`SELECT group_id FROM group_node WHERE node_id=$1`, obj, nodeId)
+}
+
+// LogByExpression logs the activity into the group of the node the request
+// came through, provided the node holds the expression.
+func (r *ActivityRepo) LogByExpression(nodeId *valueobject.ID, expressionId *valueobject.ID, obj app.Activity) error {
+	return r.insert(`
+		SELECT gn.group_id FROM group_node gn
+		INNER JOIN node_expression ne ON ne.node_id=gn.node_id
+		WHERE gn.node_id=$1 AND ne.expression_id=$2
+	`, obj, nodeId, expressionId)
+}
+
+// LogByTranslation logs the activity into the group of the node the request
+// came through, provided the node holds the translation.
+func (r *ActivityRepo) LogByTranslation(nodeId *valueobject.ID, translationId *valueobject.ID, obj app.Activity) error {
+	return r.insert(`
+		SELECT gn.group_id FROM group_node gn
+		INNER JOIN node_translation nt ON nt.node_id=gn.node_id
+		WHERE gn.node_id=$1 AND nt.translation_id=$2
+	`, obj, nodeId, translationId)
+}
+
+func (r *ActivityRepo) List(groupId *valueobject.ID, filter app.ActivityFilter) ([]*app.Activity, error) {
+	conditions := []string{"group_id=$1"}
+	args := []interface{}{groupId}
+
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.ActorId != nil {
+		addCondition("actor_id=$%d", filter.ActorId)
+	}
+	if filter.Action != "" {
+		addCondition("action=$%d", filter.Action)
+	}
+	if filter.TargetType != "" {
+		addCondition("target_type=$%d", filter.TargetType)
+	}
+	if filter.TargetId != nil {
+		addCondition("target_id=$%d", filter.TargetId)
+	}
+	if filter.Since != nil {
+		addCondition("created_at >= $%d", filter.Since)
+	}
+	if filter.Until != nil {
+		addCondition("created_at < $%d", filter.Until)
+	}
+	if filter.Cursor != nil {
+		addCondition("id < $%d", filter.Cursor)
+	}
+
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, group_id, actor_id, action, target_type, target_id, before, after, created_at
+		FROM group_activity
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT NULLIF($%d, 0)
+	`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.Db().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activities := []*app.Activity{}
+
+	for rows.Next() {
+		var before, after []byte
+		activity := &app.Activity{}
+		err = rows.Scan(&activity.Id, &activity.GroupId, &activity.ActorId, &activity.Action,
+			&activity.TargetType, &activity.TargetId, &before, &after, &activity.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if before != nil {
+			activity.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			activity.After = json.RawMessage(after)
+		}
+
+		activities = append(activities, activity)
+	}
+
+	return activities, nil
+}
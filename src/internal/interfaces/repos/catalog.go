@@ -0,0 +1,139 @@
+package repos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/db"
+)
+
+type CatalogRepo struct {
+	db db.DB
+}
+
+func NewCatalogRepo(db db.DB) *CatalogRepo {
+	return &CatalogRepo{db}
+}
+
+func (r *CatalogRepo) Search(filter app.CatalogFilter) ([]*app.CatalogEntry, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{app.GroupActive, app.GroupPublic, app.GroupPrivate, app.NodePublic}
+
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(cond, "$?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.TargetLangCode != "" {
+		addCondition("c.target_lang=$?", filter.TargetLangCode)
+	}
+	if filter.NativeLangCode != "" {
+		addCondition("c.native_lang=$?", filter.NativeLangCode)
+	}
+	if filter.Search != "" {
+		addCondition("(c.group_name ILIKE $? OR c.node_name ILIKE $?)", "%"+filter.Search+"%")
+	}
+	if filter.MinSize > 0 {
+		addCondition("c.size >= $?", filter.MinSize)
+	}
+	if filter.MaxSize > 0 {
+		addCondition("c.size <= $?", filter.MaxSize)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	query := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT g.id group_id, g.name group_name, g.target_lang, g.native_lang,
+				NULL::int node_id, NULL::varchar node_name, NULL::smallint node_type, (
+					SELECT COUNT(DISTINCT ne.expression_id) FROM node_expression ne
+					LEFT JOIN group_node gn ON gn.node_id=ne.node_id
+					WHERE gn.group_id=g.id
+				) size
+			FROM groups g
+			WHERE g.status=$1 AND g.visibility=$2
+			UNION ALL
+			SELECT g.id, g.name, g.target_lang, g.native_lang, n.id, n.name, n.type, (
+					SELECT COUNT(DISTINCT ne.expression_id) FROM node_expression ne
+					LEFT JOIN group_node cgn ON cgn.node_id=ne.node_id
+					WHERE cgn.group_id=g.id AND (cgn.node_id=n.id OR cgn.path ~ ('*.' || n.id || '.*')::lquery)
+				) size
+			FROM nodes n
+			LEFT JOIN group_node gn ON gn.node_id=n.id
+			LEFT JOIN groups g ON g.id=gn.group_id
+			WHERE g.status=$1 AND g.visibility=$3 AND n.visibility=$4
+		) c
+		WHERE %s
+		ORDER BY c.size DESC, c.group_id, c.node_id
+		LIMIT NULLIF($%d, 0) OFFSET $%d
+	`, strings.Join(conditions, " AND "), len(args)-1, len(args))
+
+	entries := []*app.CatalogEntry{}
+	err := r.db.Db().Select(&entries, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// IsPublished tells whether the group itself or any of its nodes is in the catalog.
+func (r *CatalogRepo) IsPublished(groupId *valueobject.ID) (bool, error) {
+	var published bool
+	query := `
+		SELECT g.visibility=$2 OR EXISTS (
+			SELECT 1 FROM group_node gn
+			LEFT JOIN nodes n ON n.id=gn.node_id
+			WHERE gn.group_id=g.id AND n.visibility=$3
+		) FROM groups g
+		WHERE g.id=$1 AND g.status=$4
+	`
+	err := r.db.Db().QueryRow(query, groupId, app.GroupPublic, app.NodePublic, app.GroupActive).
+		Scan(&published)
+	if err != nil {
+		return false, err
+	}
+
+	return published, nil
+}
+
+func (r *CatalogRepo) Subscribe(userId *valueobject.ID, groupId *valueobject.ID) error {
+	query := `
+		INSERT INTO subscriptions (user_id, group_id) VALUES ($1, $2)
+		ON CONFLICT (user_id, group_id) DO NOTHING
+	`
+	_, err := r.db.Db().Exec(query, userId, groupId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *CatalogRepo) Unsubscribe(userId *valueobject.ID, groupId *valueobject.ID) error {
+	query := `DELETE FROM subscriptions WHERE user_id=$1 AND group_id=$2`
+	_, err := r.db.Db().Exec(query, userId, groupId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *CatalogRepo) ListSubscriptions(userId *valueobject.ID) ([]*app.Subscription, error) {
+	subscriptions := []*app.Subscription{}
+	query := `
+		SELECT s.group_id, s.created_at FROM subscriptions s
+		LEFT JOIN groups g ON g.id=s.group_id
+		WHERE s.user_id=$1 AND g.status=$2
+		ORDER BY s.created_at DESC
+	`
+	err := r.db.Db().Select(&subscriptions, query, userId, app.GroupActive)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
@@ -0,0 +1,247 @@
+package repos
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// FindDuplicates pairs expressions of the language whose search keys are similar, the older
+// expression of a pair is the one to keep. Candidates come from the trigram index of search keys.
+func (r *ExpressionRepo) FindDuplicates(langCode string, threshold float64, limit uint) ([]*app.DuplicateCandidate, error) {
+	query := `
+		SELECT a.id, a.value, b.id, b.value, similarity(lower(a.value), lower(b.value)) AS score
+		FROM expressions a
+		JOIN LATERAL (
+			SELECT id, value FROM expressions
+			WHERE lang=a.lang AND id>a.id AND search_key(value) % search_key(a.value)
+		) b ON TRUE
+		WHERE a.lang=$1 AND search_key(a.value)<>''
+			AND similarity(search_key(a.value), search_key(b.value)) >= $2
+		ORDER BY score DESC, a.id, b.id
+		LIMIT $3
+	`
+	rows, err := r.db.Db().Query(query, langCode, threshold, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []*app.DuplicateCandidate{}
+	for rows.Next() {
+		candidate := &app.DuplicateCandidate{}
+		err = rows.Scan(&candidate.KeepId, &candidate.KeepValue, &candidate.DropId, &candidate.DropValue, &candidate.Similarity)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// Merge re-points slices, translations, transcriptions, comments and so training items of the dropped
+// expressions to the kept one and deletes the dropped expressions within a single transaction.
+// Node revisions are rewritten as well, so reverting to them doesn't refer to deleted rows.
+func (r *ExpressionRepo) Merge(keepId *valueobject.ID, dropIds []valueobject.ID) error {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return err
+	}
+
+	var count int
+	err = tx.QueryRow(`
+		SELECT COUNT(id) FROM expressions
+		WHERE id=ANY($2) AND lang=(SELECT lang FROM expressions WHERE id=$1)
+	`, keepId, pq.Array(dropIds)).Scan(&count)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if count != len(dropIds) {
+		tx.Rollback()
+		return errors.New("Merged expressions must exist and have the language of the kept one.")
+	}
+
+	expressionIds := make(map[valueobject.ID]valueobject.ID)
+	translationIds := make(map[valueobject.ID]valueobject.ID)
+
+	for _, dropId := range dropIds {
+		err = mergeExpression(tx, keepId, &dropId, translationIds)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		expressionIds[dropId] = *keepId
+	}
+
+	err = rewriteSnapshots(tx, expressionIds, translationIds)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func mergeExpression(tx *sqlx.Tx, keepId *valueobject.ID, dropId *valueobject.ID, translationIds map[valueobject.ID]valueobject.ID) error {
+	err := mergeTranslations(tx, keepId, dropId, translationIds)
+	if err != nil {
+		return err
+	}
+
+	// Tags of the dropped links go away with them by the cascade.
+	err = execAll(tx, []string{
+		`INSERT INTO node_expression (node_id, expression_id, created_at, position)
+			SELECT node_id, $1, created_at, position FROM node_expression WHERE expression_id=$2
+			ON CONFLICT (node_id, expression_id) DO NOTHING`,
+		`INSERT INTO node_expression_tag (node_id, expression_id, tag_id, created_at)
+			SELECT node_id, $1, tag_id, created_at FROM node_expression_tag WHERE expression_id=$2
+			ON CONFLICT (node_id, expression_id, tag_id) DO NOTHING`,
+		`DELETE FROM node_expression WHERE expression_id=$2`,
+		`INSERT INTO expression_transcription (expression_id, transcription_id)
+			SELECT $1, transcription_id FROM expression_transcription WHERE expression_id=$2
+			ON CONFLICT (expression_id, transcription_id) DO NOTHING`,
+		`DELETE FROM expression_transcription WHERE expression_id=$2`,
+		`INSERT INTO object_comment (object_id, comment_id, type)
+			SELECT $1, comment_id, type FROM object_comment
+			WHERE object_id=$2 AND type=(SELECT id FROM object_types WHERE name='expression')
+			ON CONFLICT (type, comment_id, object_id) DO NOTHING`,
+		`DELETE FROM object_comment WHERE object_id=$2 AND type=(SELECT id FROM object_types WHERE name='expression')`,
+		`DELETE FROM expressions WHERE id=$2`,
+	}, keepId, dropId)
+
+	return err
+}
+
+// mergeTranslations moves translations from and to the dropped expression. A translation which
+// the kept expression already has takes over slices, transcriptions and training items of the moved one,
+// such replaced translations are added to translationIds. A training keeps its own item of the existing
+// translation rather than getting a second one.
+func mergeTranslations(tx *sqlx.Tx, keepId *valueobject.ID, dropId *valueobject.ID, translationIds map[valueobject.ID]valueobject.ID) error {
+	type move struct {
+		Id         valueobject.ID  `db:"id"`
+		ExistingId *valueobject.ID `db:"existing_id"`
+	}
+	moves := []*move{}
+
+	query := `
+		SELECT t.id, e.id AS existing_id FROM translations t
+		LEFT JOIN translations e ON e.type=t.type AND e.id<>t.id
+			AND e.target_id=CASE WHEN t.target_id=$2 THEN $1 ELSE t.target_id END
+			AND e.native_id=CASE WHEN t.native_id=$2 THEN $1 ELSE t.native_id END
+		WHERE t.type=(SELECT id FROM object_types WHERE name='expression')
+			AND (t.target_id=$2 OR t.native_id=$2)
+	`
+	err := tx.Select(&moves, query, keepId, dropId)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range moves {
+		if m.ExistingId == nil {
+			_, err = tx.Exec(`
+				UPDATE translations SET
+					target_id=CASE WHEN target_id=$2 THEN $1 ELSE target_id END,
+					native_id=CASE WHEN native_id=$2 THEN $1 ELSE native_id END
+				WHERE id=$3
+			`, keepId, dropId, m.Id)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = execAll(tx, []string{
+			`INSERT INTO node_translation (node_id, translation_id, created_at)
+				SELECT node_id, $1, created_at FROM node_translation WHERE translation_id=$2
+				ON CONFLICT (node_id, translation_id) DO NOTHING`,
+			`DELETE FROM node_translation WHERE translation_id=$2`,
+			`INSERT INTO translation_transcription (translation_id, transcription_id)
+				SELECT $1, transcription_id FROM translation_transcription WHERE translation_id=$2
+				ON CONFLICT (translation_id, transcription_id) DO NOTHING`,
+			`DELETE FROM translation_transcription WHERE translation_id=$2`,
+			`DELETE FROM training_items ti WHERE translation_id=$2 AND EXISTS (
+				SELECT 1 FROM training_items k WHERE k.training_id=ti.training_id AND k.translation_id=$1
+			)`,
+			`UPDATE training_items SET translation_id=$1 WHERE translation_id=$2`,
+			`UPDATE training_failures SET translation_id=$1 WHERE translation_id=$2`,
+			`DELETE FROM translations WHERE id=$2`,
+		}, m.ExistingId, &m.Id)
+		if err != nil {
+			return err
+		}
+		translationIds[m.Id] = *m.ExistingId
+	}
+
+	return nil
+}
+
+// rewriteSnapshots replaces ids of merged expressions and translations in snapshots of node revisions.
+// An expression which meets the one it is merged into is combined with it.
+func rewriteSnapshots(tx *sqlx.Tx, expressionIds map[valueobject.ID]valueobject.ID, translationIds map[valueobject.ID]valueobject.ID) error {
+	ids := func(idMap map[valueobject.ID]valueobject.ID) []valueobject.ID {
+		keys := []valueobject.ID{}
+		for id := range idMap {
+			keys = append(keys, id)
+		}
+		return keys
+	}
+
+	revisions := []struct {
+		Id       valueobject.ID `db:"id"`
+		Snapshot []byte         `db:"snapshot"`
+	}{}
+	query := `
+		SELECT id, snapshot FROM node_revisions nr
+		WHERE EXISTS (
+			SELECT 1 FROM jsonb_array_elements(nr.snapshot->'expressions') e
+			WHERE (e->>'id')::int=ANY($1) OR EXISTS (
+				SELECT 1 FROM jsonb_array_elements(e->'translations') t WHERE (t->>'id')::int=ANY($2)
+			)
+		)
+		FOR UPDATE
+	`
+	err := tx.Select(&revisions, query, pq.Array(ids(expressionIds)), pq.Array(ids(translationIds)))
+	if err != nil {
+		return err
+	}
+
+	for _, revision := range revisions {
+		snapshot := &app.NodeSnapshot{}
+		err = json.Unmarshal(revision.Snapshot, snapshot)
+		if err != nil {
+			return err
+		}
+
+		snapshot.Expressions = app.RemapSnapshotExpressions(snapshot.Expressions, expressionIds, translationIds)
+
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE node_revisions SET snapshot=$1 WHERE id=$2`, data, revision.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execAll runs the statements one by one with the same arguments.
+func execAll(tx *sqlx.Tx, queries []string, args ...interface{}) error {
+	for _, query := range queries {
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package repos
+
+import (
+	"unicode"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/db"
+	"github.com/lib/pq"
+)
+
+type DictionaryRepo struct {
+	db db.DB
+}
+
+func NewDictionaryRepo(db db.DB) *DictionaryRepo {
+	return &DictionaryRepo{db}
+}
+
+// Import replaces the dictionary of the same name with the given entries within a single transaction.
+// Entries are copied in bulk since open dictionaries have a hundred thousand of them.
+func (r *DictionaryRepo) Import(dictionary app.Dictionary, entries []*app.DictionaryEntry) (*app.Dictionary, error) {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`DELETE FROM dictionaries WHERE name=$1`, dictionary.Name)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO dictionaries (name, format, source_lang, native_lang, transcription_type)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(query, dictionary.Name, dictionary.Format, dictionary.SourceLangCode, dictionary.NativeLangCode, dictionary.TranscriptionTypeId).
+		Scan(&dictionary.Id, &dictionary.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("dictionary_entries", "dictionary_id", "headword", "variant", "transcription", "translations"))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		_, err = stmt.Exec(dictionary.Id, entry.Headword, entry.Variant, entry.Transcription, pq.Array(entry.Translations))
+		if err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	_, err = stmt.Exec()
+	if err != nil {
+		stmt.Close()
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = stmt.Close()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	dictionary.Size = uint(len(entries))
+
+	return &dictionary, nil
+}
+
+// suggestionsQuery selects entries of dictionaries with the language pair of the node group
+// whose headword or variant is the expression.
+const suggestionsQuery = `
+	SELECT de.id, d.name, d.format,
+		CASE WHEN d.transcription_type=g.transcription_type THEN de.transcription ELSE '' END,
+		de.translations
+	FROM dictionary_entries de
+	JOIN dictionaries d ON d.id=de.dictionary_id
+	JOIN expressions e ON e.id=$2 AND (de.headword=e.value OR de.variant=e.value)
+	JOIN group_node gn ON gn.node_id=$1
+	JOIN groups g ON g.id=gn.group_id
+	WHERE e.lang=g.target_lang AND d.source_lang=g.target_lang AND d.native_lang=g.native_lang
+`
+
+// entryTranscription converts numbered pinyin of CC-CEDICT entries imported before
+// the parser started to put tone marks.
+func entryTranscription(format app.DictionaryFormat, transcription string) string {
+	if format == app.DictionaryCedict {
+		return domain.PinyinToneMarks(transcription)
+	}
+
+	return transcription
+}
+
+func scanSuggestion(scan func(...interface{}) error) (*app.DictionarySuggestion, error) {
+	var format app.DictionaryFormat
+	var translations pq.StringArray
+	suggestion := &app.DictionarySuggestion{}
+
+	err := scan(&suggestion.EntryId, &suggestion.Dictionary, &format, &suggestion.Transcription, &translations)
+	if err != nil {
+		return nil, err
+	}
+	suggestion.Transcription = entryTranscription(format, suggestion.Transcription)
+	suggestion.Translations = []string(translations)
+
+	return suggestion, nil
+}
+
+// Suggest lists dictionary entries for the expression attached to the node.
+func (r *DictionaryRepo) Suggest(nodeId *valueobject.ID, expressionId *valueobject.ID) ([]*app.DictionarySuggestion, error) {
+	rows, err := r.db.Db().Query(suggestionsQuery+` ORDER BY d.name, de.id`, nodeId, expressionId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []*app.DictionarySuggestion{}
+	for rows.Next() {
+		suggestion, err := scanSuggestion(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, rows.Err()
+}
+
+// GetSuggestion returns the entry when it is a suggestion for the expression of the node.
+func (r *DictionaryRepo) GetSuggestion(nodeId *valueobject.ID, expressionId *valueobject.ID, entryId *valueobject.ID) (*app.DictionarySuggestion, error) {
+	row := r.db.Db().QueryRow(suggestionsQuery+` AND de.id=$3`, nodeId, expressionId, entryId)
+
+	return scanSuggestion(row.Scan)
+}
+
+// maxCandidateLength is the longest word, in characters, looked up among substrings of the content.
+const maxCandidateLength = 12
+
+// candidateWords lists distinct substrings of the content up to maxCandidateLength characters
+// without leading or trailing spaces. They are looked up by the indexed word columns instead of
+// checking every word for an occurrence in the content.
+func candidateWords(content string) []string {
+	runes := []rune(content)
+	seen := make(map[string]bool)
+	words := []string{}
+
+	for start := range runes {
+		if unicode.IsSpace(runes[start]) {
+			continue
+		}
+
+		for end := start + 1; end <= len(runes) && end-start <= maxCandidateLength; end++ {
+			if unicode.IsSpace(runes[end-1]) {
+				continue
+			}
+
+			word := string(runes[start:end])
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+
+	return words
+}
+
+// Lexicon maps words occurring in the value to their transcriptions of the type. Transcriptions
+// confirmed by users on expressions win over dictionary headwords and those over variants.
+func (r *DictionaryRepo) Lexicon(typeId *valueobject.ID, value string) (map[string]string, error) {
+	query := `
+		SELECT DISTINCT ON (word) word, transcription, format FROM (
+			SELECT e.value AS word, t.value AS transcription, '' AS format, 0 AS source, t.id AS rank
+			FROM expressions e
+			JOIN expression_transcription et ON et.expression_id=e.id
+			JOIN transcriptions t ON t.id=et.transcription_id
+			WHERE t.type=$1 AND e.lang=(SELECT lang FROM transcription_types WHERE id=$1) AND e.value=ANY($2)
+			UNION ALL
+			SELECT de.headword, de.transcription, d.format, 1, de.id
+			FROM dictionary_entries de
+			JOIN dictionaries d ON d.id=de.dictionary_id
+			WHERE d.transcription_type=$1 AND de.transcription<>'' AND de.headword=ANY($2)
+			UNION ALL
+			SELECT de.variant, de.transcription, d.format, 2, de.id
+			FROM dictionary_entries de
+			JOIN dictionaries d ON d.id=de.dictionary_id
+			WHERE d.transcription_type=$1 AND de.transcription<>'' AND de.variant=ANY($2)
+		) words
+		ORDER BY word, source, rank
+	`
+	rows, err := r.db.Db().Query(query, typeId, pq.Array(candidateWords(value)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lexicon := make(map[string]string)
+	for rows.Next() {
+		var word, transcription string
+		var format app.DictionaryFormat
+		if err := rows.Scan(&word, &transcription, &format); err != nil {
+			return nil, err
+		}
+		lexicon[word] = entryTranscription(format, transcription)
+	}
+
+	return lexicon, rows.Err()
+}
+
+// Vocabulary lists expressions of the language and words of its dictionaries occurring in the content.
+func (r *DictionaryRepo) Vocabulary(langCode string, content string) (map[string]bool, error) {
+	query := `
+		SELECT value FROM expressions WHERE lang=$1 AND value=ANY($2)
+		UNION
+		SELECT de.headword FROM dictionary_entries de
+		JOIN dictionaries d ON d.id=de.dictionary_id
+		WHERE d.source_lang=$1 AND de.headword=ANY($2)
+		UNION
+		SELECT de.variant FROM dictionary_entries de
+		JOIN dictionaries d ON d.id=de.dictionary_id
+		WHERE d.source_lang=$1 AND de.variant=ANY($2)
+	`
+	rows, err := r.db.Db().Query(query, langCode, pq.Array(candidateWords(content)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vocabulary := make(map[string]bool)
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			return nil, err
+		}
+		vocabulary[word] = true
+	}
+
+	return vocabulary, rows.Err()
+}
@@ -0,0 +1,36 @@
+package repos
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCandidateWords(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"", []string{}},
+		{"中文", []string{"中", "中文", "文"}},
+		{"a b", []string{"a", "a b", "b"}},
+		{"好好", []string{"好", "好好"}},
+		{" x ", []string{"x"}},
+	}
+
+	for _, tt := range tests {
+		if got := candidateWords(tt.content); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("candidateWords(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+
+	long := strings.Repeat("字", maxCandidateLength+5)
+	for _, word := range candidateWords(long) {
+		if n := len([]rune(word)); n > maxCandidateLength {
+			t.Errorf("candidate of %d characters exceeds %d", n, maxCandidateLength)
+		}
+	}
+	if got := len(candidateWords(long)); got != maxCandidateLength {
+		t.Errorf("len(candidateWords()) = %d, want %d distinct repetitions", got, maxCandidateLength)
+	}
+}
@@ -2,11 +2,17 @@ package repos
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/alexkarpovich/lst-api/src/internal/app"
 	"github.com/alexkarpovich/lst-api/src/internal/domain"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
 	"github.com/alexkarpovich/lst-api/src/internal/interfaces/db"
+	"github.com/lib/pq"
 )
 
 type ExpressionRepo struct {
@@ -18,6 +24,11 @@ func NewExpressionRepo(db db.DB) *ExpressionRepo {
 }
 
 func (r *ExpressionRepo) Create(obj *domain.Expression) (*domain.Expression, error) {
+	obj.Value = domain.NormalizeValue(obj.Value)
+	if obj.Value == "" {
+		return nil, errors.New("Expression value is empty.")
+	}
+
 	stmt := `
 		INSERT INTO expressions (lang, value) VALUES(:lang, :value)
 		RETURNING id
@@ -49,16 +60,159 @@ func (r *ExpressionRepo) Get(id *valueobject.ID) (*domain.Expression, error) {
 	return expression, nil
 }
 
-func (r *ExpressionRepo) Search(langCode string, value string) ([]*domain.Expression, error) {
-	var expressions []*domain.Expression
-	query := `SELECT * FROM expressions WHERE lang=$1 AND value LIKE $2`
+// readableCommentQuery selects the translation comment as t.comment when the translation
+// is attached to a node the user can read, see readableNodesQuery, and an empty string otherwise.
+// Placeholders are numbered from the given one in the order of readableCommentArgs.
+func readableCommentQuery(first int) string {
+	return fmt.Sprintf(`
+		CASE WHEN EXISTS (
+			SELECT 1 FROM node_translation nt
+			JOIN group_node gn ON gn.node_id=nt.node_id
+			JOIN groups g ON g.id=gn.group_id
+			WHERE nt.translation_id=t.id AND g.status=$%[1]d AND (
+				g.visibility=$%[2]d
+				OR EXISTS (
+					SELECT 1 FROM user_group ug
+					WHERE ug.group_id=gn.group_id AND ug.user_id=$%[3]d AND ug.status=$%[4]d
+				)
+				OR EXISTS (
+					SELECT 1 FROM group_node pgn
+					JOIN nodes pn ON pn.id=pgn.node_id
+					WHERE pgn.group_id=gn.group_id AND pn.visibility=$%[5]d
+						AND (pgn.node_id=gn.node_id OR gn.path ~ ('*.' || pgn.node_id || '.*')::lquery)
+				)
+			)
+		) THEN COALESCE(t.comment, '') ELSE '' END
+	`, first, first+1, first+2, first+3, first+4)
+}
+
+func readableCommentArgs(userId *valueobject.ID) []interface{} {
+	return []interface{}{app.GroupActive, app.GroupPublic, userId, app.MemberActive, app.NodePublic}
+}
+
+// Search ranks expressions by trigram similarity of their values or transcriptions to the
+// search key, exact prefix matches are boosted. Results are paged by the last expression id.
+func (r *ExpressionRepo) Search(search domain.ExpressionSearch) ([]*domain.ExpressionMatch, error) {
+	var key string
+
+	err := r.db.Db().QueryRow(`SELECT search_key($1)`, search.Value).Scan(&key)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == "" {
+		return nil, errors.New("Search value must contain letters.")
+	}
 
-	err := r.db.Db().Select(&expressions, query, langCode, "%"+value+"%")
+	// The key is passed as a constant so the trigram indexes serve both conditions,
+	// search_key strips punctuation and the key needs no LIKE escaping.
+	query := `
+		WITH candidates AS (
+			SELECT e.id FROM expressions e
+			WHERE e.lang=$1 AND (search_key(e.value) % $2 OR search_key(e.value) LIKE $5)
+			UNION
+			SELECT et.expression_id FROM transcriptions tsc
+			JOIN expression_transcription et ON et.transcription_id=tsc.id
+			JOIN expressions e ON e.id=et.expression_id
+			WHERE e.lang=$1 AND (search_key(tsc.value) % $2 OR search_key(tsc.value) LIKE $5)
+		), ranked AS (
+			SELECT e.id, e.lang, e.value,
+				GREATEST(similarity(search_key(e.value), $2), COALESCE(MAX(similarity(search_key(tsc.value), $2)), 0))
+				+ CASE WHEN search_key(e.value) LIKE $2 || '%' OR COALESCE(bool_or(search_key(tsc.value) LIKE $2 || '%'), FALSE)
+					THEN 1 ELSE 0 END AS rank
+			FROM candidates c
+			JOIN expressions e ON e.id=c.id
+			LEFT JOIN expression_transcription et ON et.expression_id=e.id
+			LEFT JOIN transcriptions tsc ON tsc.id=et.transcription_id
+			GROUP BY e.id
+		)
+		SELECT id, lang, value, rank FROM ranked
+		WHERE $3::int IS NULL OR (rank, id) < (SELECT rank, id FROM ranked WHERE id=$3)
+		ORDER BY rank DESC, id DESC
+		LIMIT $4
+	`
+	rows, err := r.db.Db().Query(query, search.LangCode, key, search.Cursor, search.Limit, "%"+key+"%")
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []*domain.ExpressionMatch{}
+	matchMap := make(map[valueobject.ID]*domain.ExpressionMatch)
+	ids := []valueobject.ID{}
+
+	for rows.Next() {
+		match := &domain.ExpressionMatch{
+			Transcriptions: []*domain.TranscriptionItem{},
+			Translations:   []*domain.TranslationItem{},
+		}
+		err = rows.Scan(&match.Id, &match.LangCode, &match.Value, &match.Rank)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		matches = append(matches, match)
+		matchMap[*match.Id] = match
+		ids = append(ids, *match.Id)
+	}
+	rows.Close()
+
+	if len(ids) == 0 {
+		return matches, nil
+	}
+
+	query = `
+		SELECT et.expression_id, tsc.id, tsc.value FROM expression_transcription et
+		JOIN transcriptions tsc ON tsc.id=et.transcription_id
+		WHERE et.expression_id=ANY($1)
+		ORDER BY tsc.id
+	`
+	rows, err = r.db.Db().Query(query, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
 
-	return expressions, nil
+	for rows.Next() {
+		var expressionId valueobject.ID
+		item := &domain.TranscriptionItem{}
+		err = rows.Scan(&expressionId, &item.Id, &item.Value)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		match := matchMap[expressionId]
+		match.Transcriptions = append(match.Transcriptions, item)
+	}
+	rows.Close()
+
+	query = `
+		SELECT t.target_id, t.id, n.lang, n.value, ` + readableCommentQuery(3) + ` FROM translations t
+		JOIN expressions n ON n.id=t.native_id
+		WHERE t.target_id=ANY($1) AND ($2='' OR n.lang=$2)
+		ORDER BY t.id
+	`
+	args := append([]interface{}{pq.Array(ids), search.NativeLangCode}, readableCommentArgs(search.UserId)...)
+	rows, err = r.db.Db().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var expressionId valueobject.ID
+		translation := &domain.TranslationItem{}
+		err = rows.Scan(&expressionId, &translation.Id, &translation.LangCode, &translation.Value, &translation.Comment)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		match := matchMap[expressionId]
+		match.Translations = append(match.Translations, translation)
+	}
+	rows.Close()
+
+	return matches, nil
 }
 
 func (r *ExpressionRepo) CreateTranscription(expressionId *valueobject.ID, inTranscription domain.Transcription) (*domain.Transcription, error) {
@@ -104,27 +258,201 @@ func (r *ExpressionRepo) CreateTranscription(expressionId *valueobject.ID, inTra
 	return transcription, nil
 }
 
-func (r *ExpressionRepo) GetTranscriptionMap(typeId *valueobject.ID, expressionId *valueobject.ID) (map[string][]*domain.TranscriptionItem, error) {
+// HasTranscription tells whether the expression has a transcription of the type.
+func (r *ExpressionRepo) HasTranscription(expressionId *valueobject.ID, typeId *valueobject.ID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM expression_transcription et
+			JOIN transcriptions t ON t.id=et.transcription_id
+			WHERE et.expression_id=$1 AND t.type=$2
+		)
+	`
+	err := r.db.Db().QueryRow(query, expressionId, typeId).Scan(&exists)
+
+	return exists, err
+}
+
+// FindByValues maps lower case values to expressions of the language, the case is ignored.
+func (r *ExpressionRepo) FindByValues(langCode string, values []string) (map[string]*domain.Expression, error) {
+	query := `
+		SELECT DISTINCT ON (lower(value)) id, lang, value FROM expressions
+		WHERE lang=$1 AND lower(value)=ANY($2)
+		ORDER BY lower(value), id
+	`
+	expressions := []*domain.Expression{}
+	err := r.db.Db().Select(&expressions, query, langCode, pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*domain.Expression)
+	for _, expression := range expressions {
+		found[strings.ToLower(expression.Value)] = expression
+	}
+
+	return found, nil
+}
+
+const (
+	// excerptRadius is how many characters of the text are kept around the expression.
+	excerptRadius      = 60
+	maxExpressionTexts = 10
+)
+
+// textExcerpt cuts the part of the content around the first occurrence of the value.
+func textExcerpt(content string, value string) string {
+	runes := []rune(content)
+	start := 0
+
+	if idx := strings.Index(strings.ToLower(content), strings.ToLower(value)); idx >= 0 && idx <= len(content) {
+		start = utf8.RuneCountInString(content[:idx])
+	}
+
+	from, to := start-excerptRadius, start+len([]rune(value))+excerptRadius
+	if from < 0 {
+		from = 0
+	}
+	if to > len(runes) {
+		to = len(runes)
+	}
+
+	excerpt := strings.TrimSpace(string(runes[from:to]))
+	if from > 0 {
+		excerpt = "…" + excerpt
+	}
+	if to < len(runes) {
+		excerpt = excerpt + "…"
+	}
+
+	return excerpt
+}
+
+// Detail collects translations, transcriptions and example texts of the expression,
+// usage is counted over groups where the user is an active member and the user trainings.
+func (r *ExpressionRepo) Detail(expressionId *valueobject.ID, userId *valueobject.ID) (*app.ExpressionDetail, error) {
+	detail := &app.ExpressionDetail{
+		Translations:   make(map[string][]*domain.TranslationItem),
+		Transcriptions: []*app.TranscriptionGroup{},
+		Texts:          []*app.ExpressionText{},
+	}
+
+	err := r.db.Db().QueryRow(`SELECT id, lang, value FROM expressions WHERE id=$1`, expressionId).
+		Scan(&detail.Id, &detail.LangCode, &detail.Value)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT e.value, t.id, t.value FROM expressions e
-		LEFT JOIN expression_transcription et ON et.expression_id=e.id
-		LEFT JOIN transcriptions t ON et.transcription_id=t.id
-		WHERE t.type=$1 and (SELECT value FROM expressions WHERE id=$2) LIKE '%' || e.value || '%'
-		ORDER BY length(e.value) desc 
+		SELECT t.id, n.lang, n.value, ` + readableCommentQuery(2) + ` FROM translations t
+		JOIN expressions n ON n.id=t.native_id
+		WHERE t.target_id=$1
+		ORDER BY n.lang, t.id
 	`
-	rows, err := r.db.Db().Query(query, typeId, expressionId)
+	args := append([]interface{}{expressionId}, readableCommentArgs(userId)...)
+	rows, err := r.db.Db().Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	transcriptionMap := make(map[string][]*domain.TranscriptionItem)
+	for rows.Next() {
+		translation := &domain.TranslationItem{}
+		err = rows.Scan(&translation.Id, &translation.LangCode, &translation.Value, &translation.Comment)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		detail.Translations[translation.LangCode] = append(detail.Translations[translation.LangCode], translation)
+	}
+	rows.Close()
 
+	query = `
+		SELECT tt.id, tt.name, tsc.id, tsc.value FROM expression_transcription et
+		JOIN transcriptions tsc ON tsc.id=et.transcription_id
+		JOIN transcription_types tt ON tt.id=tsc.type
+		WHERE et.expression_id=$1
+		ORDER BY tt.id, tsc.id
+	`
+	rows, err = r.db.Db().Query(query, expressionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var group *app.TranscriptionGroup
 	for rows.Next() {
-		var exprValue string
+		var typeId valueobject.ID
+		var typeName string
 		item := &domain.TranscriptionItem{}
-		rows.Scan(&exprValue, &item.Id, &item.Value)
-		transcriptionMap[exprValue] = append(transcriptionMap[exprValue], item)
+		err = rows.Scan(&typeId, &typeName, &item.Id, &item.Value)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if group == nil || *group.TypeId != typeId {
+			group = &app.TranscriptionGroup{TypeId: &typeId, TypeName: typeName, Transcriptions: []*domain.TranscriptionItem{}}
+			detail.Transcriptions = append(detail.Transcriptions, group)
+		}
+		group.Transcriptions = append(group.Transcriptions, item)
+	}
+	rows.Close()
+
+	query = `
+		SELECT t.id, t.lang, t.title, t.content, t.created_at FROM texts t
+		WHERE t.lang=$2 AND strpos(lower(t.content), lower($3)) > 0 AND (
+			t.author_id=$1
+			OR EXISTS (
+				SELECT 1 FROM nodes n
+				JOIN group_node gn ON gn.node_id=n.id
+				JOIN groups g ON g.id=gn.group_id
+				WHERE n.text_id=t.id AND g.status=$4 AND (
+					g.visibility=$5
+					OR EXISTS (SELECT 1 FROM user_group ug WHERE ug.group_id=g.id AND ug.user_id=$1 AND ug.status=$6)
+				)
+			)
+		)
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $7
+	`
+	rows, err = r.db.Db().Query(query, userId, detail.LangCode, detail.Value, app.GroupActive, app.GroupPublic, app.MemberActive, maxExpressionTexts)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var content string
+		text := &app.ExpressionText{}
+		err = rows.Scan(&text.Id, &text.LangCode, &text.Title, &content, &text.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		text.Excerpt = textExcerpt(content, detail.Value)
+		detail.Texts = append(detail.Texts, text)
+	}
+	rows.Close()
+
+	query = `
+		SELECT (
+			SELECT COUNT(DISTINCT ne.node_id) FROM node_expression ne
+			JOIN group_node gn ON gn.node_id=ne.node_id
+			JOIN groups g ON g.id=gn.group_id
+			JOIN user_group ug ON ug.group_id=gn.group_id
+			WHERE ne.expression_id=$1 AND ug.user_id=$2 AND ug.status=$3 AND g.status=$4
+		), (
+			SELECT COUNT(DISTINCT tr.id) FROM training_items ti
+			JOIN translations t ON t.id=ti.translation_id
+			JOIN trainings tr ON tr.id=ti.training_id
+			WHERE t.target_id=$1 AND tr.owner_id=$2
+		)
+	`
+	err = r.db.Db().QueryRow(query, expressionId, userId, app.MemberActive, app.GroupActive).
+		Scan(&detail.Usage.Slices, &detail.Usage.Trainings)
+	if err != nil {
+		return nil, err
 	}
 
-	return transcriptionMap, nil
+	return detail, nil
 }
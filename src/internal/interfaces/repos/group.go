@@ -1,10 +1,18 @@
 package repos
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
 	"github.com/alexkarpovich/lst-api/src/internal/app"
 	"github.com/alexkarpovich/lst-api/src/internal/domain"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
 	"github.com/alexkarpovich/lst-api/src/internal/interfaces/db"
+	"github.com/jmoiron/sqlx"
 )
 
 type GroupRepo struct {
@@ -96,22 +104,22 @@ func (r *GroupRepo) Update(obj app.Group) error {
 func (r *GroupRepo) Get(groupId *valueobject.ID) (*app.Group, error) {
 	query := `
 		SELECT 
-			g.id, tt.id, tt.name, g.target_lang, g.native_lang, g.name, g.status, g.config,
+			g.id, tt.id, tt.name, g.target_lang, g.native_lang, g.name, g.status, g.visibility, g.config,
 			(SELECT COUNT(user_id) FROM user_group WHERE group_id=g.id) as users_count,
 			(SELECT coalesce(COUNT(node_id), 0) FROM group_node WHERE group_id=g.id) as node_count 
 		FROM groups g
 		LEFT JOIN user_group ug ON ug.group_id=g.id
 		LEFT JOIN transcription_types tt ON tt.id=g.transcription_type
-		WHERE g.id=$1
+		WHERE g.id=$1 AND g.status=$2
 		ORDER BY g.id DESC
 	`
 	var usersCount, nodesCount uint
 	group := &app.Group{}
 	transcriptionType := &domain.TranscriptionType{}
-	err := r.db.Db().QueryRow(query, groupId).
+	err := r.db.Db().QueryRow(query, groupId, app.GroupActive).
 		Scan(&group.Id, &transcriptionType.Id, &transcriptionType.Name,
 			&group.TargetLangCode, &group.NativeLangCode, &group.Name,
-			&group.Status, &group.Config, &usersCount, &nodesCount)
+			&group.Status, &group.Visibility, &group.Config, &usersCount, &nodesCount)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +158,7 @@ func (r *GroupRepo) Get(groupId *valueobject.ID) (*app.Group, error) {
 func (r *GroupRepo) List(userId *valueobject.ID) ([]*app.Group, error) {
 	query := `
 		SELECT 
-			g.id, tt.id, tt.name, g.target_lang, g.native_lang, g.name, g.status, g.config,
+			g.id, tt.id, tt.name, g.target_lang, g.native_lang, g.name, g.status, g.visibility, g.config,
 			(SELECT COUNT(user_id) FROM user_group WHERE group_id=g.id) as users_count,
 			(SELECT coalesce(COUNT(node_id), 0) FROM group_node WHERE group_id=g.id) as node_count 
 		FROM groups g
@@ -171,7 +179,7 @@ func (r *GroupRepo) List(userId *valueobject.ID) ([]*app.Group, error) {
 	for rows.Next() {
 		group := &app.Group{}
 		transcriptionType := &domain.TranscriptionType{}
-		rows.Scan(&group.Id, &transcriptionType.Id, &transcriptionType.Name, &group.TargetLangCode, &group.NativeLangCode, &group.Name, &group.Status, &group.Config, &usersCount, &nodesCount)
+		rows.Scan(&group.Id, &transcriptionType.Id, &transcriptionType.Name, &group.TargetLangCode, &group.NativeLangCode, &group.Name, &group.Status, &group.Visibility, &group.Config, &usersCount, &nodesCount)
 
 		group.TranscriptionTypeId = transcriptionType.Id
 		group.TranscriptionType = transcriptionType
@@ -211,10 +219,10 @@ func (r *GroupRepo) List(userId *valueobject.ID) ([]*app.Group, error) {
 	return groups, nil
 }
 
-func (r *GroupRepo) MarkAsDeleted(groupId *valueobject.ID) error {
-	stmt := `UPDATE groups SET status=$1 WHERE id=$2`
+func (r *GroupRepo) MarkAsDeleted(groupId *valueobject.ID, userId *valueobject.ID) error {
+	stmt := `UPDATE groups SET status=$1, deleted_at=NOW(), deleted_by=$2 WHERE id=$3 AND status=$4`
 
-	_, err := r.db.Db().Exec(stmt, app.GroupDeleted, groupId)
+	_, err := r.db.Db().Exec(stmt, app.GroupDeleted, userId, groupId, app.GroupActive)
 	if err != nil {
 		return err
 	}
@@ -222,115 +230,277 @@ func (r *GroupRepo) MarkAsDeleted(groupId *valueobject.ID) error {
 	return nil
 }
 
-func (r *GroupRepo) MoveNode(groupId *valueobject.ID, node app.FlatNode, nodeOrder []*valueobject.ID) error {
-	group, err := r.Get(groupId)
+// ListDeleted returns groups of the user which were deleted after the given moment.
+func (r *GroupRepo) ListDeleted(userId *valueobject.ID, deletedAfter time.Time) ([]*app.Group, error) {
+	query := `
+		SELECT g.id, g.target_lang, g.native_lang, g.name, g.status, g.visibility, g.deleted_at
+		FROM groups g
+		LEFT JOIN user_group ug ON ug.group_id=g.id
+		WHERE ug.user_id=$1 AND ug.status=$2 AND g.status=$3 AND g.deleted_at > $4
+		ORDER BY g.deleted_at DESC
+	`
+	groups := []*app.Group{}
+	rows, err := r.db.Db().Query(query, userId, app.MemberActive, app.GroupDeleted, deletedAfter)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	if group.Config == nil {
-		group.Config = &app.GroupConfig{
-			NodeOrder: nodeOrder,
+	for rows.Next() {
+		group := &app.Group{}
+		err = rows.Scan(&group.Id, &group.TargetLangCode, &group.NativeLangCode, &group.Name,
+			&group.Status, &group.Visibility, &group.DeletedAt)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		group.Config.NodeOrder = nodeOrder
+
+		groups = append(groups, group)
 	}
 
-	tx, err := r.db.Db().Begin()
+	return groups, nil
+}
+
+// Restore brings back the group if it was deleted after the given moment.
+func (r *GroupRepo) Restore(groupId *valueobject.ID, deletedAfter time.Time) error {
+	stmt := `
+		UPDATE groups SET status=$1, deleted_at=NULL, deleted_by=NULL
+		WHERE id=$2 AND status=$3 AND deleted_at > $4
+	`
+	res, err := r.db.Db().Exec(stmt, app.GroupActive, groupId, app.GroupDeleted, deletedAfter)
 	if err != nil {
 		return err
 	}
-	query := `UPDATE groups SET config=$1 WHERE id=$2`
-	_, err = tx.Exec(query, group.Config, groupId)
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errors.New("Group is not in the trash or can't be restored anymore.")
+	}
+
+	return nil
+}
+
+// execIn runs each statement with its only IN (?) placeholder expanded to the ids.
+func execIn(tx *sqlx.Tx, stmts []string, ids []valueobject.ID) error {
+	for _, stmt := range stmts {
+		stmt, args, err := sqlx.In(stmt, ids)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(tx.Rebind(stmt), args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// purgeOrphanNodes removes the nodes with their expressions and translations
+// unless some group or node trash still refers to them.
+func purgeOrphanNodes(tx *sqlx.Tx, nodeIds []valueobject.ID) error {
+	if len(nodeIds) == 0 {
+		return nil
+	}
+
+	orphan := `node_id NOT IN (SELECT node_id FROM group_node) AND node_id NOT IN (SELECT node_id FROM node_trash_items)`
+
+	return execIn(tx, []string{
+		`DELETE FROM node_expression WHERE node_id IN (?) AND ` + orphan,
+		`DELETE FROM node_translation WHERE node_id IN (?) AND ` + orphan,
+		`DELETE FROM node_revisions WHERE node_id IN (?) AND ` + orphan,
+		`DELETE FROM nodes WHERE id IN (?) AND id NOT IN (SELECT node_id FROM group_node) AND id NOT IN (SELECT node_id FROM node_trash_items)`,
+	}, nodeIds)
+}
+
+// Purge permanently removes groups deleted before the given moment together with
+// their memberships, links, activity, subscriptions, node trash and the nodes no other group refers to.
+func (r *GroupRepo) Purge(deletedBefore time.Time) (uint, error) {
+	var groupIds, nodeIds []valueobject.ID
+
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT id FROM groups WHERE status=$1 AND deleted_at < $2 FOR UPDATE`
+	err = tx.Select(&groupIds, query, app.GroupDeleted, deletedBefore)
 	if err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
 	}
 
-	query = `UPDATE group_node SET path=$1 WHERE group_id=$2 AND node_id=$3`
-	_, err = tx.Exec(query, node.Path, groupId, node.Id)
+	if len(groupIds) == 0 {
+		tx.Rollback()
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT node_id FROM group_node WHERE group_id IN (?)
+		UNION
+		SELECT nti.node_id FROM node_trash_items nti
+		LEFT JOIN node_trash nt ON nt.id=nti.trash_id
+		WHERE nt.group_id IN (?)
+	`, groupIds, groupIds)
 	if err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
+	}
+	err = tx.Select(&nodeIds, tx.Rebind(query), args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = execIn(tx, []string{
+		`DELETE FROM group_node WHERE group_id IN (?)`,
+		`DELETE FROM node_trash_items WHERE trash_id IN (SELECT id FROM node_trash WHERE group_id IN (?))`,
+		`DELETE FROM node_trash WHERE group_id IN (?)`,
+		`DELETE FROM user_group WHERE group_id IN (?)`,
+		`DELETE FROM group_links WHERE group_id IN (?)`,
+		`DELETE FROM group_activity WHERE group_id IN (?)`,
+		`DELETE FROM subscriptions WHERE group_id IN (?)`,
+		`DELETE FROM groups WHERE id IN (?)`,
+	}, groupIds)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
 	}
 
-	tx.Commit()
+	err = purgeOrphanNodes(tx, nodeIds)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
 
-	return nil
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(len(groupIds)), nil
 }
 
-func (r *GroupRepo) DeleteNode(groupId *valueobject.ID, nodeId *valueobject.ID) error {
-	query := `DELETE FROM group_node WHERE group_id=$1 AND node_id=$2`
-	_, err := r.db.Db().Exec(query, groupId, nodeId)
+func (r *GroupRepo) UpdateVisibility(groupId *valueobject.ID, visibility app.GroupVisibility) error {
+	stmt := `UPDATE groups SET visibility=$1 WHERE id=$2`
+
+	_, err := r.db.Db().Exec(stmt, visibility, groupId)
 	if err != nil {
 		return err
 	}
-	// TODO: delete node and all related data if node is unused by other groups
+
 	return nil
 }
 
-func (r *GroupRepo) FindMemberById(groupId *valueobject.ID, memberId *valueobject.ID) (*app.GroupMember, error) {
-	member := &app.GroupMember{
-		Id: memberId,
-	}
+// selectNodeOrder locks the group config and returns its node order.
+func selectNodeOrder(tx *sqlx.Tx, groupId *valueobject.ID) ([]*valueobject.ID, error) {
+	var config []byte
 
-	query := `
-		SELECT role, status FROM user_group 
-		WHERE group_id=$1 AND user_id=$2
-	`
-	err := r.db.Db().QueryRow(query, groupId, memberId).
-		Scan(&member.Role, &member.Status)
+	query := `SELECT config FROM groups WHERE id=$1 FOR UPDATE`
+	err := tx.QueryRow(query, groupId).Scan(&config)
 	if err != nil {
 		return nil, err
 	}
 
-	return member, nil
+	groupConfig := &app.GroupConfig{}
+	if config != nil {
+		err = groupConfig.Scan(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groupConfig.NodeOrder, nil
 }
 
-func (r *GroupRepo) FindMemberByNodeId(nodeId *valueobject.ID, memberId *valueobject.ID) (*app.GroupMember, error) {
-	member := &app.GroupMember{
-		Id: memberId,
+func updateNodeOrder(tx *sqlx.Tx, groupId *valueobject.ID, nodeOrder []*valueobject.ID) error {
+	order, err := json.Marshal(nodeOrder)
+	if err != nil {
+		return err
 	}
 
 	query := `
-		SELECT u.username, ug.role, ug.status FROM user_group ug
-		LEFT JOIN users u ON u.id=ug.user_id
-		LEFT JOIN group_node gn ON gn.group_id=ug.group_id
-		WHERE gn.node_id=$1 AND ug.user_id=$2
+		UPDATE groups SET config=jsonb_set(COALESCE(config, '{}'::jsonb), '{nodeOrder}', $1::jsonb)
+		WHERE id=$2
 	`
-	err := r.db.Db().QueryRow(query, nodeId, memberId).
-		Scan(&member.Username, &member.Role, &member.Status)
+	_, err = tx.Exec(query, string(order), groupId)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return member, nil
+	return nil
 }
 
-func (r *GroupRepo) FindMemberByToken(token string) (*valueobject.ID, *app.GroupMember, error) {
-	var groupId *valueobject.ID
+// MoveNode places the node under the new path and rewrites paths of all its descendants.
+// Node order is left untouched when nil is given.
+func (r *GroupRepo) MoveNode(groupId *valueobject.ID, node app.FlatNode, nodeOrder []*valueobject.ID) error {
+	var oldPath string
 
-	member := &app.GroupMember{}
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return err
+	}
 
-	query := `
-		SELECT group_id, user_id, role, status FROM user_group 
-		WHERE token=$1 AND status=$2 AND token_expires_at > NOW()
+	query := `SELECT path FROM group_node WHERE group_id=$1 AND node_id=$2 FOR UPDATE`
+	err = tx.QueryRow(query, groupId, node.Id).Scan(&oldPath)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	oldChildPath := app.FlatNode{Id: node.Id, Path: oldPath}.ChildPath()
+	newChildPath := node.ChildPath()
+
+	query = `
+		UPDATE group_node SET path=CASE
+			WHEN path=$2::ltree THEN $3::ltree
+			ELSE $3::ltree || subpath(path, nlevel($2::ltree))
+		END
+		WHERE group_id=$1 AND path <@ $2::ltree
 	`
-	err := r.db.Db().QueryRow(query, token, app.MemberPending).
-		Scan(&groupId, &member.Id, &member.Role, &member.Status)
+	_, err = tx.Exec(query, groupId, oldChildPath, newChildPath)
 	if err != nil {
-		return nil, nil, err
+		tx.Rollback()
+		return err
 	}
 
-	return groupId, member, nil
+	query = `UPDATE group_node SET path=$1 WHERE group_id=$2 AND node_id=$3`
+	_, err = tx.Exec(query, node.Path, groupId, node.Id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if nodeOrder != nil {
+		err = updateNodeOrder(tx, groupId, nodeOrder)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (r *GroupRepo) AttachUser(groupId *valueobject.ID, member app.GroupMember) error {
-	query := `
-		INSERT INTO user_group (group_id, user_id, role, status, token, token_expires_at) 
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-	_, err := r.db.Db().Exec(query, groupId, member.Id, member.Role, member.Status, member.Token, member.TokenExpiresAt)
+// DeleteNode moves the node with its whole subtree out of the group into the node trash
+// remembering original paths and positions in the node order.
+func (r *GroupRepo) DeleteNode(groupId *valueobject.ID, nodeId *valueobject.ID, userId *valueobject.ID) error {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return err
+	}
+
+	err = trashNode(tx, groupId, nodeId, userId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return err
 	}
@@ -338,26 +508,1283 @@ func (r *GroupRepo) AttachUser(groupId *valueobject.ID, member app.GroupMember)
 	return nil
 }
 
-func (r *GroupRepo) DetachMember(groupId *valueobject.ID, userId *valueobject.ID) error {
-	query := `DELETE FROM user_group WHERE group_id=$1 AND user_id=$2`
+// trashNode moves the node with its subtree out of the group tree into the node trash.
+func trashNode(tx *sqlx.Tx, groupId *valueobject.ID, nodeId *valueobject.ID, userId *valueobject.ID) error {
+	var path string
+	var trashId valueobject.ID
+
+	query := `SELECT path FROM group_node WHERE group_id=$1 AND node_id=$2 FOR UPDATE`
+	err := tx.QueryRow(query, groupId, nodeId).Scan(&path)
+	if err != nil {
+		return err
+	}
+
+	items := []*app.FlatNode{}
+	query = `
+		SELECT node_id AS id, path::text AS path FROM group_node
+		WHERE group_id=$1 AND (node_id=$2 OR path <@ $3::ltree)
+	`
+	err = tx.Select(&items, query, groupId, nodeId, app.FlatNode{Id: nodeId, Path: path}.ChildPath())
+	if err != nil {
+		return err
+	}
+
+	nodeOrder, err := selectNodeOrder(tx, groupId)
+	if err != nil {
+		return err
+	}
+
+	query = `INSERT INTO node_trash (group_id, node_id, deleted_by) VALUES ($1, $2, $3) RETURNING id`
+	err = tx.QueryRow(query, groupId, nodeId, userId).Scan(&trashId)
+	if err != nil {
+		return err
+	}
+
+	deleted := make(map[valueobject.ID]bool)
+	ids := []valueobject.ID{}
+
+	for _, item := range items {
+		var orderIdx *int
+		for idx, id := range nodeOrder {
+			if id != nil && *id == *item.Id {
+				orderIdx = &idx
+				break
+			}
+		}
+
+		query = `INSERT INTO node_trash_items (trash_id, node_id, path, order_idx) VALUES ($1, $2, $3, $4)`
+		_, err = tx.Exec(query, trashId, item.Id, item.Path, orderIdx)
+		if err != nil {
+			return err
+		}
+
+		deleted[*item.Id] = true
+		ids = append(ids, *item.Id)
+	}
 
-	_, err := r.db.Db().Exec(query, groupId, userId)
+	query, args, err := sqlx.In(`DELETE FROM group_node WHERE group_id=? AND node_id IN (?)`, groupId, ids)
 	if err != nil {
 		return err
 	}
+	_, err = tx.Exec(tx.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+
+	if nodeOrder != nil {
+		newOrder := []*valueobject.ID{}
+		for _, id := range nodeOrder {
+			if id == nil || !deleted[*id] {
+				newOrder = append(newOrder, id)
+			}
+		}
+
+		err = updateNodeOrder(tx, groupId, newOrder)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-func (r *GroupRepo) UpdateMember(groupId *valueobject.ID, member app.GroupMember) error {
+// ListDeletedNodes returns the node trash of the group with nodes deleted after the given moment.
+func (r *GroupRepo) ListDeletedNodes(groupId *valueobject.ID, deletedAfter time.Time) ([]*app.TrashedNode, error) {
+	nodes := []*app.TrashedNode{}
 	query := `
-		UPDATE user_group SET role=$1, status=$2 
-		WHERE group_id=$3 AND user_id=$4	
+		SELECT nt.id, nt.node_id, n.type, n.name, nti.path::text AS path, nt.deleted_by, nt.deleted_at,
+			(SELECT COUNT(*) FROM node_trash_items WHERE trash_id=nt.id) AS size
+		FROM node_trash nt
+		LEFT JOIN nodes n ON n.id=nt.node_id
+		LEFT JOIN node_trash_items nti ON nti.trash_id=nt.id AND nti.node_id=nt.node_id
+		WHERE nt.group_id=$1 AND nt.deleted_at > $2
+		ORDER BY nt.deleted_at DESC
+	`
+	err := r.db.Db().Select(&nodes, query, groupId, deletedAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// RestoreNode brings the deleted subtree back to its original place and order.
+// When the original parent is gone the subtree is restored to the top level.
+func (r *GroupRepo) RestoreNode(groupId *valueobject.ID, trashId *valueobject.ID, deletedAfter time.Time) (*valueobject.ID, error) {
+	var nodeId valueobject.ID
+	var parentPath string
+
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT node_id FROM node_trash WHERE id=$1 AND group_id=$2 AND deleted_at > $3 FOR UPDATE`
+	err = tx.QueryRow(query, trashId, groupId, deletedAfter).Scan(&nodeId)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, errors.New("Node is not in the trash or can't be restored anymore.")
+		}
+		return nil, err
+	}
+
+	type trashItem struct {
+		NodeId   valueobject.ID `db:"node_id"`
+		Path     string         `db:"path"`
+		OrderIdx *int           `db:"order_idx"`
+	}
+	items := []*trashItem{}
+	query = `
+		SELECT node_id, path::text AS path, order_idx FROM node_trash_items
+		WHERE trash_id=$1
+		ORDER BY order_idx NULLS LAST
 	`
+	err = tx.Select(&items, query, trashId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var rootPath []valueobject.ID
+	for _, item := range items {
+		if item.NodeId == nodeId {
+			rootPath = app.SplitNodePath(item.Path)
+		}
+	}
+
+	newRootPath := []valueobject.ID{}
+	if len(rootPath) > 0 {
+		parent := app.FlatNode{Id: &rootPath[len(rootPath)-1]}
+		query = `
+			SELECT gn.path::text FROM group_node gn
+			LEFT JOIN nodes n ON n.id=gn.node_id
+			WHERE gn.group_id=$1 AND gn.node_id=$2 AND n.type=$3
+		`
+		err = tx.QueryRow(query, groupId, parent.Id, app.NodeFolder).Scan(&parentPath)
+		if err != nil && err != sql.ErrNoRows {
+			tx.Rollback()
+			return nil, err
+		}
+		if err == nil {
+			parent.Path = parentPath
+			newRootPath = app.SplitNodePath(parent.ChildPath())
+		}
+	}
 
-	_, err := r.db.Db().Exec(query, member.Role, member.Status, groupId, member.Id)
+	nodeOrder, err := selectNodeOrder(tx, groupId)
 	if err != nil {
-		return err
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, item := range items {
+		itemPath := app.SplitNodePath(item.Path)
+		path := append(append([]valueobject.ID{}, newRootPath...), itemPath[len(rootPath):]...)
+
+		query = `INSERT INTO group_node (group_id, node_id, path) VALUES ($1, $2, $3)`
+		_, err = tx.Exec(query, groupId, item.NodeId, app.JoinNodePath(path))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if item.OrderIdx != nil {
+			idx := *item.OrderIdx
+			if idx > len(nodeOrder) {
+				idx = len(nodeOrder)
+			}
+			id := item.NodeId
+			nodeOrder = append(nodeOrder[:idx], append([]*valueobject.ID{&id}, nodeOrder[idx:]...)...)
+		}
+	}
+
+	if nodeOrder != nil {
+		err = updateNodeOrder(tx, groupId, nodeOrder)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = execIn(tx, []string{
+		`DELETE FROM node_trash_items WHERE trash_id IN (?)`,
+		`DELETE FROM node_trash WHERE id IN (?)`,
+	}, []valueobject.ID{*trashId})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &nodeId, nil
+}
+
+// PurgeNodes permanently removes node trash older than the given moment
+// together with the nodes no group refers to anymore.
+func (r *GroupRepo) PurgeNodes(deletedBefore time.Time) (uint, error) {
+	var trashIds, nodeIds []valueobject.ID
+
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT id FROM node_trash WHERE deleted_at < $1 FOR UPDATE`
+	err = tx.Select(&trashIds, query, deletedBefore)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if len(trashIds) == 0 {
+		tx.Rollback()
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT node_id FROM node_trash_items WHERE trash_id IN (?)`, trashIds)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	err = tx.Select(&nodeIds, tx.Rebind(query), args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = execIn(tx, []string{
+		`DELETE FROM node_trash_items WHERE trash_id IN (?)`,
+		`DELETE FROM node_trash WHERE id IN (?)`,
+	}, trashIds)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = purgeOrphanNodes(tx, nodeIds)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(len(trashIds)), nil
+}
+
+func (r *GroupRepo) FindMemberById(groupId *valueobject.ID, memberId *valueobject.ID) (*app.GroupMember, error) {
+	member := &app.GroupMember{
+		Id: memberId,
+	}
+
+	query := `
+		SELECT role, status FROM user_group 
+		WHERE group_id=$1 AND user_id=$2
+	`
+	err := r.db.Db().QueryRow(query, groupId, memberId).
+		Scan(&member.Role, &member.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (r *GroupRepo) FindMemberByNodeId(nodeId *valueobject.ID, memberId *valueobject.ID) (*app.GroupMember, error) {
+	member := &app.GroupMember{
+		Id: memberId,
+	}
+
+	query := `
+		SELECT u.username, ug.role, ug.status FROM user_group ug
+		LEFT JOIN users u ON u.id=ug.user_id
+		LEFT JOIN group_node gn ON gn.group_id=ug.group_id
+		INNER JOIN groups g ON g.id=ug.group_id
+		WHERE gn.node_id=$1 AND ug.user_id=$2 AND g.status=$3
+	`
+	err := r.db.Db().QueryRow(query, nodeId, memberId, app.GroupActive).
+		Scan(&member.Username, &member.Role, &member.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (r *GroupRepo) FindMemberByToken(token string) (*valueobject.ID, *app.GroupMember, error) {
+	var groupId *valueobject.ID
+
+	member := &app.GroupMember{}
+
+	query := `
+		SELECT group_id, user_id, role, status FROM user_group 
+		WHERE token=$1 AND status=$2 AND token_expires_at > NOW()
+	`
+	err := r.db.Db().QueryRow(query, token, app.MemberPending).
+		Scan(&groupId, &member.Id, &member.Role, &member.Status)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return groupId, member, nil
+}
+
+func (r *GroupRepo) AttachUser(groupId *valueobject.ID, member app.GroupMember) error {
+	query := `
+		INSERT INTO user_group (group_id, user_id, role, status, token, token_expires_at) 
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, group_id) DO UPDATE
+		SET role=EXCLUDED.role, status=EXCLUDED.status, token=EXCLUDED.token, token_expires_at=EXCLUDED.token_expires_at
+		WHERE user_group.status<>$7
+	`
+	_, err := r.db.Db().Exec(query, groupId, member.Id, member.Role, member.Status, member.Token, member.TokenExpiresAt, app.MemberActive)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// keepLastAdmin locks active admins of the group until the transaction ends and fails
+// when the member is the only one of them, so that a group always keeps somebody who can manage it.
+func keepLastAdmin(tx *sql.Tx, groupId *valueobject.ID, userId *valueobject.ID) error {
+	rows, err := tx.Query(`
+		SELECT user_id FROM user_group
+		WHERE group_id=$1 AND role=$2 AND status=$3
+		FOR UPDATE
+	`, groupId, app.UserAdmin, app.MemberActive)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	isAdmin := false
+	count := 0
+	for rows.Next() {
+		var adminId valueobject.ID
+		if err := rows.Scan(&adminId); err != nil {
+			return err
+		}
+		count++
+		isAdmin = isAdmin || adminId == *userId
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if isAdmin && count <= 1 {
+		return errors.New("Group must have at least one admin, transfer admin role first.")
+	}
+
+	return nil
+}
+
+func (r *GroupRepo) DetachMember(groupId *valueobject.ID, userId *valueobject.ID) error {
+	tx, err := r.db.Db().Begin()
+	if err != nil {
+		return err
+	}
+
+	err = keepLastAdmin(tx, groupId, userId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `DELETE FROM user_group WHERE group_id=$1 AND user_id=$2`
+
+	_, err = tx.Exec(query, groupId, userId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *GroupRepo) UpdateMember(groupId *valueobject.ID, member app.GroupMember) error {
+	tx, err := r.db.Db().Begin()
+	if err != nil {
+		return err
+	}
+
+	if member.Role != app.UserAdmin || member.Status != app.MemberActive {
+		err = keepLastAdmin(tx, groupId, member.Id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	query := `
+		UPDATE user_group SET role=$1, status=$2 
+		WHERE group_id=$3 AND user_id=$4	
+	`
+
+	_, err = tx.Exec(query, member.Role, member.Status, groupId, member.Id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type cloneNode struct {
+	Id              valueobject.ID
+	Type            app.NodeType
+	Name            string
+	TextId          *valueobject.ID
+	Path            []valueobject.ID
+	ExpressionOrder app.ExpressionOrder
+	SmartQuery      *app.SmartQuery
+}
+
+// cloneName returns the name of the clone, the requested one when it's free
+// or the first free one of "<source> (copy)", "<source> (copy 2)", ... otherwise.
+func cloneName(tx *sqlx.Tx, name string, source *app.Group) (string, error) {
+	var taken bool
+	query := `SELECT EXISTS(SELECT 1 FROM groups WHERE name=$1 AND target_lang=$2 AND native_lang=$3)`
+
+	if name != "" {
+		err := tx.QueryRow(query, name, source.TargetLangCode, source.NativeLangCode).Scan(&taken)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", errors.New("Group with the name already exists.")
+		}
+		return name, nil
+	}
+
+	for n := 1; ; n++ {
+		name = source.Name + " (copy)"
+		if n > 1 {
+			name = fmt.Sprintf("%s (copy %d)", source.Name, n)
+		}
+
+		err := tx.QueryRow(query, name, source.TargetLangCode, source.NativeLangCode).Scan(&taken)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return name, nil
+		}
+	}
+}
+
+// Clone creates a new group with the same language pair and copies either the
+// whole node tree of the source group or only the subtrees of given nodes.
+// Expressions, translations and texts are shared, only links to them are copied.
+// Cloned nodes are private and an empty name defaults to a free "<source> (copy)" one.
+func (r *GroupRepo) Clone(userId *valueobject.ID, sourceId *valueobject.ID, obj app.Group, nodeIds []valueobject.ID) (*valueobject.ID, error) {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	source := &app.Group{}
+	query := `
+		SELECT transcription_type, target_lang, native_lang, name, config FROM groups
+		WHERE id=$1 AND status=$2
+		FOR SHARE
+	`
+	err = tx.QueryRow(query, sourceId, app.GroupActive).
+		Scan(&source.TranscriptionTypeId, &source.TargetLangCode, &source.NativeLangCode, &source.Name, &source.Config)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, errors.New("Group doesn't exist.")
+		}
+		return nil, err
+	}
+
+	query = `
+		SELECT n.id, n.type, n.name, n.text_id, n.expression_order, n.smart_query, gn.path FROM group_node gn
+		LEFT JOIN nodes n ON n.id=gn.node_id
+		WHERE gn.group_id=$1
+	`
+	rows, err := tx.Query(query, sourceId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	nodes := []*cloneNode{}
+	for rows.Next() {
+		var path string
+		node := &cloneNode{}
+		err = rows.Scan(&node.Id, &node.Type, &node.Name, &node.TextId, &node.ExpressionOrder, &node.SmartQuery, &path)
+		if err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		node.Path = app.SplitNodePath(path)
+		nodes = append(nodes, node)
+	}
+	rows.Close()
+
+	selected := make(map[valueobject.ID]bool)
+	for _, id := range nodeIds {
+		selected[id] = true
+	}
+
+	included := make(map[valueobject.ID]bool)
+	for _, node := range nodes {
+		if len(selected) == 0 || selected[node.Id] {
+			included[node.Id] = true
+			continue
+		}
+		for _, ancestorId := range node.Path {
+			if selected[ancestorId] {
+				included[node.Id] = true
+				break
+			}
+		}
+	}
+
+	if len(included) == 0 {
+		tx.Rollback()
+		return nil, errors.New("There are no nodes to clone in the group.")
+	}
+
+	obj.Name, err = cloneName(tx, obj.Name, source)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO groups (name, transcription_type, target_lang, native_lang, status) 
+		VALUES($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err = tx.QueryRow(query, obj.Name, source.TranscriptionTypeId, source.TargetLangCode, source.NativeLangCode, app.GroupActive).
+		Scan(&obj.Id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	query = `INSERT INTO user_group (user_id, group_id, role, status) VALUES($1, $2, $3, $4)`
+	_, err = tx.Exec(query, userId, obj.Id, app.UserAdmin, app.MemberActive)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	idMap := make(map[valueobject.ID]valueobject.ID)
+	smartQueries := make(map[valueobject.ID]*app.SmartQuery)
+	for _, node := range nodes {
+		if !included[node.Id] {
+			continue
+		}
+
+		var newId valueobject.ID
+		query = `
+			INSERT INTO nodes (type, name, visibility, text_id, expression_order, smart_query) VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`
+		err = tx.QueryRow(query, node.Type, node.Name, app.NodePrivate, node.TextId, node.ExpressionOrder, node.SmartQuery).
+			Scan(&newId)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		idMap[node.Id] = newId
+
+		// Queries of the clone are owned by its creator.
+		if node.SmartQuery != nil {
+			smartQuery := *node.SmartQuery
+			smartQuery.OwnerId = userId
+			smartQueries[newId] = &smartQuery
+		}
+
+		query = `
+			INSERT INTO node_expression (node_id, expression_id, created_at, position)
+			SELECT $1, expression_id, created_at, position FROM node_expression WHERE node_id=$2
+		`
+		_, err = tx.Exec(query, newId, node.Id)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		query = `
+			INSERT INTO node_translation (node_id, translation_id, created_at)
+			SELECT $1, translation_id, created_at FROM node_translation WHERE node_id=$2
+		`
+		_, err = tx.Exec(query, newId, node.Id)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		_, err = insertRevision(tx, &newId, userId, app.ActivityGroupClone)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	for _, node := range nodes {
+		if !included[node.Id] {
+			continue
+		}
+
+		// Ancestors which were left out are cut off, so selected nodes become roots.
+		path := []valueobject.ID{}
+		for _, ancestorId := range node.Path {
+			if newAncestorId, ok := idMap[ancestorId]; ok {
+				path = append(path, newAncestorId)
+			}
+		}
+
+		query = `INSERT INTO group_node (group_id, node_id, path) VALUES ($1, $2, $3)`
+		_, err = tx.Exec(query, obj.Id, idMap[node.Id], app.JoinNodePath(path))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = updateCopiedSmartQueries(tx, smartQueries, idMap)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if source.Config != nil {
+		config := &app.GroupConfig{NodeOrder: []*valueobject.ID{}}
+		for _, id := range source.Config.NodeOrder {
+			if id == nil {
+				continue
+			}
+			if newId, ok := idMap[*id]; ok {
+				config.NodeOrder = append(config.NodeOrder, &newId)
+			}
+		}
+
+		query = `UPDATE groups SET config=$1 WHERE id=$2`
+		_, err = tx.Exec(query, config, obj.Id)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.Id, nil
+}
+
+// TransferAdmin promotes the member to admin and demotes the former admin
+// to editor within a single transaction.
+func (r *GroupRepo) TransferAdmin(groupId *valueobject.ID, fromId *valueobject.ID, toId *valueobject.ID) error {
+	tx, err := r.db.Db().Begin()
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE user_group SET role=$1 WHERE group_id=$2 AND user_id=$3 AND status=$4 AND role<>$1`
+	res, err := tx.Exec(query, app.UserAdmin, groupId, toId, app.MemberActive)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if affected, err := res.RowsAffected(); err != nil || affected != 1 {
+		tx.Rollback()
+		if err != nil {
+			return err
+		}
+		return errors.New("Admin role can be transferred only to an active member who isn't an admin.")
+	}
+
+	query = `UPDATE user_group SET role=$1 WHERE group_id=$2 AND user_id=$3 AND status=$4 AND role=$5`
+	res, err = tx.Exec(query, app.UserEditor, groupId, fromId, app.MemberActive, app.UserAdmin)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if affected, err := res.RowsAffected(); err != nil || affected != 1 {
+		tx.Rollback()
+		if err != nil {
+			return err
+		}
+		return errors.New("Forbidden, only admin can transfer admin role.")
+	}
+
+	return tx.Commit()
+}
+
+func (r *GroupRepo) CreateLink(link app.GroupLink) (*app.GroupLink, error) {
+	query := `
+		INSERT INTO group_links (group_id, author_id, token, role, max_uses, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	err := r.db.Db().QueryRow(query, link.GroupId, link.AuthorId, link.Token, link.Role, link.MaxUses, link.Status, link.ExpiresAt).
+		Scan(&link.Id, &link.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+func (r *GroupRepo) ListLinks(groupId *valueobject.ID) ([]*app.GroupLink, error) {
+	links := []*app.GroupLink{}
+	query := `
+		SELECT id, group_id, author_id, token, role, max_uses, use_count, status, expires_at, created_at
+		FROM group_links
+		WHERE group_id=$1
+		ORDER BY created_at DESC
+	`
+	err := r.db.Db().Select(&links, query, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func (r *GroupRepo) FindLinkByToken(token string) (*app.GroupLink, error) {
+	link := &app.GroupLink{}
+	query := `
+		SELECT id, group_id, author_id, token, role, max_uses, use_count, status, expires_at, created_at
+		FROM group_links
+		WHERE token=$1
+	`
+	err := r.db.Db().Get(link, query, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (r *GroupRepo) RevokeLink(groupId *valueobject.ID, linkId *valueobject.ID) error {
+	query := `UPDATE group_links SET status=$1 WHERE group_id=$2 AND id=$3`
+
+	_, err := r.db.Db().Exec(query, app.GroupLinkRevoked, groupId, linkId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// JoinByLink consumes one use of the link and makes the user an active member
+// of the link's group. Expired, revoked or used up links are rejected.
+func (r *GroupRepo) JoinByLink(token string, member app.GroupMember) (*valueobject.ID, error) {
+	var groupId *valueobject.ID
+
+	tx, err := r.db.Db().Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE group_links SET use_count=use_count+1
+		WHERE token=$1 AND status=$2 AND expires_at > NOW() AND (max_uses=0 OR use_count < max_uses)
+			AND group_id IN (SELECT id FROM groups WHERE status=$3)
+		RETURNING group_id, role
+	`
+	err = tx.QueryRow(query, token, app.GroupLinkActive, app.GroupActive).
+		Scan(&groupId, &member.Role)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, errors.New("Join link is invalid, expired or used up.")
+		}
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO user_group (user_id, group_id, role, status) VALUES($1, $2, $3, $4)
+		ON CONFLICT (user_id, group_id) DO UPDATE
+		SET role=EXCLUDED.role, status=EXCLUDED.status, token=NULL, token_expires_at=NULL
+	`
+	_, err = tx.Exec(query, member.Id, groupId, member.Role, app.MemberActive)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return groupId, nil
+}
+
+// Backup reads the whole group content, transcriptions are limited to the transcription type of the group.
+func (r *GroupRepo) Backup(groupId *valueobject.ID) (*app.GroupBackup, error) {
+	group, err := r.Get(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &app.GroupBackup{
+		Version:   app.BackupVersion,
+		CreatedAt: time.Now(),
+		Group: &app.BackupGroup{
+			Name:              group.Name,
+			TargetLangCode:    group.TargetLangCode,
+			NativeLangCode:    group.NativeLangCode,
+			TranscriptionType: group.TranscriptionType.Name,
+			Visibility:        group.Visibility,
+			NodeOrder:         []valueobject.ID{},
+		},
+		Nodes:   []*app.BackupNode{},
+		Members: []*app.BackupMember{},
+	}
+
+	if group.Config != nil {
+		for _, id := range group.Config.NodeOrder {
+			if id != nil {
+				backup.Group.NodeOrder = append(backup.Group.NodeOrder, *id)
+			}
+		}
+	}
+
+	query := `
+		SELECT n.id, n.type, n.name, n.visibility, n.expression_order, n.smart_query, gn.path, t.title, t.content, t.created_at FROM group_node gn
+		LEFT JOIN nodes n ON n.id=gn.node_id
+		LEFT JOIN texts t ON t.id=n.text_id
+		WHERE gn.group_id=$1
+		ORDER BY nlevel(gn.path), n.id
+	`
+	rows, err := r.db.Db().Query(query, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[valueobject.ID]*app.BackupNode)
+	for rows.Next() {
+		var path string
+		var title, content sql.NullString
+		var createdAt sql.NullTime
+		node := &app.BackupNode{Expressions: []*app.BackupExpression{}}
+		err = rows.Scan(&node.Key, &node.Type, &node.Name, &node.Visibility, &node.ExpressionOrder, &node.SmartQuery, &path, &title, &content, &createdAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		node.Path = app.SplitNodePath(path)
+		if content.Valid {
+			node.Text = &app.BackupText{Title: title.String, Content: content.String, CreatedAt: createdAt.Time}
+		}
+
+		nodes[node.Key] = node
+		backup.Nodes = append(backup.Nodes, node)
+	}
+	rows.Close()
+
+	type nodeExpression struct {
+		node       *app.BackupNode
+		expression *app.BackupExpression
+	}
+
+	query = `
+		SELECT ne.node_id, e.id, e.value, ne.position, ne.created_at FROM node_expression ne
+		LEFT JOIN expressions e ON e.id=ne.expression_id
+		LEFT JOIN group_node gn ON gn.node_id=ne.node_id
+		WHERE gn.group_id=$1
+		ORDER BY ne.node_id, ne.position NULLS LAST, ne.created_at, e.id
+	`
+	rows, err = r.db.Db().Query(query, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	expressions := make(map[valueobject.ID][]*app.BackupExpression)
+	attached := make(map[[2]valueobject.ID]*app.BackupExpression)
+	for rows.Next() {
+		var nodeId, expressionId valueobject.ID
+		expr := &app.BackupExpression{
+			Transcriptions: []string{},
+			Translations:   []*app.BackupTranslation{},
+		}
+		err = rows.Scan(&nodeId, &expressionId, &expr.Value, &expr.Position, &expr.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		node := nodes[nodeId]
+		node.Expressions = append(node.Expressions, expr)
+		expressions[expressionId] = append(expressions[expressionId], expr)
+		attached[[2]valueobject.ID{nodeId, expressionId}] = expr
+	}
+	rows.Close()
+
+	query = `
+		SELECT et.expression_id, tsc.value FROM expression_transcription et
+		LEFT JOIN transcriptions tsc ON tsc.id=et.transcription_id
+		WHERE tsc.type=$2 AND et.expression_id IN (
+			SELECT ne.expression_id FROM node_expression ne
+			LEFT JOIN group_node gn ON gn.node_id=ne.node_id
+			WHERE gn.group_id=$1
+		)
+		ORDER BY et.expression_id, tsc.id
+	`
+	rows, err = r.db.Db().Query(query, groupId, group.TranscriptionTypeId)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var expressionId valueobject.ID
+		var value string
+		err = rows.Scan(&expressionId, &value)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		for _, expr := range expressions[expressionId] {
+			expr.Transcriptions = append(expr.Transcriptions, value)
+		}
+	}
+	rows.Close()
+
+	query = `
+		SELECT tt.translation_id, tsc.value FROM translation_transcription tt
+		LEFT JOIN transcriptions tsc ON tsc.id=tt.transcription_id
+		WHERE tsc.type=$2 AND tt.translation_id IN (
+			SELECT nt.translation_id FROM node_translation nt
+			LEFT JOIN group_node gn ON gn.node_id=nt.node_id
+			WHERE gn.group_id=$1
+		)
+		ORDER BY tt.translation_id, tsc.id
+	`
+	rows, err = r.db.Db().Query(query, groupId, group.TranscriptionTypeId)
+	if err != nil {
+		return nil, err
+	}
+
+	translationTranscriptions := make(map[valueobject.ID][]string)
+	for rows.Next() {
+		var translationId valueobject.ID
+		var value string
+		err = rows.Scan(&translationId, &value)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		translationTranscriptions[translationId] = append(translationTranscriptions[translationId], value)
+	}
+	rows.Close()
+
+	query = `
+		SELECT nt.node_id, t.id, t.target_id, e.value, coalesce(t.comment, '') FROM node_translation nt
+		LEFT JOIN translations t ON t.id=nt.translation_id
+		LEFT JOIN expressions e ON e.id=t.native_id
+		LEFT JOIN group_node gn ON gn.node_id=nt.node_id
+		WHERE gn.group_id=$1
+		ORDER BY nt.node_id, nt.created_at, t.id
+	`
+	rows, err = r.db.Db().Query(query, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var nodeId, translationId, targetId valueobject.ID
+		tr := &app.BackupTranslation{}
+		err = rows.Scan(&nodeId, &translationId, &targetId, &tr.Value, &tr.Comment)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		// Translations are kept only together with their expressions.
+		expr, ok := attached[[2]valueobject.ID{nodeId, targetId}]
+		if !ok {
+			continue
+		}
+
+		tr.Transcriptions = translationTranscriptions[translationId]
+		if tr.Transcriptions == nil {
+			tr.Transcriptions = []string{}
+		}
+		expr.Translations = append(expr.Translations, tr)
+	}
+	rows.Close()
+
+	query = `
+		SELECT u.username, u.email, ug.role, ug.status FROM user_group ug
+		LEFT JOIN users u ON u.id=ug.user_id
+		WHERE ug.group_id=$1 AND ug.status<>$2
+		ORDER BY u.id
+	`
+	rows, err = r.db.Db().Query(query, groupId, app.MemberDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		member := &app.BackupMember{}
+		err = rows.Scan(&member.Username, &member.Email, &member.Role, &member.Status)
+		if err != nil {
+			return nil, err
+		}
+
+		backup.Members = append(backup.Members, member)
+	}
+
+	return backup, nil
+}
+
+// RestoreBackup recreates the archived group as a new private group owned by the user.
+// Nodes get new ids, expressions, translations and transcriptions are reused when they
+// already exist on this instance. Members are not restored here.
+func (r *GroupRepo) RestoreBackup(userId *valueobject.ID, backup *app.GroupBackup, name string) (*app.RestoreReport, error) {
+	var transcriptionTypeId *valueobject.ID
+
+	query := `SELECT id FROM transcription_types WHERE lang=$1 AND name=$2`
+	err := r.db.Db().QueryRow(query, backup.Group.TargetLangCode, backup.Group.TranscriptionType).
+		Scan(&transcriptionTypeId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("Transcription type \"%s\" doesn't exist on this instance.", backup.Group.TranscriptionType)
+		}
+		return nil, err
+	}
+
+	group := &app.Group{
+		Name:                name,
+		TranscriptionTypeId: transcriptionTypeId,
+		TargetLangCode:      backup.Group.TargetLangCode,
+		NativeLangCode:      backup.Group.NativeLangCode,
+	}
+	report := &app.RestoreReport{Invited: []string{}, Skipped: []string{}}
+
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO groups (name, transcription_type, target_lang, native_lang, status) 
+		VALUES($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err = tx.QueryRow(query, group.Name, group.TranscriptionTypeId, group.TargetLangCode, group.NativeLangCode, app.GroupActive).
+		Scan(&group.Id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	report.GroupId = group.Id
+
+	query = `INSERT INTO user_group (user_id, group_id, role, status) VALUES($1, $2, $3, $4)`
+	_, err = tx.Exec(query, userId, group.Id, app.UserAdmin, app.MemberActive)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Ancestors go first so that their new ids are known when paths are remapped.
+	nodes := make([]*app.BackupNode, len(backup.Nodes))
+	copy(nodes, backup.Nodes)
+	sort.SliceStable(nodes, func(a, b int) bool {
+		return len(nodes[a].Path) < len(nodes[b].Path)
+	})
+
+	idMap := make(map[valueobject.ID]valueobject.ID)
+	smartQueries := make(map[valueobject.ID]*app.SmartQuery)
+	for _, node := range nodes {
+		var newId valueobject.ID
+		var textId *valueobject.ID
+
+		if _, ok := idMap[node.Key]; ok {
+			tx.Rollback()
+			return nil, fmt.Errorf("Node %d appears in the archive more than once.", node.Key)
+		}
+
+		path := []valueobject.ID{}
+		for _, ancestorKey := range node.Path {
+			ancestorId, ok := idMap[ancestorKey]
+			if !ok {
+				tx.Rollback()
+				return nil, fmt.Errorf("Ancestor %d of node %d is missing in the archive.", ancestorKey, node.Key)
+			}
+			path = append(path, ancestorId)
+		}
+
+		if node.Text != nil {
+			query = `INSERT INTO texts (author_id, title, content, lang) VALUES($1, $2, $3, $4) RETURNING id`
+			err = tx.QueryRow(query, userId, node.Text.Title, node.Text.Content, group.TargetLangCode).
+				Scan(&textId)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			report.Texts++
+		}
+
+		if node.ExpressionOrder == "" {
+			node.ExpressionOrder = app.ExpressionOrderCreated
+		}
+
+		// Queries of the restored group are owned by the restoring user.
+		if node.SmartQuery != nil {
+			node.SmartQuery.OwnerId = userId
+		}
+
+		// Restored nodes are private whatever the archive says, publishing is up to the new group.
+		query = `INSERT INTO nodes (type, name, visibility, text_id, expression_order, smart_query) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+		err = tx.QueryRow(query, node.Type, node.Name, app.NodePrivate, textId, node.ExpressionOrder, node.SmartQuery).
+			Scan(&newId)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		idMap[node.Key] = newId
+		if node.SmartQuery != nil {
+			smartQueries[newId] = node.SmartQuery
+		}
+		report.Nodes++
+
+		query = `INSERT INTO group_node (group_id, node_id, path) VALUES ($1, $2, $3)`
+		_, err = tx.Exec(query, group.Id, newId, app.JoinNodePath(path))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		for _, expr := range node.Expressions {
+			err = restoreExpression(tx, group, &newId, expr)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			report.Expressions++
+		}
+
+		_, err = insertRevision(tx, &newId, userId, app.ActivityGroupRestoreBackup)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = updateCopiedSmartQueries(tx, smartQueries, idMap)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	nodeOrder := []*valueobject.ID{}
+	for _, key := range backup.Group.NodeOrder {
+		if newId, ok := idMap[key]; ok {
+			newId := newId
+			nodeOrder = append(nodeOrder, &newId)
+		}
+	}
+
+	err = updateNodeOrder(tx, group.Id, nodeOrder)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func restoreExpression(tx *sqlx.Tx, group *app.Group, nodeId *valueobject.ID, expr *app.BackupExpression) error {
+	expressionId, _, err := findOrCreateExpression(tx, group.TargetLangCode, expr.Value)
+	if err != nil {
+		return err
+	}
+
+	createdAt := expr.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err = execLinked(tx, `
+		INSERT INTO node_expression (node_id, expression_id, position, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (node_id, expression_id) DO NOTHING
+	`, nodeId, expressionId, expr.Position, createdAt)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range expr.Transcriptions {
+		transcriptionId, err := findOrCreateTranscription(tx, group.TranscriptionTypeId, value)
+		if err != nil {
+			return err
+		}
+
+		_, err = execLinked(tx, `
+			INSERT INTO expression_transcription (expression_id, transcription_id) VALUES ($1, $2)
+			ON CONFLICT (expression_id, transcription_id) DO NOTHING
+		`, expressionId, transcriptionId)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, tr := range expr.Translations {
+		nativeId, _, err := findOrCreateExpression(tx, group.NativeLangCode, tr.Value)
+		if err != nil {
+			return err
+		}
+
+		translationId, err := findOrCreateTranslation(tx, expressionId, nativeId, tr.Comment)
+		if err != nil {
+			return err
+		}
+
+		_, err = execLinked(tx, `
+			INSERT INTO node_translation (node_id, translation_id) VALUES ($1, $2)
+			ON CONFLICT (node_id, translation_id) DO NOTHING
+		`, nodeId, translationId)
+		if err != nil {
+			return err
+		}
+
+		for _, value := range tr.Transcriptions {
+			transcriptionId, err := findOrCreateTranscription(tx, group.TranscriptionTypeId, value)
+			if err != nil {
+				return err
+			}
+
+			_, err = execLinked(tx, `
+				INSERT INTO translation_transcription (translation_id, transcription_id) VALUES ($1, $2)
+				ON CONFLICT (translation_id, transcription_id) DO NOTHING
+			`, translationId, transcriptionId)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
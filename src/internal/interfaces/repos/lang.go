@@ -2,6 +2,7 @@ package repos
 
 import (
 	"github.com/alexkarpovich/lst-api/src/internal/domain"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
 	"github.com/alexkarpovich/lst-api/src/internal/interfaces/db"
 )
 
@@ -38,3 +39,15 @@ func (r *LangRepo) ListTranscriptionTypes(langCode string) ([]*domain.Transcript
 
 	return transcriptionTypes, nil
 }
+
+func (r *LangRepo) GetTranscriptionType(typeId *valueobject.ID) (*domain.TranscriptionType, error) {
+	transcriptionType := &domain.TranscriptionType{}
+	query := `SELECT id, name, lang FROM transcription_types WHERE id=$1`
+
+	err := r.db.Db().Get(transcriptionType, query, typeId)
+	if err != nil {
+		return nil, err
+	}
+
+	return transcriptionType, nil
+}
@@ -2,14 +2,58 @@ package repos
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 
 	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain"
 	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
 	"github.com/alexkarpovich/lst-api/src/internal/interfaces/db"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// readableNodesQuery selects nodes which are visible to the user, either
+// through active membership or because the group or one of the node
+// ancestors is published to the catalog.
+const readableNodesQuery = `
+	SELECT gn.node_id FROM group_node gn
+	LEFT JOIN groups g ON g.id=gn.group_id
+	WHERE gn.node_id IN (?) AND g.status=? AND (
+		g.visibility=?
+		OR EXISTS (
+			SELECT 1 FROM user_group ug
+			WHERE ug.group_id=gn.group_id AND ug.user_id=? AND ug.status=?
+		)
+		OR EXISTS (
+			SELECT 1 FROM group_node pgn
+			LEFT JOIN nodes pn ON pn.id=pgn.node_id
+			WHERE pgn.group_id=gn.group_id AND pn.visibility=?
+				AND (pgn.node_id=gn.node_id OR gn.path ~ ('*.' || pgn.node_id || '.*')::lquery)
+		)
+	)
+`
+
+func selectReadableNodeIds(db db.DB, userId *valueobject.ID, nodeIds []valueobject.ID) ([]valueobject.ID, error) {
+	ids := []valueobject.ID{}
+	if len(nodeIds) == 0 {
+		return ids, nil
+	}
+
+	query, args, err := sqlx.In(readableNodesQuery, nodeIds, app.GroupActive, app.GroupPublic, userId, app.MemberActive, app.NodePublic)
+	if err != nil {
+		return nil, err
+	}
+	query = db.Db().Rebind(query)
+
+	err = db.Db().Select(&ids, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
 type NodeRepo struct {
 	db db.DB
 }
@@ -18,25 +62,38 @@ func NewNodeRepo(db db.DB) *NodeRepo {
 	return &NodeRepo{db}
 }
 
-func (r *NodeRepo) Create(groupId *valueobject.ID, obj app.Node) (*app.Node, error) {
-	var query string
-
+func (r *NodeRepo) Create(groupId *valueobject.ID, obj app.Node, actorId *valueobject.ID) (*app.Node, error) {
 	tx, err := r.db.Db().Beginx()
 	if err != nil {
 		return nil, err
 	}
 
-	query = `
-		INSERT INTO nodes (type, name, visibility) 
-		VALUES(:type, :name, :visibility)
+	err = insertNode(tx, groupId, &obj, actorId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &obj, nil
+}
+
+// insertNode adds the node to the group under its path and records the first revision.
+func insertNode(tx *sqlx.Tx, groupId *valueobject.ID, obj *app.Node, actorId *valueobject.ID) error {
+	query := `
+		INSERT INTO nodes (type, name, visibility, smart_query) 
+		VALUES(:type, :name, :visibility, :smart_query)
 		RETURNING id
 	`
 
 	rows, err := tx.NamedQuery(query, obj)
-
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return err
 	}
 
 	if rows.Next() {
@@ -50,17 +107,12 @@ func (r *NodeRepo) Create(groupId *valueobject.ID, obj app.Node) (*app.Node, err
 	query = `INSERT INTO group_node (group_id, node_id, path) VALUES ($1, $2, $3)`
 	_, err = tx.Exec(query, groupId, obj.Id, obj.Path)
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return err
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+	_, err = insertRevision(tx, obj.Id, actorId, app.ActivityNodeCreate)
 
-	return &obj, nil
+	return err
 }
 
 func (r *NodeRepo) Get(nodeId *valueobject.ID) (*app.Node, error) {
@@ -109,11 +161,11 @@ func (r *NodeRepo) Get(nodeId *valueobject.ID) (*app.Node, error) {
 	expressions := []*app.Expression{}
 
 	query = `
-		SELECT e.id, e.value, ne.created_at FROM expressions e
+		SELECT e.id, e.value, MAX(ne.created_at) created_at FROM expressions e
 		LEFT JOIN node_expression ne ON ne.expression_id=e.id
 		WHERE ne.node_id=$1
-		ORDER BY ne.created_at DESC;
-	`
+		GROUP BY e.id
+		ORDER BY ` + expressionOrderBy(node.ExpressionOrder)
 
 	rowsx, err := r.db.Db().Queryx(query, nodeId)
 	if err != nil {
@@ -136,23 +188,48 @@ func (r *NodeRepo) Get(nodeId *valueobject.ID) (*app.Node, error) {
 	return &node, nil
 }
 
-func (r *NodeRepo) View(ids []valueobject.ID) (*app.NodeView, error) {
+// expressionOrderBy returns ORDER BY clause for expressions grouped by id and joined with node_expression as ne.
+func expressionOrderBy(order app.ExpressionOrder) string {
+	switch order {
+	case app.ExpressionOrderManual:
+		return `MIN(ne.position) NULLS LAST, MIN(ne.created_at), e.id`
+	case app.ExpressionOrderAlpha:
+		return `e.value, e.id`
+	case app.ExpressionOrderDifficulty:
+		return `(
+			SELECT COUNT(*) FROM training_failures tf
+			JOIN translations t ON t.id=tf.translation_id
+			WHERE t.type=(SELECT id FROM object_types WHERE name='expression') AND t.target_id=e.id
+		) DESC, e.value, e.id`
+	}
+
+	return `MAX(ne.created_at) DESC, e.id DESC`
+}
+
+// View lists expressions of the nodes with their translations and transcriptions,
+// contents of smart nodes are computed for the viewer.
+func (r *NodeRepo) View(ids []valueobject.ID, order app.ExpressionOrder, viewerId *valueobject.ID) (*app.NodeView, error) {
 	var err error
 	var query string
 	var nodeView app.NodeView
 	var tmpId valueobject.ID
 
+	// A single node is listed in its configured order unless another one is asked.
+	if order == "" && len(ids) == 1 {
+		err = r.db.Db().QueryRow(`SELECT expression_order FROM nodes WHERE id=$1`, ids[0]).Scan(&order)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
 	query = `
 		SELECT t.id, tsc.id, tsc.value FROM transcriptions tsc
 		LEFT JOIN translation_transcription tt ON tt.transcription_id=tsc.id
-		LEFT JOIN translations t ON t.id=tt.translation_id
-		LEFT JOIN node_expression ne ON ne.expression_id=t.target_id
-		WHERE ne.node_id IN (?)
+		JOIN translations t ON t.id=tt.translation_id
+		JOIN node_expression_links($1, $2) ne ON ne.expression_id=t.target_id
 		GROUP BY t.id, tsc.id	
 	`
-	query, args, err := sqlx.In(query, ids)
-	query = r.db.Db().Rebind(query)
-	rows, err := r.db.Db().Query(query, args...)
+	rows, err := r.db.Db().Query(query, pq.Array(ids), viewerId)
 	if err != nil {
 		return nil, err
 	}
@@ -168,15 +245,12 @@ func (r *NodeRepo) View(ids []valueobject.ID) (*app.NodeView, error) {
 
 	query = `
 		SELECT t.id, t.target_id, e.value, t.comment, MAX(nt.created_at) created_at FROM translations t
-		LEFT JOIN node_translation nt ON nt.translation_id=t.id
+		JOIN node_translation_links($1, $2) nt ON nt.translation_id=t.id
 		LEFT JOIN expressions e ON e.id=t.native_id
-		WHERE nt.node_id IN (?)
 		GROUP BY t.id, e.value
 		ORDER BY created_at DESC
 	`
-	query, args, err = sqlx.In(query, ids)
-	query = r.db.Db().Rebind(query)
-	rows, err = r.db.Db().Query(query, args...)
+	rows, err = r.db.Db().Query(query, pq.Array(ids), viewerId)
 	if err != nil {
 		return nil, err
 	}
@@ -200,14 +274,11 @@ func (r *NodeRepo) View(ids []valueobject.ID) (*app.NodeView, error) {
 
 	query = `
 		SELECT et.expression_id, tsc.id, tsc.value FROM transcriptions tsc
-		LEFT JOIN expression_transcription et ON et.transcription_id=tsc.id
-		LEFT JOIN node_expression ne ON ne.expression_id=et.expression_id
-		WHERE ne.node_id IN (?)
+		JOIN expression_transcription et ON et.transcription_id=tsc.id
+		JOIN node_expression_links($1, $2) ne ON ne.expression_id=et.expression_id
 		GROUP BY et.expression_id, tsc.id	
 	`
-	query, args, err = sqlx.In(query, ids)
-	query = r.db.Db().Rebind(query)
-	rows, err = r.db.Db().Query(query, args...)
+	rows, err = r.db.Db().Query(query, pq.Array(ids), viewerId)
 	if err != nil {
 		return nil, err
 	}
@@ -229,15 +300,11 @@ func (r *NodeRepo) View(ids []valueobject.ID) (*app.NodeView, error) {
 
 	query = `
 		SELECT e.id, e.value, MAX(ne.created_at) created_at FROM expressions e
-		LEFT JOIN node_expression ne ON ne.expression_id=e.id
-		WHERE ne.node_id IN (?)
+		JOIN node_expression_links($1, $2) ne ON ne.expression_id=e.id
 		GROUP BY e.id
-		ORDER BY created_at DESC		
-	`
+		ORDER BY ` + expressionOrderBy(order)
 
-	query, args, err = sqlx.In(query, ids)
-	query = r.db.Db().Rebind(query)
-	rows, err = r.db.Db().Query(query, args...)
+	rows, err = r.db.Db().Query(query, pq.Array(ids), viewerId)
 	if err != nil {
 		return nil, err
 	}
@@ -260,27 +327,34 @@ func (r *NodeRepo) View(ids []valueobject.ID) (*app.NodeView, error) {
 	nodeView.Expressions = expressions
 
 	if len(ids) == 1 {
-		text := &app.Text{}
-		query = `
-			SELECT id, author_id, content, created_at FROM texts
-			WHERE id=(SELECT text_id FROM nodes WHERE id=$1)
-		`
-		err := r.db.Db().QueryRow(query, ids[0]).
-			Scan(&text.Id, &text.AuthorId, &text.Content, &text.CreatedAt)
+		nodeView.Text, err = r.GetText(&ids[0])
 		if err != nil {
-			if err != sql.ErrNoRows {
-				return nil, err
-			}
-
-			nodeView.Text = nil
-		} else {
-			nodeView.Text = text
+			return nil, err
 		}
 	}
 
 	return &nodeView, nil
 }
 
+// GetText returns the text attached to the node or nil when there is none.
+func (r *NodeRepo) GetText(nodeId *valueobject.ID) (*app.Text, error) {
+	text := &app.Text{}
+	query := `
+		SELECT id, author_id, content, created_at FROM texts
+		WHERE id=(SELECT text_id FROM nodes WHERE id=$1)
+	`
+	err := r.db.Db().QueryRow(query, nodeId).
+		Scan(&text.Id, &text.AuthorId, &text.Content, &text.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return text, nil
+}
+
 func (r *NodeRepo) GetGroupByNode(nodeId *valueobject.ID) (*app.Group, error) {
 	var group app.Group
 	query := `
@@ -300,10 +374,13 @@ func (r *NodeRepo) List(groupId *valueobject.ID) ([]*app.FlatNode, error) {
 	nodes := []*app.FlatNode{}
 
 	err := r.db.Db().Select(&nodes, `
+		WITH links AS (
+			SELECT * FROM node_expression_links(ARRAY(SELECT node_id FROM group_node WHERE group_id=$1), NULL)
+		)
 		SELECT n.id, n.type, n.name, n.visibility, (
-			SELECT COUNT(DISTINCT expression_id) FROM node_expression ne 
+			SELECT COUNT(DISTINCT expression_id) FROM links ne 
 			LEFT JOIN group_node cgn ON cgn.node_id=ne.node_id 
-			WHERE cgn.group_id=$1 AND (n.type=0 AND index(cgn.path, CASE WHEN gn.path='' THEN concat(gn.node_id) ELSE concat(gn.path,'.',gn.node_id) END::ltree) <> -1) OR (n.type=1 AND ne.node_id=n.id)
+			WHERE cgn.group_id=$1 AND (n.type=0 AND index(cgn.path, CASE WHEN gn.path='' THEN concat(gn.node_id) ELSE concat(gn.path,'.',gn.node_id) END::ltree) <> -1) OR (n.type IN (1,2) AND ne.node_id=n.id)
 			) as count, 
 			gn.path as path FROM nodes n
 		LEFT JOIN group_node gn ON gn.node_id=n.id
@@ -321,13 +398,60 @@ func (r *NodeRepo) List(groupId *valueobject.ID) ([]*app.FlatNode, error) {
 	return nodes, nil
 }
 
+// ListTree returns group nodes ordered according to the group config together with
+// expressions, translations and user's due training items counted over each subtree.
+// Smart nodes are counted with their contents computed for the user.
+// When rootId is given only the root and its descendants are returned.
+func (r *NodeRepo) ListTree(groupId *valueobject.ID, userId *valueobject.ID, rootId *valueobject.ID) ([]*app.TreeNode, error) {
+	nodes := []*app.TreeNode{}
+
+	err := r.db.Db().Select(&nodes, `
+		WITH tree AS (
+			SELECT node_id, path, path || node_id::text AS child_path FROM group_node WHERE group_id=$1
+		), subtree AS (
+			SELECT t.node_id AS root_id, s.node_id FROM tree t
+			LEFT JOIN tree s ON s.node_id=t.node_id OR s.path <@ t.child_path
+		), expression_links AS (
+			SELECT * FROM node_expression_links(ARRAY(SELECT node_id FROM tree), $2)
+		), translation_links AS (
+			SELECT * FROM node_translation_links(ARRAY(SELECT node_id FROM tree), $2)
+		)
+		SELECT n.id, n.type, n.name, n.visibility, t.path::text AS path, (
+				SELECT COUNT(DISTINCT ne.expression_id) FROM subtree st
+				LEFT JOIN expression_links ne ON ne.node_id=st.node_id
+				WHERE st.root_id=n.id
+			) AS count, (
+				SELECT COUNT(DISTINCT nt.translation_id) FROM subtree st
+				LEFT JOIN translation_links nt ON nt.node_id=st.node_id
+				WHERE st.root_id=n.id
+			) AS translation_count, (
+				SELECT COUNT(DISTINCT ti.translation_id) FROM subtree st
+				LEFT JOIN translation_links nt ON nt.node_id=st.node_id
+				LEFT JOIN training_items ti ON ti.translation_id=nt.translation_id
+				LEFT JOIN trainings tr ON tr.id=ti.training_id
+				WHERE st.root_id=n.id AND tr.owner_id=$2 AND ti.complete=FALSE
+			) AS due_count
+		FROM tree t
+		LEFT JOIN nodes n ON n.id=t.node_id
+		LEFT JOIN groups g ON g.id=$1
+		LEFT JOIN jsonb_array_elements(g.config->'nodeOrder') WITH ORDINALITY AS arr(nid, idx) ON arr.nid::int=t.node_id
+		WHERE $3::int IS NULL OR t.node_id=$3 OR t.path ~ ('*.' || $3 || '.*')::lquery
+		ORDER BY arr.idx NULLS LAST, n.id
+	`, groupId, userId, rootId)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
 func (r *NodeRepo) FilterSliceIds(sliceIds []valueobject.ID) ([]valueobject.ID, error) {
 	var query string
 	var err error
 
 	ids := []valueobject.ID{}
-	query = `SELECT id FROM nodes WHERE type=? AND id in (?)`
-	query, args, err := sqlx.In(query, app.NodeSlice, sliceIds)
+	query = `SELECT id FROM nodes WHERE type IN (?) AND id in (?)`
+	query, args, err := sqlx.In(query, []app.NodeType{app.NodeSlice, app.NodeSmart}, sliceIds)
 	query = r.db.Db().Rebind(query)
 	err = r.db.Db().Select(&ids, query, args...)
 	if err != nil {
@@ -337,23 +461,39 @@ func (r *NodeRepo) FilterSliceIds(sliceIds []valueobject.ID) ([]valueobject.ID,
 	return ids, nil
 }
 
-func (r *NodeRepo) Update(obj app.FlatNode) error {
+func (r *NodeRepo) FilterReadableIds(userId *valueobject.ID, nodeIds []valueobject.ID) ([]valueobject.ID, error) {
+	return selectReadableNodeIds(r.db, userId, nodeIds)
+}
+
+func (r *NodeRepo) Update(obj app.FlatNode, actorId *valueobject.ID) error {
 	var query string
 
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return err
+	}
+
 	query = `
 		UPDATE nodes SET name=:name, visibility=:visibility
 		WHERE id=:id
 	`
 
-	_, err := r.db.Db().NamedExec(query, obj)
+	_, err = tx.NamedExec(query, obj)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	return nil
+	_, err = insertRevision(tx, obj.Id, actorId, app.ActivityNodeUpdate)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *NodeRepo) AttachExpression(nodeId *valueobject.ID, expression app.Expression) (*app.Expression, error) {
+func (r *NodeRepo) AttachExpression(nodeId *valueobject.ID, expression app.Expression, actorId *valueobject.ID) (*app.Expression, error) {
 	var err error
 	var query string
 
@@ -362,29 +502,16 @@ func (r *NodeRepo) AttachExpression(nodeId *valueobject.ID, expression app.Expre
 		return nil, err
 	}
 
-	tx, err := r.db.Db().Begin()
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
 
 	if expression.Id == nil {
-		query = `SELECT id FROM expressions WHERE value=$1 AND lang=$2`
-		err = tx.QueryRow(query, expression.Value, group.TargetLangCode).
-			Scan(&expression.Id)
+		expression.Id, _, err = findOrCreateExpression(tx, group.TargetLangCode, expression.Value)
 		if err != nil {
-			if err != sql.ErrNoRows {
-				return nil, err
-			}
-		}
-
-		if expression.Id == nil {
-			query = `
-				INSERT INTO expressions (value, lang) VALUES($1, $2)
-				RETURNING id
-			`
-			err = tx.QueryRow(query, expression.Value, group.TargetLangCode).
-				Scan(&expression.Id)
-			if err != nil {
-				tx.Rollback()
-				return nil, err
-			}
+			tx.Rollback()
+			return nil, err
 		}
 	}
 
@@ -407,15 +534,24 @@ func (r *NodeRepo) AttachExpression(nodeId *valueobject.ID, expression app.Expre
 		return nil, err
 	}
 
-	tx.Commit()
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityExpressionAttach)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
 
 	return &expression, nil
 }
 
-func (r *NodeRepo) DetachExpression(nodeId *valueobject.ID, expressionId *valueobject.ID) error {
+func (r *NodeRepo) DetachExpression(nodeId *valueobject.ID, expressionId *valueobject.ID, actorId *valueobject.ID) error {
 	var query string
 
-	tx, err := r.db.Db().Begin()
+	tx, err := r.db.Db().Beginx()
 	if err != nil {
 		return err
 	}
@@ -439,25 +575,27 @@ func (r *NodeRepo) DetachExpression(nodeId *valueobject.ID, expressionId *valueo
 		return err
 	}
 
-	tx.Commit()
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityExpressionDetach)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (r *NodeRepo) TranslationsBySlices(sliceIds []valueobject.ID) ([]*app.Translation, error) {
-	var query string
-	var err error
-
+// TranslationsBySlices lists translations of the slices, contents of smart slices are computed for the viewer.
+func (r *NodeRepo) TranslationsBySlices(sliceIds []valueobject.ID, viewerId *valueobject.ID) ([]*app.Translation, error) {
 	translations := []*app.Translation{}
-	query = `
+	query := `
 		SELECT t.id, e.value, t.comment FROM translations t
 		LEFT JOIN expressions e ON e.id=t.native_id
-		LEFT JOIN node_translation nt ON nt.translation_id=t.id
-		WHERE nt.node_id IN (?)
+		JOIN node_translation_links($1, $2) nt ON nt.translation_id=t.id
+		LEFT JOIN nodes n ON n.id=nt.node_id
+		LEFT JOIN node_expression_links($1, $2) ne ON ne.node_id=nt.node_id AND ne.expression_id=t.target_id
+		ORDER BY nt.node_id, CASE WHEN n.expression_order='manual' THEN ne.position END NULLS LAST, ne.created_at, t.id
 	`
-	query, args, err := sqlx.In(query, sliceIds)
-	query = r.db.Db().Rebind(query)
-	err = r.db.Db().Select(&translations, query, args...)
+	err := r.db.Db().Select(&translations, query, pq.Array(sliceIds), viewerId)
 	if err != nil {
 		return nil, err
 	}
@@ -465,6 +603,69 @@ func (r *NodeRepo) TranslationsBySlices(sliceIds []valueobject.ID) ([]*app.Trans
 	return translations, nil
 }
 
+// HasManualOrder tells whether any of the slices is configured to keep the manual expression order.
+func (r *NodeRepo) HasManualOrder(sliceIds []valueobject.ID) (bool, error) {
+	var count int
+
+	query, args, err := sqlx.In(`SELECT COUNT(id) FROM nodes WHERE id IN (?) AND expression_order=?`, sliceIds, app.ExpressionOrderManual)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.db.Db().QueryRow(r.db.Db().Rebind(query), args...).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// ReorderExpressions configures the expression order of the node. Given expressions take the
+// first positions in the given order, the rest keep their manual order after them.
+func (r *NodeRepo) ReorderExpressions(nodeId *valueobject.ID, order app.ExpressionOrder, expressionIds []valueobject.ID, actorId *valueobject.ID) error {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE nodes SET expression_order=$1 WHERE id=$2`, order, nodeId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(expressionIds) > 0 {
+		query := `
+			UPDATE node_expression ne SET position=o.position
+			FROM (
+				SELECT expression_id, ROW_NUMBER() OVER (
+					ORDER BY array_position($2::int[], expression_id) NULLS LAST, position NULLS LAST, created_at, expression_id
+				) AS position
+				FROM node_expression WHERE node_id=$1
+			) o
+			WHERE ne.node_id=$1 AND ne.expression_id=o.expression_id
+		`
+		_, err = tx.Exec(query, nodeId, pq.Array(expressionIds))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityNodeUpdate)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *NodeRepo) AvailableTranslations(nodeId *valueobject.ID, expressionId *valueobject.ID) ([]*app.Translation, error) {
 	var query string
 	var err error
@@ -493,7 +694,7 @@ func (r *NodeRepo) AvailableTranslations(nodeId *valueobject.ID, expressionId *v
 	return translations, nil
 }
 
-func (r *NodeRepo) AttachTranslation(nodeId *valueobject.ID, expressionId *valueobject.ID, translation app.Translation) (*app.Translation, error) {
+func (r *NodeRepo) AttachTranslation(nodeId *valueobject.ID, expressionId *valueobject.ID, translation app.Translation, actorId *valueobject.ID) (*app.Translation, error) {
 	var query string
 	var err error
 
@@ -502,28 +703,16 @@ func (r *NodeRepo) AttachTranslation(nodeId *valueobject.ID, expressionId *value
 		return nil, err
 	}
 
-	tx, err := r.db.Db().Begin()
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
 
 	if translation.Id == nil {
-		var nativeId *valueobject.ID = nil
-
-		query = `SELECT id FROM expressions WHERE lang=$1 AND value=$2`
-		err = tx.QueryRow(query, group.NativeLangCode, translation.Value).
-			Scan(&nativeId)
+		nativeId, _, err := findOrCreateExpression(tx, group.NativeLangCode, translation.Value)
 		if err != nil {
-			if err != sql.ErrNoRows {
-				return nil, err
-			}
-		}
-
-		if nativeId == nil {
-			query = `INSERT INTO expressions (lang, value) VALUES($1, $2) RETURNING id`
-			err := tx.QueryRow(query, group.NativeLangCode, translation.Value).
-				Scan(&nativeId)
-			if err != nil {
-				tx.Rollback()
-				return nil, err
-			}
+			tx.Rollback()
+			return nil, err
 		}
 
 		query = `
@@ -575,66 +764,945 @@ func (r *NodeRepo) AttachTranslation(nodeId *valueobject.ID, expressionId *value
 	err = tx.QueryRow(query, translation.Id).
 		Scan(&translation.Value, &translation.Comment)
 	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityTranslationAttach)
+	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	tx.Commit()
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
 
 	return &translation, nil
 }
 
-func (r *NodeRepo) DetachTranslation(nodeId *valueobject.ID, translationId *valueobject.ID) error {
-	query := `DELETE FROM node_translation WHERE node_id=$1 AND translation_id=$2`
-	_, err := r.db.Db().Exec(query, nodeId, translationId)
+// Copy duplicates the node with its subtree into the group under the given path.
+// Like attaching, copies share expressions, translations and texts with the source.
+func (r *NodeRepo) Copy(nodeId *valueobject.ID, groupId *valueobject.ID, path string, actorId *valueobject.ID) (*valueobject.ID, error) {
+	tx, err := r.db.Db().Beginx()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
-}
+	var sourceGroupId valueobject.ID
+	var sourcePath string
+	var sourceConfig *app.GroupConfig
 
-func (r *NodeRepo) AttachText(nodeId *valueobject.ID, text app.Text) (*app.Text, error) {
-	var err error
-	var query string
+	query := `
+		SELECT gn.group_id, gn.path::text, g.config FROM group_node gn
+		JOIN groups g ON g.id=gn.group_id
+		WHERE gn.node_id=$1
+		FOR SHARE
+	`
+	err = tx.QueryRow(query, nodeId).
+		Scan(&sourceGroupId, &sourcePath, &sourceConfig)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
-	group, err := r.GetGroupByNode(nodeId)
+	nodes := []*app.Node{}
+	query = `
+		SELECT n.id, n.type, n.name, n.visibility, n.text_id, n.expression_order, n.smart_query, gn.path::text AS path FROM group_node gn
+		LEFT JOIN nodes n ON n.id=gn.node_id
+		WHERE gn.group_id=$1 AND (gn.node_id=$2 OR gn.path <@ $3::ltree)
+		ORDER BY nlevel(gn.path)
+	`
+	err = tx.Select(&nodes, query, sourceGroupId, nodeId, app.FlatNode{Id: nodeId, Path: sourcePath}.ChildPath())
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	tx, err := r.db.Db().Begin()
+	sourceOrder := []*valueobject.ID{}
+	if sourceConfig != nil {
+		sourceOrder = sourceConfig.NodeOrder
+	}
 
-	if text.Id == nil {
+	rootLevel := len(app.SplitNodePath(sourcePath))
+	targetPath := app.SplitNodePath(path)
+	idMap := make(map[valueobject.ID]valueobject.ID)
+	smartQueries := make(map[valueobject.ID]*app.SmartQuery)
+
+	for _, node := range nodes {
+		var newId valueobject.ID
 		query = `
-			INSERT INTO texts (author_id, title, content, lang) VALUES($1, $2, $3, $4)
-			RETURNING id, created_at
+			INSERT INTO nodes (type, name, visibility, text_id, expression_order, smart_query) VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
 		`
-		err = tx.QueryRow(query, text.AuthorId, text.Title, text.Content, group.TargetLangCode).
-			Scan(&text.Id, &text.CreatedAt)
+		err = tx.QueryRow(query, node.Type, node.Name, app.NodePrivate, node.TextId, node.ExpressionOrder, node.SmartQuery).
+			Scan(&newId)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
-	}
-
-	query = `UPDATE nodes SET text_id=$1 WHERE id=$2`
-	_, err = tx.Exec(query, text.Id, nodeId)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+		idMap[*node.Id] = newId
 
-	tx.Commit()
+		// Queries of the copy are owned by its creator.
+		if node.SmartQuery != nil {
+			smartQuery := *node.SmartQuery
+			smartQuery.OwnerId = actorId
+			smartQueries[newId] = &smartQuery
+		}
 
-	return &text, nil
-}
+		query = `
+			INSERT INTO node_expression (node_id, expression_id, created_at, position)
+			SELECT $1, expression_id, created_at, position FROM node_expression WHERE node_id=$2
+		`
+		_, err = tx.Exec(query, newId, node.Id)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 
-func (r *NodeRepo) DetachText(nodeId *valueobject.ID) error {
-	query := `UPDATE nodes SET text_id=NULL WHERE id=$1`
-	_, err := r.db.Db().Exec(query, nodeId)
-	if err != nil {
+		query = `
+			INSERT INTO node_translation (node_id, translation_id, created_at)
+			SELECT $1, translation_id, created_at FROM node_translation WHERE node_id=$2
+		`
+		_, err = tx.Exec(query, newId, node.Id)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		_, err = insertRevision(tx, &newId, actorId, app.ActivityNodeCopy)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		// Ancestors above the copied node are replaced with the target path.
+		newPath := append([]valueobject.ID{}, targetPath...)
+		for _, ancestorId := range app.SplitNodePath(node.Path)[rootLevel:] {
+			newPath = append(newPath, idMap[ancestorId])
+		}
+
+		query = `INSERT INTO group_node (group_id, node_id, path) VALUES ($1, $2, $3)`
+		_, err = tx.Exec(query, groupId, newId, app.JoinNodePath(newPath))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = updateCopiedSmartQueries(tx, smartQueries, idMap)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	nodeOrder, err := selectNodeOrder(tx, groupId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if nodeOrder != nil {
+		for _, id := range sourceOrder {
+			if id == nil {
+				continue
+			}
+			if newId, ok := idMap[*id]; ok {
+				nodeOrder = append(nodeOrder, &newId)
+			}
+		}
+
+		err = updateNodeOrder(tx, groupId, nodeOrder)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	copyId := idMap[*nodeId]
+
+	return &copyId, nil
+}
+
+// Merge moves expression and translation attachments of the source nodes into the target node.
+// Attachments the target already has are dropped. Sources are moved to the trash of their groups
+// within the same transaction when removeSources is set, it returns the groups they were removed from.
+func (r *NodeRepo) Merge(targetId *valueobject.ID, sourceIds []valueobject.ID, actorId *valueobject.ID, removeSources bool) (map[valueobject.ID]*valueobject.ID, error) {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := []string{
+		`INSERT INTO node_expression (node_id, expression_id, created_at)
+			SELECT ?, expression_id, MIN(created_at) FROM node_expression WHERE node_id IN (?)
+			GROUP BY expression_id
+			ON CONFLICT (node_id, expression_id) DO NOTHING`,
+		`INSERT INTO node_translation (node_id, translation_id, created_at)
+			SELECT ?, translation_id, MIN(created_at) FROM node_translation WHERE node_id IN (?)
+			GROUP BY translation_id
+			ON CONFLICT (node_id, translation_id) DO NOTHING`,
+	}
+	for _, stmt := range stmts {
+		stmt, args, err := sqlx.In(stmt, targetId, sourceIds)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		_, err = tx.Exec(tx.Rebind(stmt), args...)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = execIn(tx, []string{
+		`DELETE FROM node_expression WHERE node_id IN (?)`,
+		`DELETE FROM node_translation WHERE node_id IN (?)`,
+	}, sourceIds)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, id := range append([]valueobject.ID{*targetId}, sourceIds...) {
+		id := id
+		_, err = insertRevision(tx, &id, actorId, app.ActivityNodeMerge)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	removedFrom := make(map[valueobject.ID]*valueobject.ID)
+	if removeSources {
+		for _, id := range sourceIds {
+			id := id
+			groupId := new(valueobject.ID)
+			err = tx.QueryRow(`SELECT group_id FROM group_node WHERE node_id=$1`, id).Scan(groupId)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+
+			err = trashNode(tx, groupId, &id, actorId)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			removedFrom[id] = groupId
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return removedFrom, nil
+}
+
+func (r *NodeRepo) DetachTranslation(nodeId *valueobject.ID, translationId *valueobject.ID, actorId *valueobject.ID) error {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM node_translation WHERE node_id=$1 AND translation_id=$2`
+	_, err = tx.Exec(query, nodeId, translationId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityTranslationDetach)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *NodeRepo) AttachText(nodeId *valueobject.ID, text app.Text, actorId *valueobject.ID) (*app.Text, error) {
+	var err error
+	var query string
+
+	group, err := r.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	if text.Id == nil {
+		query = `
+			INSERT INTO texts (author_id, title, content, lang) VALUES($1, $2, $3, $4)
+			RETURNING id, created_at
+		`
+		err = tx.QueryRow(query, text.AuthorId, text.Title, text.Content, group.TargetLangCode).
+			Scan(&text.Id, &text.CreatedAt)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	query = `UPDATE nodes SET text_id=$1 WHERE id=$2`
+	_, err = tx.Exec(query, text.Id, nodeId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityTextAttach)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &text, nil
+}
+
+func (r *NodeRepo) DetachText(nodeId *valueobject.ID, actorId *valueobject.ID) error {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE nodes SET text_id=NULL WHERE id=$1`
+	_, err = tx.Exec(query, nodeId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityTextDetach)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// findOrCreateExpression returns id of the expression and whether it has just been created.
+// The value is normalized and an existing expression differing by the case is taken.
+func findOrCreateExpression(tx *sqlx.Tx, langCode string, value string) (*valueobject.ID, bool, error) {
+	var id *valueobject.ID
+
+	value = domain.NormalizeValue(value)
+	if value == "" {
+		return nil, false, errors.New("Expression value is empty.")
+	}
+
+	query := `
+		SELECT id FROM expressions
+		WHERE lang=$1 AND lower(value)=lower($2)
+		ORDER BY value=$2 DESC, id
+		LIMIT 1
+	`
+	err := tx.QueryRow(query, langCode, value).
+		Scan(&id)
+	if err == nil {
+		return id, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	err = tx.QueryRow(`INSERT INTO expressions (lang, value) VALUES ($1, $2) RETURNING id`, langCode, value).
+		Scan(&id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return id, true, nil
+}
+
+func findOrCreateTranscription(tx *sqlx.Tx, typeId *valueobject.ID, value string) (*valueobject.ID, error) {
+	var id *valueobject.ID
+
+	err := tx.QueryRow(`SELECT id FROM transcriptions WHERE type=$1 AND value=$2`, typeId, value).
+		Scan(&id)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRow(`INSERT INTO transcriptions (type, value) VALUES ($1, $2) RETURNING id`, typeId, value).
+			Scan(&id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// findOrCreateTranslation keeps the comment of an existing translation.
+func findOrCreateTranslation(tx *sqlx.Tx, targetId *valueobject.ID, nativeId *valueobject.ID, comment string) (*valueobject.ID, error) {
+	var id *valueobject.ID
+
+	query := `
+		SELECT id FROM translations
+		WHERE type=(SELECT id FROM object_types WHERE name='expression') AND target_id=$1 AND native_id=$2
+	`
+	err := tx.QueryRow(query, targetId, nativeId).Scan(&id)
+	if err == sql.ErrNoRows {
+		query = `
+			INSERT INTO translations (type, target_id, native_id, comment)
+			SELECT id, $1, $2, $3 FROM object_types WHERE name='expression'
+			RETURNING id
+		`
+		err = tx.QueryRow(query, targetId, nativeId, comment).Scan(&id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// execLinked runs insert of a link row and tells whether the link is new.
+func execLinked(tx *sqlx.Tx, query string, args ...interface{}) (bool, error) {
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func importRow(tx *sqlx.Tx, group *app.Group, nodeId *valueobject.ID, row *app.ImportRow) (*app.ImportRowReport, error) {
+	report := &app.ImportRowReport{
+		Line:       row.Line,
+		Expression: row.Expression,
+	}
+
+	expressionId, created, err := findOrCreateExpression(tx, group.TargetLangCode, row.Expression)
+	if err != nil {
+		return nil, err
+	}
+	report.ExpressionId = expressionId
+
+	linked, err := execLinked(tx, `
+		INSERT INTO node_expression (node_id, expression_id) VALUES ($1, $2)
+		ON CONFLICT (node_id, expression_id) DO NOTHING
+	`, nodeId, expressionId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range row.Transcriptions {
+		transcriptionId, err := findOrCreateTranscription(tx, group.TranscriptionTypeId, value)
+		if err != nil {
+			return nil, err
+		}
+
+		isNew, err := execLinked(tx, `
+			INSERT INTO expression_transcription (expression_id, transcription_id) VALUES ($1, $2)
+			ON CONFLICT (expression_id, transcription_id) DO NOTHING
+		`, expressionId, transcriptionId)
+		if err != nil {
+			return nil, err
+		}
+		linked = linked || isNew
+	}
+
+	for _, value := range row.Translations {
+		nativeId, _, err := findOrCreateExpression(tx, group.NativeLangCode, value)
+		if err != nil {
+			return nil, err
+		}
+
+		translationId, err := findOrCreateTranslation(tx, expressionId, nativeId, row.Comment)
+		if err != nil {
+			return nil, err
+		}
+
+		isNew, err := execLinked(tx, `
+			INSERT INTO node_translation (node_id, translation_id) VALUES ($1, $2)
+			ON CONFLICT (node_id, translation_id) DO NOTHING
+		`, nodeId, translationId)
+		if err != nil {
+			return nil, err
+		}
+		linked = linked || isNew
+	}
+
+	switch {
+	case created:
+		report.Status = app.ImportCreated
+	case linked:
+		report.Status = app.ImportLinked
+	default:
+		report.Status = app.ImportDuplicate
+	}
+
+	return report, nil
+}
+
+// importRows attaches the rows to the node, a row runs within a savepoint so that its failure
+// is reported without affecting others.
+func importRows(tx *sqlx.Tx, group *app.Group, nodeId *valueobject.ID, rows []*app.ImportRow) ([]*app.ImportRowReport, error) {
+	reports := []*app.ImportRowReport{}
+
+	for _, row := range rows {
+		var report *app.ImportRowReport
+		err := inSavepoint(tx, func() error {
+			var err error
+			report, err = importRow(tx, group, nodeId, row)
+			return err
+		})
+		if _, ok := err.(*savepointError); ok {
+			return nil, err
+		}
+		if err != nil {
+			report = &app.ImportRowReport{
+				Line:       row.Line,
+				Expression: row.Expression,
+				Status:     app.ImportError,
+				Error:      err.Error(),
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// Import attaches the rows to the node within a single transaction reusing existing
+// expressions, translations and transcriptions. A failed row doesn't affect others.
+// In dry run mode the transaction is rolled back and only the report is returned,
+// otherwise the resulting content is recorded as a node revision with the given action.
+func (r *NodeRepo) Import(nodeId *valueobject.ID, rows []*app.ImportRow, dryRun bool, actorId *valueobject.ID, action app.ActivityAction) ([]*app.ImportRowReport, error) {
+	group, err := r.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := importRows(tx, group, nodeId, rows)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if dryRun {
+		tx.Rollback()
+
+		for _, report := range reports {
+			if report.Status == app.ImportCreated {
+				report.ExpressionId = nil
+			}
+		}
+
+		return reports, nil
+	}
+
+	_, err = insertRevision(tx, nodeId, actorId, action)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// ImportNodes creates the folders and slices under the path of the group and imports rows into
+// the slices within a single transaction, so a failure leaves the group as it was.
+func (r *NodeRepo) ImportNodes(groupId *valueobject.ID, path string, nodes []*app.ImportNode, actorId *valueobject.ID) ([]*app.ImportedNode, error) {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	group := &app.Group{}
+	err = tx.Get(group, `SELECT * FROM groups WHERE id=$1 AND status=$2 FOR SHARE`, groupId, app.GroupActive)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, errors.New("Group doesn't exist.")
+		}
+		return nil, err
+	}
+
+	imported := []*app.ImportedNode{}
+	for _, node := range nodes {
+		obj := &app.Node{
+			Type:       node.Type,
+			Name:       node.Name,
+			Visibility: app.NodePrivate,
+			Path:       path,
+		}
+		if node.Parent != nil {
+			parent := imported[*node.Parent].Node
+			obj.Path = app.FlatNode{Id: parent.Id, Path: parent.Path}.ChildPath()
+		}
+
+		err = insertNode(tx, groupId, obj, actorId)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		result := &app.ImportedNode{Node: obj, Reports: []*app.ImportRowReport{}}
+		imported = append(imported, result)
+
+		if len(node.Rows) == 0 {
+			continue
+		}
+
+		result.Reports, err = importRows(tx, group, obj.Id, node.Rows)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		_, err = insertRevision(tx, obj.Id, actorId, app.ActivityNodeImport)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// savepointError is a failure of the savepoint itself, it breaks the whole transaction
+// unlike errors of the item run within the savepoint.
+type savepointError struct {
+	err error
+}
+
+func (e *savepointError) Error() string {
+	return e.err.Error()
+}
+
+func (e *savepointError) Unwrap() error {
+	return e.err
+}
+
+// inSavepoint runs fn so that its failure rolls back only its own changes and returns
+// the error of fn. Failures of the transaction are returned as *savepointError.
+func inSavepoint(tx *sqlx.Tx, fn func() error) error {
+	if _, err := tx.Exec(`SAVEPOINT item`); err != nil {
+		return &savepointError{err}
+	}
+
+	itemErr := fn()
+	if itemErr != nil {
+		if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT item`); err != nil {
+			return &savepointError{err}
+		}
+
+		return itemErr
+	}
+
+	if _, err := tx.Exec(`RELEASE SAVEPOINT item`); err != nil {
+		return &savepointError{err}
+	}
+
+	return nil
+}
+
+func bulkExpression(tx *sqlx.Tx, group *app.Group, nodeId *valueobject.ID, expression *app.Expression, result *app.BulkExpressionResult) error {
+	if expression.Id == nil {
+		if expression.Value == "" {
+			return errors.New("You need to specify expression id or value.")
+		}
+
+		id, _, err := findOrCreateExpression(tx, group.TargetLangCode, expression.Value)
+		if err != nil {
+			return err
+		}
+		result.Id = id
+	} else {
+		err := tx.QueryRow(`SELECT value FROM expressions WHERE id=$1 AND lang=$2`, expression.Id, group.TargetLangCode).
+			Scan(&result.Value)
+		if err == sql.ErrNoRows {
+			return errors.New("Expression doesn't exist in the target language of the group.")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	linked, err := execLinked(tx, `
+		INSERT INTO node_expression (node_id, expression_id) VALUES ($1, $2)
+		ON CONFLICT (node_id, expression_id) DO NOTHING
+	`, nodeId, result.Id)
+	if err != nil {
+		return err
+	}
+
+	result.Status = app.BulkUnchanged
+	if linked {
+		result.Status = app.BulkAttached
+	}
+
+	return nil
+}
+
+func bulkTranslation(tx *sqlx.Tx, group *app.Group, nodeId *valueobject.ID, expressionId *valueobject.ID, translation *app.Translation, result *app.BulkTranslationResult) error {
+	if translation.Id == nil {
+		if translation.Value == "" {
+			return errors.New("You need to specify translation id or value.")
+		}
+
+		nativeId, _, err := findOrCreateExpression(tx, group.NativeLangCode, translation.Value)
+		if err != nil {
+			return err
+		}
+
+		result.Id, err = findOrCreateTranslation(tx, expressionId, nativeId, translation.Comment)
+		if err != nil {
+			return err
+		}
+	} else {
+		var targetId valueobject.ID
+
+		query := `
+			SELECT t.target_id, e.value FROM translations t
+			LEFT JOIN expressions e ON e.id=t.native_id
+			WHERE t.id=$1
+		`
+		err := tx.QueryRow(query, translation.Id).Scan(&targetId, &result.Value)
+		if err == sql.ErrNoRows || (err == nil && targetId != *expressionId) {
+			return errors.New("Translation doesn't belong to the expression.")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	linked, err := execLinked(tx, `
+		INSERT INTO node_translation (node_id, translation_id) VALUES ($1, $2)
+		ON CONFLICT (node_id, translation_id) DO NOTHING
+	`, nodeId, result.Id)
+	if err != nil {
 		return err
 	}
 
+	result.Status = app.BulkUnchanged
+	if linked {
+		result.Status = app.BulkAttached
+	}
+
 	return nil
 }
+
+// AttachExpressions attaches the expressions with their nested translations within a single
+// transaction. Every item is tried so that all failures get reported, in atomic mode
+// the transaction is rolled back when any of them fails.
+func (r *NodeRepo) AttachExpressions(nodeId *valueobject.ID, expressions []*app.Expression, atomic bool, actorId *valueobject.ID) ([]*app.BulkExpressionResult, error) {
+	group, err := r.GetGroupByNode(nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*app.BulkExpressionResult{}
+	failed := false
+
+	for idx, expression := range expressions {
+		result := &app.BulkExpressionResult{
+			Index:        idx,
+			Id:           expression.Id,
+			Value:        expression.Value,
+			Translations: []*app.BulkTranslationResult{},
+		}
+		results = append(results, result)
+
+		itemErr := inSavepoint(tx, func() error {
+			return bulkExpression(tx, group, nodeId, expression, result)
+		})
+		if _, ok := itemErr.(*savepointError); ok {
+			tx.Rollback()
+			return nil, itemErr
+		}
+
+		if itemErr != nil {
+			failed = true
+			result.Status = app.BulkFailed
+			result.Error = itemErr.Error()
+			continue
+		}
+
+		for trIdx, translation := range expression.Translations {
+			trResult := &app.BulkTranslationResult{
+				Index: trIdx,
+				Id:    translation.Id,
+				Value: translation.Value,
+			}
+			result.Translations = append(result.Translations, trResult)
+
+			itemErr := inSavepoint(tx, func() error {
+				return bulkTranslation(tx, group, nodeId, result.Id, translation, trResult)
+			})
+			if _, ok := itemErr.(*savepointError); ok {
+				tx.Rollback()
+				return nil, itemErr
+			}
+
+			if itemErr != nil {
+				failed = true
+				trResult.Status = app.BulkFailed
+				trResult.Error = itemErr.Error()
+			}
+		}
+	}
+
+	if atomic && failed {
+		tx.Rollback()
+
+		for _, result := range results {
+			if result.Status == app.BulkAttached {
+				result.Status = app.BulkRolledBack
+			}
+			for _, trResult := range result.Translations {
+				if trResult.Status == app.BulkAttached {
+					trResult.Status = app.BulkRolledBack
+				}
+			}
+		}
+
+		return results, nil
+	}
+
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityExpressionAttach)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// DetachExpressions detaches expressions together with their translations and then
+// separate translations from the node, atomic mode works as in AttachExpressions.
+func (r *NodeRepo) DetachExpressions(nodeId *valueobject.ID, expressionIds []valueobject.ID, translationIds []valueobject.ID, atomic bool, actorId *valueobject.ID) ([]*app.BulkDetachResult, error) {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*app.BulkDetachResult{}
+	failed := false
+
+	detach := func(targetType app.ActivityTarget, id valueobject.ID, queries ...string) error {
+		result := &app.BulkDetachResult{TargetType: targetType, Id: &id, Status: app.BulkUnchanged}
+		results = append(results, result)
+
+		itemErr := inSavepoint(tx, func() error {
+			for _, query := range queries {
+				detached, err := execLinked(tx, query, nodeId, id)
+				if err != nil {
+					return err
+				}
+				if detached {
+					result.Status = app.BulkDetached
+				}
+			}
+
+			return nil
+		})
+		if _, ok := itemErr.(*savepointError); ok {
+			return itemErr
+		}
+
+		if itemErr != nil {
+			failed = true
+			result.Status = app.BulkFailed
+			result.Error = itemErr.Error()
+		}
+
+		return nil
+	}
+
+	for _, id := range expressionIds {
+		err = detach(app.TargetExpression, id, `
+			DELETE FROM node_translation 
+			WHERE node_id=$1 AND translation_id IN (
+				SELECT id FROM translations WHERE target_id=$2 AND type=(SELECT id FROM object_types WHERE name='expression')
+			)
+		`, `DELETE FROM node_expression WHERE node_id=$1 AND expression_id=$2`)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	for _, id := range translationIds {
+		err = detach(app.TargetTranslation, id, `DELETE FROM node_translation WHERE node_id=$1 AND translation_id=$2`)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if atomic && failed {
+		tx.Rollback()
+
+		for _, result := range results {
+			if result.Status == app.BulkDetached {
+				result.Status = app.BulkRolledBack
+			}
+		}
+
+		return results, nil
+	}
+
+	_, err = insertRevision(tx, nodeId, actorId, app.ActivityExpressionDetach)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -7,23 +7,35 @@ import (
 )
 
 type Repos struct {
-	User        app.UserRepo
-	Group       app.GroupRepo
-	Node        app.NodeRepo
-	Expression  domain.ExpressionRepo
-	Translation domain.TranslationRepo
-	Lang        domain.LangRepo
-	Training    app.TrainingRepo
+	User             app.UserRepo
+	Group            app.GroupRepo
+	Node             app.NodeRepo
+	Expression       domain.ExpressionRepo
+	ExpressionDetail app.ExpressionDetailRepo
+	ExpressionMerge  app.ExpressionMergeRepo
+	Translation      domain.TranslationRepo
+	Lang             domain.LangRepo
+	Training         app.TrainingRepo
+	Activity         app.ActivityRepo
+	Catalog          app.CatalogRepo
+	Tag              app.TagRepo
+	Dictionary       app.DictionaryRepo
 }
 
 func NewRepos(db db.DB) *Repos {
 	return &Repos{
-		User:        NewUserRepo(db),
-		Group:       NewGroupRepo(db),
-		Node:        NewNodeRepo(db),
-		Expression:  NewExpressionRepo(db),
-		Translation: NewTranslationRepo(db),
-		Lang:        NewLangRepo(db),
-		Training:    NewTrainingRepo(db),
+		User:             NewUserRepo(db),
+		Group:            NewGroupRepo(db),
+		Node:             NewNodeRepo(db),
+		Expression:       NewExpressionRepo(db),
+		ExpressionDetail: NewExpressionRepo(db),
+		ExpressionMerge:  NewExpressionRepo(db),
+		Translation:      NewTranslationRepo(db),
+		Lang:             NewLangRepo(db),
+		Training:         NewTrainingRepo(db),
+		Activity:         NewActivityRepo(db),
+		Catalog:          NewCatalogRepo(db),
+		Tag:              NewTagRepo(db),
+		Dictionary:       NewDictionaryRepo(db),
 	}
 }
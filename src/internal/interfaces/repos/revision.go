@@ -0,0 +1,165 @@
+package repos
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// insertRevision records the current content of the node as its next revision.
+// Nothing is recorded when the content is the same as in the latest revision.
+func insertRevision(tx *sqlx.Tx, nodeId *valueobject.ID, actorId *valueobject.ID, action app.ActivityAction) (*app.NodeRevision, error) {
+	// Locking the node keeps revision numbers of concurrent changes sequential.
+	_, err := tx.Exec(`SELECT id FROM nodes WHERE id=$1 FOR UPDATE`, nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &app.NodeRevision{NodeId: nodeId, ActorId: actorId, Action: action}
+	query := `
+		WITH latest AS (
+			SELECT number, snapshot FROM node_revisions WHERE node_id=$1 ORDER BY number DESC LIMIT 1
+		)
+		INSERT INTO node_revisions (node_id, number, actor_id, action, snapshot)
+		SELECT $1, COALESCE((SELECT number FROM latest), 0) + 1, $2, $3, node_snapshot($1)
+		WHERE NOT EXISTS (SELECT 1 FROM latest WHERE snapshot=node_snapshot($1))
+		RETURNING id, number, created_at
+	`
+	err = tx.QueryRow(query, nodeId, actorId, action).Scan(&revision.Id, &revision.Number, &revision.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// ListRevisions returns revisions of the node without snapshots, the latest first.
+func (r *NodeRepo) ListRevisions(nodeId *valueobject.ID) ([]*app.NodeRevision, error) {
+	revisions := []*app.NodeRevision{}
+	query := `
+		SELECT nr.id, nr.node_id, nr.number, nr.actor_id, u.username AS actor_username, nr.action, nr.created_at
+		FROM node_revisions nr
+		LEFT JOIN users u ON u.id=nr.actor_id
+		WHERE nr.node_id=$1
+		ORDER BY nr.number DESC
+	`
+	err := r.db.Db().Select(&revisions, query, nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (r *NodeRepo) GetRevision(nodeId *valueobject.ID, number uint) (*app.NodeRevision, error) {
+	var snapshot []byte
+
+	revision := &app.NodeRevision{}
+	query := `
+		SELECT nr.id, nr.node_id, nr.number, nr.actor_id, u.username, nr.action, nr.snapshot, nr.created_at
+		FROM node_revisions nr
+		LEFT JOIN users u ON u.id=nr.actor_id
+		WHERE nr.node_id=$1 AND nr.number=$2
+	`
+	err := r.db.Db().QueryRow(query, nodeId, number).Scan(
+		&revision.Id, &revision.NodeId, &revision.Number, &revision.ActorId,
+		&revision.ActorUsername, &revision.Action, &snapshot, &revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.Snapshot = &app.NodeSnapshot{}
+	err = json.Unmarshal(snapshot, revision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// ApplySnapshot brings the node name, text, attached expressions with their positions
+// and attached translations to the state kept in the snapshot. The result is recorded as
+// a revert revision, nil is returned when the node already has the content of the snapshot.
+func (r *NodeRepo) ApplySnapshot(nodeId *valueobject.ID, snapshot *app.NodeSnapshot, actorId *valueobject.ID) (*app.NodeRevision, error) {
+	var textId *valueobject.ID
+
+	if snapshot.Text != nil {
+		textId = snapshot.Text.Id
+	}
+
+	expressionIds := []valueobject.ID{}
+	translationIds := []valueobject.ID{}
+	for _, expr := range snapshot.Expressions {
+		expressionIds = append(expressionIds, *expr.Id)
+		for _, tr := range expr.Translations {
+			translationIds = append(translationIds, *tr.Id)
+		}
+	}
+
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE nodes SET name=$1, text_id=$2 WHERE id=$3`, snapshot.Name, textId, nodeId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	stmts := []string{
+		`DELETE FROM node_expression WHERE node_id=$1 AND NOT expression_id=ANY($2::int[])`,
+		`DELETE FROM node_translation WHERE node_id=$1 AND NOT translation_id=ANY($2::int[])`,
+	}
+	for idx, ids := range [][]valueobject.ID{expressionIds, translationIds} {
+		_, err = tx.Exec(stmts[idx], nodeId, pq.Array(ids))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	for _, expr := range snapshot.Expressions {
+		query := `
+			INSERT INTO node_expression (node_id, expression_id, position) VALUES ($1, $2, $3)
+			ON CONFLICT (node_id, expression_id) DO UPDATE SET position=EXCLUDED.position
+		`
+		_, err = tx.Exec(query, nodeId, expr.Id, expr.Position)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	query := `
+		INSERT INTO node_translation (node_id, translation_id)
+		SELECT $1, UNNEST($2::int[])
+		ON CONFLICT (node_id, translation_id) DO NOTHING
+	`
+	_, err = tx.Exec(query, nodeId, pq.Array(translationIds))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	revision, err := insertRevision(tx, nodeId, actorId, app.ActivityNodeRevert)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
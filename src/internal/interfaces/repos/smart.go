@@ -0,0 +1,53 @@
+package repos
+
+import (
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/jmoiron/sqlx"
+)
+
+// HasSmart tells whether any of the nodes is a smart slice.
+func (r *NodeRepo) HasSmart(nodeIds []valueobject.ID) (bool, error) {
+	var count int
+
+	query, args, err := sqlx.In(`SELECT COUNT(id) FROM nodes WHERE id IN (?) AND type=?`, nodeIds, app.NodeSmart)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.db.Db().QueryRow(r.db.Db().Rebind(query), args...).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// UpdateSmartQuery stores the new query of the smart slice, its contents are computed when read.
+func (r *NodeRepo) UpdateSmartQuery(nodeId *valueobject.ID, query *app.SmartQuery) error {
+	_, err := r.db.Db().Exec(`UPDATE nodes SET smart_query=$1, updated_at=NOW() WHERE id=$2 AND type=$3`, query, nodeId, app.NodeSmart)
+
+	return err
+}
+
+// updateCopiedSmartQueries points sources of the copied smart queries, keyed by the copy id,
+// to copies of the source nodes. Sources which weren't copied are kept as they are.
+func updateCopiedSmartQueries(tx *sqlx.Tx, queries map[valueobject.ID]*app.SmartQuery, idMap map[valueobject.ID]valueobject.ID) error {
+	for nodeId, query := range queries {
+		copied := *query
+		copied.SourceIds = make([]valueobject.ID, len(query.SourceIds))
+		for i, id := range query.SourceIds {
+			if newId, ok := idMap[id]; ok {
+				id = newId
+			}
+			copied.SourceIds[i] = id
+		}
+
+		_, err := tx.Exec(`UPDATE nodes SET smart_query=$1 WHERE id=$2`, copied, nodeId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
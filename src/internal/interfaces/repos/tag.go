@@ -0,0 +1,396 @@
+package repos
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/db"
+	"github.com/lib/pq"
+)
+
+// filterLinksQuery selects node-expression links of the group slices matching the saved filter.
+// It expects group id, tag ids, slice node type, scope node ids and the number of needed tags.
+const filterLinksQuery = `
+	SELECT ne.node_id, ne.expression_id FROM node_expression ne
+	LEFT JOIN group_node gn ON gn.node_id=ne.node_id
+	LEFT JOIN nodes n ON n.id=ne.node_id
+	JOIN LATERAL (
+		SELECT net.tag_id FROM node_expression_tag net
+		WHERE net.node_id=ne.node_id AND net.expression_id=ne.expression_id
+		UNION
+		SELECT nt.tag_id FROM node_tag nt
+		WHERE nt.tag_id=ANY($2::int[]) AND (nt.node_id=ne.node_id OR gn.path ~ ('*.' || nt.node_id || '.*')::lquery)
+	) lt ON lt.tag_id=ANY($2::int[])
+	WHERE gn.group_id=$1 AND n.type=$3 AND (
+		cardinality($4::int[])=0
+		OR gn.node_id=ANY($4::int[])
+		OR EXISTS (SELECT 1 FROM UNNEST($4::int[]) s(id) WHERE gn.path ~ ('*.' || s.id || '.*')::lquery)
+	)
+	GROUP BY ne.node_id, ne.expression_id
+	HAVING COUNT(DISTINCT lt.tag_id) >= $5
+`
+
+func filterLinksArgs(filter *app.SavedFilter) []interface{} {
+	needed := 1
+	if filter.Match == app.TagMatchAll {
+		needed = len(filter.TagIds)
+	}
+
+	return []interface{}{filter.GroupId, pq.Array(filter.TagIds), app.NodeSlice, pq.Array(filter.NodeIds), needed}
+}
+
+func idsFromArray(arr pq.Int64Array) []valueobject.ID {
+	ids := []valueobject.ID{}
+	for _, id := range arr {
+		ids = append(ids, valueobject.ID(id))
+	}
+
+	return ids
+}
+
+type TagRepo struct {
+	db db.DB
+}
+
+func NewTagRepo(db db.DB) *TagRepo {
+	return &TagRepo{db}
+}
+
+func (r *TagRepo) Create(tag app.Tag) (*app.Tag, error) {
+	query := `INSERT INTO tags (group_id, name) VALUES ($1, $2) RETURNING id, created_at`
+	err := r.db.Db().QueryRow(query, tag.GroupId, tag.Name).Scan(&tag.Id, &tag.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepo) Get(tagId *valueobject.ID) (*app.Tag, error) {
+	tag := &app.Tag{}
+	err := r.db.Db().Get(tag, `SELECT id, group_id, name, created_at FROM tags WHERE id=$1`, tagId)
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *TagRepo) List(groupId *valueobject.ID) ([]*app.Tag, error) {
+	tags := []*app.Tag{}
+	err := r.db.Db().Select(&tags, `SELECT id, group_id, name, created_at FROM tags WHERE group_id=$1 ORDER BY name`, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *TagRepo) Rename(tagId *valueobject.ID, name string) error {
+	_, err := r.db.Db().Exec(`UPDATE tags SET name=$1 WHERE id=$2`, name, tagId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the tag from nodes, expression links and saved filters.
+func (r *TagRepo) Delete(tagId *valueobject.ID) error {
+	tx, err := r.db.Db().Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE saved_filters SET tag_ids=array_remove(tag_ids, $1) WHERE $1=ANY(tag_ids)`, tagId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM tags WHERE id=$1`, tagId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TagRepo) NodeTags(nodeId *valueobject.ID) (*app.NodeTags, error) {
+	nodeTags := &app.NodeTags{
+		Tags:        []*app.Tag{},
+		Expressions: make(map[valueobject.ID][]*app.Tag),
+	}
+
+	query := `
+		SELECT t.id, t.group_id, t.name, t.created_at FROM node_tag nt
+		LEFT JOIN tags t ON t.id=nt.tag_id
+		WHERE nt.node_id=$1
+		ORDER BY t.name
+	`
+	err := r.db.Db().Select(&nodeTags.Tags, query, nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT net.expression_id, t.id, t.group_id, t.name, t.created_at FROM node_expression_tag net
+		LEFT JOIN tags t ON t.id=net.tag_id
+		WHERE net.node_id=$1
+		ORDER BY net.expression_id, t.name
+	`
+	rows, err := r.db.Db().Query(query, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var expressionId valueobject.ID
+		tag := &app.Tag{}
+		err = rows.Scan(&expressionId, &tag.Id, &tag.GroupId, &tag.Name, &tag.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeTags.Expressions[expressionId] = append(nodeTags.Expressions[expressionId], tag)
+	}
+
+	return nodeTags, nil
+}
+
+func (r *TagRepo) TagNode(nodeId *valueobject.ID, tagId *valueobject.ID) error {
+	query := `INSERT INTO node_tag (node_id, tag_id) VALUES ($1, $2) ON CONFLICT (node_id, tag_id) DO NOTHING`
+	_, err := r.db.Db().Exec(query, nodeId, tagId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TagRepo) UntagNode(nodeId *valueobject.ID, tagId *valueobject.ID) error {
+	_, err := r.db.Db().Exec(`DELETE FROM node_tag WHERE node_id=$1 AND tag_id=$2`, nodeId, tagId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TagRepo) TagExpression(nodeId *valueobject.ID, expressionId *valueobject.ID, tagId *valueobject.ID) error {
+	var attached bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM node_expression WHERE node_id=$1 AND expression_id=$2)`
+	err := r.db.Db().QueryRow(query, nodeId, expressionId).Scan(&attached)
+	if err != nil {
+		return err
+	}
+
+	if !attached {
+		return errors.New("Expression isn't attached to the node.")
+	}
+
+	query = `
+		INSERT INTO node_expression_tag (node_id, expression_id, tag_id) VALUES ($1, $2, $3)
+		ON CONFLICT (node_id, expression_id, tag_id) DO NOTHING
+	`
+	_, err = r.db.Db().Exec(query, nodeId, expressionId, tagId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TagRepo) UntagExpression(nodeId *valueobject.ID, expressionId *valueobject.ID, tagId *valueobject.ID) error {
+	query := `DELETE FROM node_expression_tag WHERE node_id=$1 AND expression_id=$2 AND tag_id=$3`
+	_, err := r.db.Db().Exec(query, nodeId, expressionId, tagId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TagRepo) CreateFilter(filter app.SavedFilter) (*app.SavedFilter, error) {
+	query := `
+		INSERT INTO saved_filters (group_id, owner_id, name, tag_ids, match, node_ids)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err := r.db.Db().QueryRow(query, filter.GroupId, filter.OwnerId, filter.Name, pq.Array(filter.TagIds), filter.Match, pq.Array(filter.NodeIds)).
+		Scan(&filter.Id, &filter.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filter, nil
+}
+
+const selectFiltersQuery = `SELECT id, group_id, owner_id, name, tag_ids, match, node_ids, created_at FROM saved_filters`
+
+func scanFilter(row interface{ Scan(...interface{}) error }) (*app.SavedFilter, error) {
+	var tagIds, nodeIds pq.Int64Array
+
+	filter := &app.SavedFilter{}
+	err := row.Scan(&filter.Id, &filter.GroupId, &filter.OwnerId, &filter.Name, &tagIds, &filter.Match, &nodeIds, &filter.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	filter.TagIds = idsFromArray(tagIds)
+	filter.NodeIds = idsFromArray(nodeIds)
+
+	return filter, nil
+}
+
+func (r *TagRepo) GetFilter(filterId *valueobject.ID) (*app.SavedFilter, error) {
+	return scanFilter(r.db.Db().QueryRow(selectFiltersQuery+` WHERE id=$1`, filterId))
+}
+
+// ListFilters returns filters the user has saved in the group.
+func (r *TagRepo) ListFilters(groupId *valueobject.ID, ownerId *valueobject.ID) ([]*app.SavedFilter, error) {
+	rows, err := r.db.Db().Query(selectFiltersQuery+` WHERE group_id=$1 AND owner_id=$2 ORDER BY name`, groupId, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	filters := []*app.SavedFilter{}
+	for rows.Next() {
+		filter, err := scanFilter(rows)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+func (r *TagRepo) UpdateFilter(filter app.SavedFilter) error {
+	query := `UPDATE saved_filters SET name=$1, tag_ids=$2, match=$3, node_ids=$4 WHERE id=$5`
+	_, err := r.db.Db().Exec(query, filter.Name, pq.Array(filter.TagIds), filter.Match, pq.Array(filter.NodeIds), filter.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TagRepo) DeleteFilter(filterId *valueobject.ID) error {
+	_, err := r.db.Db().Exec(`DELETE FROM saved_filters WHERE id=$1`, filterId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FilterExpressions lists expressions matched by the filter with translations attached
+// along with the matching links, an expression matched in several slices is listed once.
+func (r *TagRepo) FilterExpressions(filter *app.SavedFilter) ([]*app.Expression, error) {
+	var expressionId valueobject.ID
+
+	query := `
+		WITH links AS (` + filterLinksQuery + `)
+		SELECT DISTINCT t.id, t.target_id, e.value, t.comment FROM links l
+		LEFT JOIN node_translation nt ON nt.node_id=l.node_id
+		LEFT JOIN translations t ON t.id=nt.translation_id
+		LEFT JOIN expressions e ON e.id=t.native_id
+		WHERE t.target_id=l.expression_id
+		ORDER BY t.id
+	`
+	rows, err := r.db.Db().Query(query, filterLinksArgs(filter)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exprTranslMap := make(map[valueobject.ID][]*app.Translation)
+	for rows.Next() {
+		tr := &app.Translation{}
+		err = rows.Scan(&tr.Id, &expressionId, &tr.Value, &tr.Comment)
+		if err != nil {
+			return nil, err
+		}
+
+		exprTranslMap[expressionId] = append(exprTranslMap[expressionId], tr)
+	}
+
+	query = `
+		WITH links AS (` + filterLinksQuery + `)
+		SELECT e.id, e.value, MAX(ne.created_at) created_at FROM links l
+		LEFT JOIN node_expression ne ON ne.node_id=l.node_id AND ne.expression_id=l.expression_id
+		LEFT JOIN expressions e ON e.id=l.expression_id
+		GROUP BY e.id
+		ORDER BY ` + expressionOrderBy(app.ExpressionOrderCreated)
+	rows, err = r.db.Db().Query(query, filterLinksArgs(filter)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expressions := []*app.Expression{}
+	for rows.Next() {
+		expr := &app.Expression{}
+		err = rows.Scan(&expr.Id, &expr.Value, &expr.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		expr.Translations = exprTranslMap[*expr.Id]
+		expressions = append(expressions, expr)
+	}
+
+	return expressions, nil
+}
+
+// TranslationsByFilters returns translations which trainings of the filters are made of, each once.
+func (r *TagRepo) TranslationsByFilters(filterIds []valueobject.ID) ([]*app.Translation, error) {
+	translations := []*app.Translation{}
+	seen := make(map[valueobject.ID]bool)
+
+	for _, id := range filterIds {
+		id := id
+		filter, err := r.GetFilter(&id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, errors.New("Saved filter doesn't exist.")
+			}
+			return nil, err
+		}
+
+		query := `
+			WITH links AS (` + filterLinksQuery + `)
+			SELECT t.id, e.value, t.comment FROM links l
+			LEFT JOIN node_translation nt ON nt.node_id=l.node_id
+			LEFT JOIN translations t ON t.id=nt.translation_id
+			LEFT JOIN expressions e ON e.id=t.native_id
+			WHERE t.target_id=l.expression_id
+			ORDER BY l.node_id, nt.created_at, t.id
+		`
+		filterTranslations := []*app.Translation{}
+		err = r.db.Db().Select(&filterTranslations, query, filterLinksArgs(filter)...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tr := range filterTranslations {
+			if !seen[*tr.Id] {
+				seen[*tr.Id] = true
+				translations = append(translations, tr)
+			}
+		}
+	}
+
+	return translations, nil
+}
@@ -30,11 +30,11 @@ func (r *TrainingRepo) Create(inTraining app.Training) (*app.Training, error) {
 	}
 
 	query = `
-		INSERT INTO trainings (owner_id, type, transcription_type, slices)
-		VALUES($1, $2, $3, $4)
+		INSERT INTO trainings (owner_id, type, transcription_type, slices, filters)
+		VALUES($1, $2, $3, $4, $5)
 		RETURNING id
 	`
-	err = tx.QueryRow(query, training.OwnerId, training.Type, training.TranscriptionTypeId, pq.Array(training.Slices)).
+	err = tx.QueryRow(query, training.OwnerId, training.Type, training.TranscriptionTypeId, pq.Array(training.Slices), pq.Array(training.Filters)).
 		Scan(&training.Id)
 	if err != nil {
 		tx.Rollback()
@@ -86,13 +86,14 @@ func (r *TrainingRepo) getMeta(trainingId *valueobject.ID) (*app.TrainingMeta, e
 
 func (r *TrainingRepo) Get(trainingId *valueobject.ID) (*app.Training, error) {
 	query := `
-		SELECT id, owner_id, type, transcription_type, slices FROM trainings
+		SELECT id, owner_id, type, transcription_type, slices, filters FROM trainings
 		WHERE id=$1
 	`
 	training := &app.Training{}
 	sliceArr := pq.Int64Array{}
+	filterArr := pq.Int64Array{}
 	err := r.db.Db().QueryRow(query, trainingId).
-		Scan(&training.Id, &training.OwnerId, &training.Type, &training.TranscriptionTypeId, &sliceArr)
+		Scan(&training.Id, &training.OwnerId, &training.Type, &training.TranscriptionTypeId, &sliceArr, &filterArr)
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +101,7 @@ func (r *TrainingRepo) Get(trainingId *valueobject.ID) (*app.Training, error) {
 	for _, sliceId := range sliceArr {
 		training.Slices = append(training.Slices, valueobject.ID(sliceId))
 	}
+	training.Filters = idsFromArray(filterArr)
 
 	training.Meta, err = r.getMeta(trainingId)
 	if err != nil {
@@ -111,7 +113,7 @@ func (r *TrainingRepo) Get(trainingId *valueobject.ID) (*app.Training, error) {
 
 func (r *TrainingRepo) List(ownerId *valueobject.ID) ([]*app.Training, error) {
 	query := `
-		SELECT id, type, transcription_type, slices FROM trainings
+		SELECT id, type, transcription_type, slices, filters FROM trainings
 		WHERE owner_id=$1
 	`
 	trainings := []*app.Training{}
@@ -122,13 +124,15 @@ func (r *TrainingRepo) List(ownerId *valueobject.ID) ([]*app.Training, error) {
 
 	for rows.Next() {
 		sliceArr := pq.Int64Array{}
+		filterArr := pq.Int64Array{}
 		training := &app.Training{OwnerId: ownerId}
-		rows.Scan(&training.Id, &training.Type, &training.TranscriptionTypeId, &sliceArr)
+		rows.Scan(&training.Id, &training.Type, &training.TranscriptionTypeId, &sliceArr, &filterArr)
 		trainings = append(trainings, training)
 
 		for _, sliceId := range sliceArr {
 			training.Slices = append(training.Slices, valueobject.ID(sliceId))
 		}
+		training.Filters = idsFromArray(filterArr)
 
 		training.Meta, err = r.getMeta(training.Id)
 		if err != nil {
@@ -141,13 +145,14 @@ func (r *TrainingRepo) List(ownerId *valueobject.ID) ([]*app.Training, error) {
 
 func (r *TrainingRepo) GetByItemId(itemId *valueobject.ID) (*app.Training, error) {
 	query := `
-		SELECT id, owner_id, type, transcription_type, slices FROM trainings
+		SELECT id, owner_id, type, transcription_type, slices, filters FROM trainings
 		WHERE id = (SELECT training_id FROM training_items WHERE id=$1)
 	`
 	training := &app.Training{}
 	sliceArr := pq.Int64Array{}
+	filterArr := pq.Int64Array{}
 	err := r.db.Db().QueryRow(query, itemId).
-		Scan(&training.Id, &training.OwnerId, &training.Type, &training.TranscriptionTypeId, &sliceArr)
+		Scan(&training.Id, &training.OwnerId, &training.Type, &training.TranscriptionTypeId, &sliceArr, &filterArr)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +160,7 @@ func (r *TrainingRepo) GetByItemId(itemId *valueobject.ID) (*app.Training, error
 	for _, sliceId := range sliceArr {
 		training.Slices = append(training.Slices, valueobject.ID(sliceId))
 	}
+	training.Filters = idsFromArray(filterArr)
 
 	training.Meta, err = r.getMeta(training.Id)
 	if err != nil {
@@ -168,11 +174,9 @@ func (r *TrainingRepo) GetBySlices(inTraining app.Training) (*app.Training, erro
 	training := &inTraining
 	query := `
 		SELECT id FROM trainings
-		WHERE owner_id=? AND type=? AND transcription_type=? AND slices = array[?]::smallint[]
+		WHERE owner_id=$1 AND type=$2 AND transcription_type=$3 AND slices=$4::smallint[] AND filters=$5::int[]
 	`
-	query, args, err := sqlx.In(query, inTraining.OwnerId, inTraining.Type, inTraining.TranscriptionTypeId, inTraining.Slices)
-	query = r.db.Db().Rebind(query)
-	err = r.db.Db().QueryRow(query, args...).
+	err := r.db.Db().QueryRow(query, inTraining.OwnerId, inTraining.Type, inTraining.TranscriptionTypeId, pq.Array(inTraining.Slices), pq.Array(inTraining.Filters)).
 		Scan(&training.Id)
 	if err != nil {
 		return nil, err
@@ -220,12 +224,24 @@ func (r *TrainingRepo) ItemAnswers(itemId *valueobject.ID) ([]*app.TrainingAnswe
 	query := `
 		SELECT e.id, e.value, t.id FROM expressions e
 		LEFT JOIN translations t ON t.target_id=e.id
-		LEFT JOIN node_translation nt ON nt.translation_id=t.id
+		JOIN node_translation_links(?::int[], ?) nt ON nt.translation_id=t.id
 		LEFT JOIN training_items ti ON ti.translation_id=t.id
-		WHERE ti.id=? AND nt.node_id IN (?)
+		WHERE ti.id=?
 	`
+	args := []interface{}{pq.Array(training.Slices), training.OwnerId, itemId}
+	// Items of saved filters may come from any slice, so the answer is the translation target itself.
+	if len(training.Filters) > 0 {
+		query = `
+			SELECT e.id, e.value, t.id FROM training_items ti
+			LEFT JOIN translations t ON t.id=ti.translation_id
+			LEFT JOIN expressions e ON e.id=t.target_id
+			WHERE ti.id=?
+		`
+		args = args[2:]
+	}
+
 	answers := []*app.TrainingAnswer{}
-	query, args, err := sqlx.In(query, itemId, training.Slices)
+	query, args, err = sqlx.In(query, args...)
 	query = r.db.Db().Rebind(query)
 	rows, err := r.db.Db().Query(query, args...)
 	if err != nil {
@@ -265,6 +281,27 @@ func (r *TrainingRepo) MarkItemAsComplete(itemId *valueobject.ID) error {
 	return nil
 }
 
+// MarkItemAsFailed records a failure of the item translation for the training owner.
+func (r *TrainingRepo) MarkItemAsFailed(itemId *valueobject.ID) error {
+	query := `
+		INSERT INTO training_failures (user_id, translation_id)
+		SELECT t.owner_id, ti.translation_id FROM training_items ti
+		LEFT JOIN trainings t ON t.id=ti.training_id
+		WHERE ti.id=$1
+	`
+	_, err := r.db.Db().Exec(query, itemId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *TrainingRepo) HasCreatePermission(userId *valueobject.ID, nodes []valueobject.ID) bool {
-	return true
+	ids, err := selectReadableNodeIds(r.db, userId, nodes)
+	if err != nil {
+		return false
+	}
+
+	return len(ids) == len(nodes)
 }
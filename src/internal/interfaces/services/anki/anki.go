@@ -0,0 +1,379 @@
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/pkg/sqlite"
+)
+
+const (
+	fieldSeparator = "\x1f"
+	// Newer collections keep deck names separated with this char in the decks table.
+	deckNameSeparator = "\x1f"
+
+	maxCollectionSize = 256 << 20
+)
+
+var (
+	lineBreakRegexp = regexp.MustCompile(`(?i)<br\s*/?>|</?(div|p|li)[^>]*>`)
+	tagRegexp       = regexp.MustCompile(`<[^>]*>`)
+	soundRegexp     = regexp.MustCompile(`\[sound:[^\]]*\]`)
+)
+
+type AnkiService struct{}
+
+// ReadPackage extracts decks and notes from .apkg file.
+func (s *AnkiService) ReadPackage(data []byte) (*app.DeckPackage, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("Invalid Anki package, zip archive expected.")
+	}
+
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var collection *zip.File
+	for _, name := range []string{"collection.anki21", "collection.anki2"} {
+		if file, ok := files[name]; ok {
+			collection = file
+			break
+		}
+	}
+
+	if collection == nil {
+		if _, ok := files["collection.anki21b"]; ok {
+			return nil, errors.New("Compressed Anki collections are not supported, export the deck with \"Support older Anki versions\" option.")
+		}
+		return nil, errors.New("Invalid Anki package, collection is missing.")
+	}
+
+	if collection.UncompressedSize64 > maxCollectionSize {
+		return nil, errors.New("Anki collection is too large.")
+	}
+
+	reader, err := collection.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxCollectionSize))
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sqlite.Open(content)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Anki collection: %s", err)
+	}
+
+	return readCollection(db)
+}
+
+// tableRows reads the collection table, a missing or corrupted table means the collection is invalid.
+func tableRows(db *sqlite.DB, name string) ([]sqlite.Row, error) {
+	rows, err := db.Rows(name)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Anki collection: %s", err)
+	}
+
+	return rows, nil
+}
+
+func readCollection(db *sqlite.DB) (*app.DeckPackage, error) {
+	deckNames, err := readDeckNames(db)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := tableRows(db, "cards")
+	if err != nil {
+		return nil, err
+	}
+
+	// A note goes to the deck of its first card.
+	noteDecks := make(map[int64]int64)
+	noteOrds := make(map[int64]int64)
+	for _, card := range cards {
+		nid, _ := card["nid"].(int64)
+		did, _ := card["did"].(int64)
+		ord, _ := card["ord"].(int64)
+
+		if prevOrd, ok := noteOrds[nid]; !ok || ord < prevOrd {
+			noteDecks[nid] = did
+			noteOrds[nid] = ord
+		}
+	}
+
+	notes, err := tableRows(db, "notes")
+	if err != nil {
+		return nil, err
+	}
+
+	decks := make(map[string]*app.Deck)
+	for _, name := range deckNames {
+		decks[name] = &app.Deck{Name: name, Notes: []*app.DeckNote{}}
+	}
+
+	for _, note := range notes {
+		id, _ := note["id"].(int64)
+		flds, _ := note["flds"].(string)
+		tags, _ := note["tags"].(string)
+
+		did, ok := noteDecks[id]
+		if !ok {
+			continue
+		}
+
+		name, ok := deckNames[did]
+		if !ok {
+			name = "Default"
+			if _, ok := decks[name]; !ok {
+				decks[name] = &app.Deck{Name: name, Notes: []*app.DeckNote{}}
+			}
+		}
+
+		fields := strings.Split(flds, fieldSeparator)
+		for i, field := range fields {
+			fields[i] = cleanField(field)
+		}
+
+		decks[name].Notes = append(decks[name].Notes, &app.DeckNote{
+			Fields: fields,
+			Tags:   strings.Fields(tags),
+		})
+	}
+
+	pkg := &app.DeckPackage{Decks: []*app.Deck{}}
+	for _, deck := range decks {
+		pkg.Decks = append(pkg.Decks, deck)
+	}
+	sort.Slice(pkg.Decks, func(a, b int) bool {
+		return pkg.Decks[a].Name < pkg.Decks[b].Name
+	})
+
+	return pkg, nil
+}
+
+func readDeckNames(db *sqlite.DB) (map[int64]string, error) {
+	names := make(map[int64]string)
+
+	if db.HasTable("decks") {
+		decks, err := tableRows(db, "decks")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, deck := range decks {
+			id, _ := deck["id"].(int64)
+			name, _ := deck["name"].(string)
+			names[id] = strings.ReplaceAll(name, deckNameSeparator, app.AnkiDeckSeparator)
+		}
+
+		if len(names) > 0 {
+			return names, nil
+		}
+	}
+
+	cols, err := tableRows(db, "col")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cols) == 0 {
+		return nil, errors.New("Invalid Anki collection, col record is missing.")
+	}
+
+	decksJson, _ := cols[0]["decks"].(string)
+	decks := make(map[string]struct {
+		Name string `json:"name"`
+	})
+	if err = json.Unmarshal([]byte(decksJson), &decks); err != nil {
+		return nil, errors.New("Invalid Anki collection, decks can't be parsed.")
+	}
+
+	for idArg, deck := range decks {
+		id, err := strconv.ParseInt(idArg, 10, 64)
+		if err != nil {
+			continue
+		}
+		names[id] = deck.Name
+	}
+
+	return names, nil
+}
+
+// cleanField turns note field HTML into plain text.
+func cleanField(value string) string {
+	value = soundRegexp.ReplaceAllString(value, " ")
+	value = lineBreakRegexp.ReplaceAllString(value, " ")
+	value = tagRegexp.ReplaceAllString(value, "")
+	value = html.UnescapeString(value)
+
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// WritePackage builds .apkg file with a note per expression in the decks.
+func (s *AnkiService) WritePackage(pkg app.DeckPackage) ([]byte, error) {
+	now := time.Now()
+	mod := now.Unix()
+	modMs := now.UnixNano() / int64(time.Millisecond)
+	modelId := modMs
+
+	db := sqlite.NewWriter()
+	for _, table := range collectionTables {
+		if err := db.CreateTable(table.name, table.sql); err != nil {
+			return nil, err
+		}
+	}
+	for _, index := range collectionIndexes {
+		if err := db.CreateIndex(index.name, index.table, index.sql); err != nil {
+			return nil, err
+		}
+	}
+
+	decks := map[string]interface{}{
+		strconv.Itoa(defaultDeckId): deckJson(defaultDeckId, "Default", mod),
+	}
+	deckIds := make(map[string]int64)
+	nextId := modMs
+
+	// Parent decks are listed explicitly so that the hierarchy survives import.
+	for _, deck := range pkg.Decks {
+		parts := strings.Split(deck.Name, app.AnkiDeckSeparator)
+		for i := range parts {
+			name := strings.Join(parts[:i+1], app.AnkiDeckSeparator)
+			if _, ok := deckIds[name]; ok {
+				continue
+			}
+
+			nextId++
+			deckIds[name] = nextId
+			decks[strconv.FormatInt(nextId, 10)] = deckJson(nextId, name, mod)
+		}
+	}
+
+	noteCount := 0
+	for _, deck := range pkg.Decks {
+		deckId := deckIds[deck.Name]
+
+		for _, note := range deck.Notes {
+			noteCount++
+			nextId++
+
+			plain := make([]string, len(noteFields))
+			copy(plain, note.Fields)
+
+			fields := make([]string, len(plain))
+			for i, value := range plain {
+				fields[i] = html.EscapeString(value)
+			}
+			flds := strings.Join(fields, fieldSeparator)
+
+			tags := ""
+			if len(note.Tags) > 0 {
+				tags = " " + strings.Join(note.Tags, " ") + " "
+			}
+
+			var sfld interface{} = plain[0]
+			if number, err := strconv.ParseInt(plain[0], 10, 64); err == nil {
+				sfld = number
+			}
+
+			err := db.Insert("notes", nextId, noteGuid(deck.Name, flds), modelId, mod, -1, tags, flds, sfld, fieldChecksum(fields[0]), 0, "")
+			if err != nil {
+				return nil, err
+			}
+
+			err = db.Insert("cards", nextId, nextId, deckId, 0, mod, -1, 0, 0, noteCount, 0, 0, 0, 0, 0, 0, 0, 0, "")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	models := map[string]interface{}{
+		strconv.FormatInt(modelId, 10): modelJson(modelId, defaultDeckId, mod),
+	}
+	dconf := map[string]interface{}{
+		strconv.Itoa(defaultConfigId): deckConfigJson(mod),
+	}
+
+	values := []interface{}{}
+	for _, value := range []interface{}{collectionConfJson(modelId, noteCount+1), models, decks, dconf} {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(data))
+	}
+
+	year, month, day := now.Date()
+	crt := time.Date(year, month, day, 4, 0, 0, 0, now.Location()).Unix()
+
+	err := db.Insert("col", 1, crt, modMs, modMs, collectionVersion, 0, 0, 0, values[0], values[1], values[2], values[3], "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := db.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{"collection.anki2", collection},
+		{"media", []byte("{}")},
+	} {
+		file, err := archive.Create(entry.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = file.Write(entry.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// noteGuid is stable for the same content so that reimport updates notes instead of duplicating them.
+func noteGuid(deck string, flds string) string {
+	sum := sha1.Sum([]byte(deck + fieldSeparator + flds))
+	return base64.RawStdEncoding.EncodeToString(sum[:8])
+}
+
+// fieldChecksum is the first 8 hex digits of the sha1 of the field stripped from HTML, as Anki computes it.
+func fieldChecksum(value string) int64 {
+	sum := sha1.Sum([]byte(cleanField(value)))
+	checksum, _ := strconv.ParseInt(hex.EncodeToString(sum[:4]), 16, 64)
+
+	return checksum
+}
@@ -0,0 +1,264 @@
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/pkg/sqlite"
+)
+
+// zipFiles builds an .apkg archive with the given files.
+func zipFiles(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for name, data := range files {
+		file, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = file.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// legacyCollection builds a collection of older Anki versions which keep decks
+// as JSON in the col table instead of the decks table.
+func legacyCollection(t *testing.T) []byte {
+	db := sqlite.NewWriter()
+	for _, table := range collectionTables {
+		if err := db.CreateTable(table.name, table.sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	decks := `{"1": {"name": "Default"}, "1500": {"name": "Chinese::HSK 1"}, "oops": {"name": "Skipped"}}`
+	err := db.Insert("col", 1, 0, 0, 0, 11, 0, 0, 0, "{}", "{}", decks, "{}", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notes := []struct {
+		id   int64
+		flds string
+		tags string
+	}{
+		{100, "你好\x1fhello<br>hi [sound:nihao.mp3]", " greeting hsk1 "},
+		{200, "<div>谢谢</div>\x1fthank&nbsp;you", ""},
+		{300, "孤儿\x1forphan", ""},
+	}
+	for _, note := range notes {
+		err = db.Insert("notes", note.id, "guid", 1, 0, -1, note.tags, note.flds, "", 0, 0, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cards := []struct {
+		id, nid, did, ord int64
+	}{
+		{1, 100, 1500, 1},
+		{2, 100, 1, 0},
+		{3, 200, 1500, 0},
+		{4, 300, 77, 0},
+	}
+	for _, card := range cards {
+		err = db.Insert("cards", card.id, card.nid, card.did, card.ord, 0, -1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := db.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestReadPackageLegacyCollection(t *testing.T) {
+	pkg, err := (&AnkiService{}).ReadPackage(zipFiles(t, map[string][]byte{
+		"collection.anki2": legacyCollection(t),
+		"media":            []byte("{}"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &app.DeckPackage{Decks: []*app.Deck{
+		{Name: "Chinese::HSK 1", Notes: []*app.DeckNote{
+			{Fields: []string{"谢谢", "thank you"}, Tags: []string{}},
+		}},
+		{Name: "Default", Notes: []*app.DeckNote{
+			{Fields: []string{"你好", "hello hi"}, Tags: []string{"greeting", "hsk1"}},
+			{Fields: []string{"孤儿", "orphan"}, Tags: []string{}},
+		}},
+	}}
+	if !reflect.DeepEqual(pkg, want) {
+		t.Errorf("ReadPackage() = %s, want %s", dump(pkg), dump(want))
+	}
+}
+
+func TestWriteReadPackage(t *testing.T) {
+	in := app.DeckPackage{Decks: []*app.Deck{
+		{Name: "Lang::Words", Notes: []*app.DeckNote{
+			{Fields: []string{"<b>", "bold & brave"}, Tags: []string{"html"}},
+			{Fields: []string{"42", "answer"}},
+		}},
+	}}
+
+	data, err := (&AnkiService{}).WritePackage(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := (&AnkiService{}).ReadPackage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decks := make(map[string]*app.Deck)
+	for _, deck := range pkg.Decks {
+		decks[deck.Name] = deck
+	}
+
+	for _, name := range []string{"Default", "Lang", "Lang::Words"} {
+		if _, ok := decks[name]; !ok {
+			t.Errorf("deck %q is missing in %s", name, dump(pkg))
+		}
+	}
+
+	words := decks["Lang::Words"]
+	if words == nil || len(words.Notes) != 2 {
+		t.Fatalf("Lang::Words = %s", dump(words))
+	}
+	if got := words.Notes[0]; got.Fields[0] != "<b>" || got.Fields[1] != "bold & brave" || !reflect.DeepEqual(got.Tags, []string{"html"}) {
+		t.Errorf("first note = %s", dump(got))
+	}
+	if got := words.Notes[1]; got.Fields[0] != "42" || len(got.Tags) != 0 {
+		t.Errorf("second note = %s", dump(got))
+	}
+}
+
+func TestReadPackageInvalid(t *testing.T) {
+	collection := legacyCollection(t)
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not a zip", []byte("definitely not a zip"), "zip archive expected"},
+		{"empty zip", zipFiles(t, map[string][]byte{}), "collection is missing"},
+		{"collection without notes", zipFiles(t, map[string][]byte{"collection.anki2": emptyDatabase(t)}), "Invalid Anki collection"},
+		{"compressed collection", zipFiles(t, map[string][]byte{"collection.anki21b": {1, 2, 3}}), "Compressed Anki collections"},
+		{"collection isn't sqlite", zipFiles(t, map[string][]byte{"collection.anki2": []byte("hello")}), "Invalid Anki collection"},
+		{"truncated collection", zipFiles(t, map[string][]byte{"collection.anki2": collection[:len(collection)/2]}), "Invalid Anki collection"},
+		{"collection header only", zipFiles(t, map[string][]byte{"collection.anki2": collection[:100]}), "Invalid Anki collection"},
+		{"truncated archive", zipFiles(t, map[string][]byte{"collection.anki2": collection})[:200], "zip archive expected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&AnkiService{}).ReadPackage(tt.data)
+			if err == nil {
+				t.Fatal("ReadPackage() succeeded")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadPackage() error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestReadPackageCorruptedCollection flips bytes of the collection,
+// reading it must fail with an error or succeed but never panic.
+func TestReadPackageCorruptedCollection(t *testing.T) {
+	valid := legacyCollection(t)
+
+	for pos := 0; pos < len(valid); pos += 7 {
+		data := append([]byte{}, valid...)
+		data[pos] ^= 0xff
+		apkg := zipFiles(t, map[string][]byte{"collection.anki2": data})
+
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("byte %d: panic: %v", pos, r)
+				}
+			}()
+
+			(&AnkiService{}).ReadPackage(apkg)
+		}()
+	}
+}
+
+func TestCleanField(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"  spaced \n out  ", "spaced out"},
+		{"one<br>two<BR/>three", "one two three"},
+		{"<div>a</div><div>b</div>", "a b"},
+		{"<b>bold</b> <span style=\"x\">text</span>", "bold text"},
+		{"tom &amp; jerry &lt;3", "tom & jerry <3"},
+		{"word [sound:word.mp3]", "word"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := cleanField(tt.value); got != tt.want {
+			t.Errorf("cleanField(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func dump(v interface{}) string {
+	var b strings.Builder
+	switch x := v.(type) {
+	case *app.DeckPackage:
+		for _, deck := range x.Decks {
+			b.WriteString(dump(deck) + "; ")
+		}
+	case *app.Deck:
+		if x == nil {
+			return "<nil>"
+		}
+		b.WriteString(x.Name + ":")
+		for _, note := range x.Notes {
+			b.WriteString(" " + dump(note))
+		}
+	case *app.DeckNote:
+		b.WriteString("[" + strings.Join(x.Fields, "|") + " #" + strings.Join(x.Tags, ",") + "]")
+	}
+
+	return b.String()
+}
+
+func emptyDatabase(t *testing.T) []byte {
+	db := sqlite.NewWriter()
+	if err := db.CreateTable("col", "CREATE TABLE col (id integer primary key, decks text)"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := db.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
@@ -0,0 +1,221 @@
+package anki
+
+// Anki collection schema version 11 which every Anki release can import.
+const collectionVersion = 11
+
+var collectionTables = []struct {
+	name string
+	sql  string
+}{
+	{"col", `CREATE TABLE col (
+    id              integer primary key,
+    crt             integer not null,
+    mod             integer not null,
+    scm             integer not null,
+    ver             integer not null,
+    dty             integer not null,
+    usn             integer not null,
+    ls              integer not null,
+    conf            text not null,
+    models          text not null,
+    decks           text not null,
+    dconf           text not null,
+    tags            text not null
+)`},
+	{"notes", `CREATE TABLE notes (
+    id              integer primary key,
+    guid            text not null,
+    mid             integer not null,
+    mod             integer not null,
+    usn             integer not null,
+    tags            text not null,
+    flds            text not null,
+    sfld            integer not null,
+    csum            integer not null,
+    flags           integer not null,
+    data            text not null
+)`},
+	{"cards", `CREATE TABLE cards (
+    id              integer primary key,
+    nid             integer not null,
+    did             integer not null,
+    ord             integer not null,
+    mod             integer not null,
+    usn             integer not null,
+    type            integer not null,
+    queue           integer not null,
+    due             integer not null,
+    ivl             integer not null,
+    factor          integer not null,
+    reps            integer not null,
+    lapses          integer not null,
+    left            integer not null,
+    odue            integer not null,
+    odid            integer not null,
+    flags           integer not null,
+    data            text not null
+)`},
+	{"revlog", `CREATE TABLE revlog (
+    id              integer primary key,
+    cid             integer not null,
+    usn             integer not null,
+    ease            integer not null,
+    ivl             integer not null,
+    lastIvl         integer not null,
+    factor          integer not null,
+    time            integer not null,
+    type            integer not null
+)`},
+	{"graves", `CREATE TABLE graves (
+    usn             integer not null,
+    oid             integer not null,
+    type            integer not null
+)`},
+}
+
+var collectionIndexes = []struct {
+	name  string
+	table string
+	sql   string
+}{
+	{"ix_notes_usn", "notes", "CREATE INDEX ix_notes_usn on notes (usn)"},
+	{"ix_cards_usn", "cards", "CREATE INDEX ix_cards_usn on cards (usn)"},
+	{"ix_revlog_usn", "revlog", "CREATE INDEX ix_revlog_usn on revlog (usn)"},
+	{"ix_cards_nid", "cards", "CREATE INDEX ix_cards_nid on cards (nid)"},
+	{"ix_cards_sched", "cards", "CREATE INDEX ix_cards_sched on cards (did, queue, due)"},
+	{"ix_revlog_cid", "revlog", "CREATE INDEX ix_revlog_cid on revlog (cid)"},
+	{"ix_notes_csum", "notes", "CREATE INDEX ix_notes_csum on notes (csum)"},
+}
+
+// Exported notes use a single note type with these fields.
+var noteFields = []string{"Expression", "Translation", "Transcription"}
+
+const (
+	defaultDeckId   = 1
+	defaultConfigId = 1
+
+	cardQuestion = "{{Expression}}"
+	cardAnswer   = "{{FrontSide}}\n\n<hr id=answer>\n\n{{Translation}}<br>\n<i>{{Transcription}}</i>"
+	cardCss      = ".card {\n font-family: arial;\n font-size: 20px;\n text-align: center;\n color: black;\n background-color: white;\n}\n"
+)
+
+func modelJson(modelId int64, deckId int64, mod int64) map[string]interface{} {
+	fields := []map[string]interface{}{}
+	for ord, name := range noteFields {
+		fields = append(fields, map[string]interface{}{
+			"name":   name,
+			"ord":    ord,
+			"sticky": false,
+			"rtl":    false,
+			"font":   "Arial",
+			"size":   20,
+			"media":  []string{},
+		})
+	}
+
+	return map[string]interface{}{
+		"id":        modelId,
+		"name":      "Lst Vocabulary",
+		"type":      0,
+		"mod":       mod,
+		"usn":       -1,
+		"sortf":     0,
+		"did":       deckId,
+		"flds":      fields,
+		"css":       cardCss,
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"latexsvg":  false,
+		"req":       []interface{}{[]interface{}{0, "any", []int{0}}},
+		"tags":      []string{},
+		"vers":      []interface{}{},
+		"tmpls": []map[string]interface{}{
+			{
+				"name":  "Card 1",
+				"ord":   0,
+				"qfmt":  cardQuestion,
+				"afmt":  cardAnswer,
+				"did":   nil,
+				"bqfmt": "",
+				"bafmt": "",
+			},
+		},
+	}
+}
+
+func deckJson(deckId int64, name string, mod int64) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               deckId,
+		"name":             name,
+		"mod":              mod,
+		"usn":              -1,
+		"desc":             "",
+		"dyn":              0,
+		"conf":             defaultConfigId,
+		"collapsed":        false,
+		"browserCollapsed": false,
+		"extendNew":        10,
+		"extendRev":        50,
+		"newToday":         []int{0, 0},
+		"revToday":         []int{0, 0},
+		"lrnToday":         []int{0, 0},
+		"timeToday":        []int{0, 0},
+	}
+}
+
+func deckConfigJson(mod int64) map[string]interface{} {
+	return map[string]interface{}{
+		"id":       defaultConfigId,
+		"name":     "Default",
+		"mod":      mod,
+		"usn":      0,
+		"dyn":      false,
+		"maxTaken": 60,
+		"timer":    0,
+		"autoplay": true,
+		"replayq":  true,
+		"new": map[string]interface{}{
+			"delays":        []float64{1, 10},
+			"ints":          []int{1, 4, 7},
+			"initialFactor": 2500,
+			"order":         1,
+			"perDay":        20,
+			"bury":          false,
+			"separate":      true,
+		},
+		"rev": map[string]interface{}{
+			"perDay":     200,
+			"ease4":      1.3,
+			"fuzz":       0.05,
+			"ivlFct":     1,
+			"maxIvl":     36500,
+			"bury":       false,
+			"minSpace":   1,
+			"hardFactor": 1.2,
+		},
+		"lapse": map[string]interface{}{
+			"delays":      []float64{10},
+			"mult":        0,
+			"minInt":      1,
+			"leechFails":  8,
+			"leechAction": 1,
+		},
+	}
+}
+
+func collectionConfJson(modelId int64, nextPos int) map[string]interface{} {
+	return map[string]interface{}{
+		"activeDecks":   []int{defaultDeckId},
+		"curDeck":       defaultDeckId,
+		"curModel":      modelId,
+		"nextPos":       nextPos,
+		"estTimes":      true,
+		"sortType":      "noteFld",
+		"sortBackwards": false,
+		"timeLim":       0,
+		"addToCur":      true,
+		"newSpread":     0,
+		"dueCounts":     true,
+		"collapseTime":  1200,
+	}
+}
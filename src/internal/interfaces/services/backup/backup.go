@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+)
+
+const (
+	manifestFile = "manifest.json"
+	groupFile    = "group.json"
+	textsDir     = "texts/"
+
+	maxArchiveFileSize = 64 << 20
+)
+
+// manifest is read first to learn the archive version before the content is parsed.
+type manifest struct {
+	Version   uint      `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Group     string    `json:"group"`
+	Nodes     int       `json:"nodes"`
+	Members   int       `json:"members"`
+	Texts     []string  `json:"texts"`
+}
+
+type BackupService struct{}
+
+func textFile(node *app.BackupNode) string {
+	return fmt.Sprintf("%s%d.txt", textsDir, node.Key)
+}
+
+func writeFile(archive *zip.Writer, name string, data []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// Pack writes the group backup into zip archive, text contents are kept as separate files.
+func (s *BackupService) Pack(backup *app.GroupBackup) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	meta := manifest{
+		Version:   backup.Version,
+		CreatedAt: backup.CreatedAt,
+		Group:     backup.Group.Name,
+		Nodes:     len(backup.Nodes),
+		Members:   len(backup.Members),
+		Texts:     []string{},
+	}
+
+	for _, node := range backup.Nodes {
+		if node.Text == nil {
+			continue
+		}
+
+		name := textFile(node)
+		if err := writeFile(archive, name, []byte(node.Text.Content)); err != nil {
+			return nil, err
+		}
+		meta.Texts = append(meta.Texts, name)
+	}
+
+	for name, value := range map[string]interface{}{manifestFile: meta, groupFile: backup} {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		if err = writeFile(archive, name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func readFile(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > maxArchiveFileSize {
+		return nil, fmt.Errorf("File \"%s\" of the backup is too large.", file.Name)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(io.LimitReader(rc, maxArchiveFileSize))
+}
+
+// Unpack reads the group backup from zip archive made by Pack.
+func (s *BackupService) Unpack(data []byte) (*app.GroupBackup, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("Invalid backup, zip archive expected.")
+	}
+
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	for _, name := range []string{manifestFile, groupFile} {
+		if _, ok := files[name]; !ok {
+			return nil, fmt.Errorf("Invalid backup, %s is missing.", name)
+		}
+	}
+
+	content, err := readFile(files[manifestFile])
+	if err != nil {
+		return nil, err
+	}
+
+	meta := manifest{}
+	if err = json.Unmarshal(content, &meta); err != nil {
+		return nil, errors.New("Invalid backup, manifest can't be parsed.")
+	}
+
+	if meta.Version == 0 || meta.Version > app.BackupVersion {
+		return nil, fmt.Errorf("Backup version %d is not supported, the latest supported version is %d.", meta.Version, app.BackupVersion)
+	}
+
+	content, err = readFile(files[groupFile])
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &app.GroupBackup{}
+	if err = json.Unmarshal(content, backup); err != nil {
+		return nil, errors.New("Invalid backup, group content can't be parsed.")
+	}
+
+	if backup.Group == nil {
+		return nil, errors.New("Invalid backup, group is missing.")
+	}
+
+	for _, node := range backup.Nodes {
+		if node == nil {
+			return nil, errors.New("Invalid backup, empty node found.")
+		}
+
+		if node.Text == nil {
+			continue
+		}
+
+		name := textFile(node)
+		file, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("Invalid backup, %s is missing.", name)
+		}
+
+		content, err = readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		node.Text.Content = strings.ToValidUTF8(string(content), "")
+	}
+
+	return backup, nil
+}
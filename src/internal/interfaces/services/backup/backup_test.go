@@ -0,0 +1,136 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+func testBackup() *app.GroupBackup {
+	position := 1
+
+	return &app.GroupBackup{
+		Version:   app.BackupVersion,
+		CreatedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		Group: &app.BackupGroup{
+			Name:              "Chinese",
+			TargetLangCode:    "zh",
+			NativeLangCode:    "en",
+			TranscriptionType: "pinyin",
+			NodeOrder:         []valueobject.ID{1, 2},
+		},
+		Nodes: []*app.BackupNode{
+			{Key: 1, Type: app.NodeFolder, Name: "HSK", Path: []valueobject.ID{}, Expressions: []*app.BackupExpression{}},
+			{
+				Key:  2,
+				Type: app.NodeSlice,
+				Name: "Lesson 1",
+				Path: []valueobject.ID{1},
+				Text: &app.BackupText{Title: "Dialogue", Content: "你好！\n谢谢。", CreatedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+				Expressions: []*app.BackupExpression{{
+					Value:          "你好",
+					Position:       &position,
+					Transcriptions: []string{"nǐ hǎo"},
+					Translations:   []*app.BackupTranslation{{Value: "hello", Comment: "greeting", Transcriptions: []string{}}},
+					CreatedAt:      time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC),
+				}},
+			},
+		},
+		Members: []*app.BackupMember{{Username: "li", Email: "li@example.com", Role: app.UserEditor, Status: app.MemberActive}},
+	}
+}
+
+// archive builds a zip archive with the given files.
+func archive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		if err := writeFile(w, name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestPackUnpack(t *testing.T) {
+	s := &BackupService{}
+	backup := testBackup()
+
+	data, err := s.Pack(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Unpack(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, backup) {
+		t.Errorf("Unpack(Pack()) = %+v, want %+v", got, backup)
+	}
+}
+
+func TestUnpackInvalid(t *testing.T) {
+	manifest := `{"version": 1}`
+	group := `{"version": 1, "group": {"name": "Chinese"}, "nodes": [{"key": 5, "text": {"title": "t"}}]}`
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not a zip", []byte("backup"), "zip archive expected"},
+		{"truncated zip", archive(t, map[string]string{manifestFile: manifest, groupFile: group})[:30], "zip archive expected"},
+		{"manifest missing", archive(t, map[string]string{groupFile: group}), "manifest.json is missing"},
+		{"group missing", archive(t, map[string]string{manifestFile: manifest}), "group.json is missing"},
+		{"manifest not json", archive(t, map[string]string{manifestFile: "{", groupFile: group}), "manifest can't be parsed"},
+		{"version missing", archive(t, map[string]string{manifestFile: `{}`, groupFile: group}), "Backup version 0 is not supported"},
+		{"newer version", archive(t, map[string]string{manifestFile: `{"version": 99}`, groupFile: group}), "Backup version 99 is not supported"},
+		{"group not json", archive(t, map[string]string{manifestFile: manifest, groupFile: "[1, 2"}), "group content can't be parsed"},
+		{"group content missing", archive(t, map[string]string{manifestFile: manifest, groupFile: `{"nodes": []}`}), "group is missing"},
+		{"null node", archive(t, map[string]string{manifestFile: manifest, groupFile: `{"group": {}, "nodes": [null]}`}), "empty node found"},
+		{"text missing", archive(t, map[string]string{manifestFile: manifest, groupFile: group}), "texts/5.txt is missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&BackupService{}).Unpack(tt.data)
+			if err == nil {
+				t.Fatal("Unpack() succeeded")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Unpack() error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnpackInvalidText(t *testing.T) {
+	data := archive(t, map[string]string{
+		manifestFile:  `{"version": 1}`,
+		groupFile:     `{"group": {"name": "G"}, "nodes": [{"key": 5, "text": {"title": "t"}}]}`,
+		"texts/5.txt": "ok\xffok",
+	})
+
+	backup, err := (&BackupService{}).Unpack(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := backup.Nodes[0].Text.Content; got != "okok" {
+		t.Errorf("text content = %q, want invalid UTF-8 dropped", got)
+	}
+}
@@ -0,0 +1,119 @@
+package dictionary
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain"
+)
+
+const (
+	// maxValueLength is the longest expression value, longer headwords and glosses are skipped
+	maxValueLength = 128
+	maxLineLength  = 1024 * 1024
+)
+
+// cedictLine matches "traditional simplified [pin1 yin1] /gloss/gloss/".
+var cedictLine = regexp.MustCompile(`^(\S+)\s+(\S+)\s+\[([^\]]*)\]\s+/(.*)/\s*$`)
+
+type DictionaryService struct{}
+
+// Parse reads dictionary entries skipping comments, blank and malformed lines.
+func (s *DictionaryService) Parse(reader io.Reader, format app.DictionaryFormat) ([]*app.DictionaryEntry, error) {
+	var parseLine func(string) *app.DictionaryEntry
+
+	switch format {
+	case app.DictionaryCedict:
+		parseLine = parseCedictLine
+	case app.DictionaryTsv:
+		parseLine = parseTsvLine
+	default:
+		return nil, fmt.Errorf("Unknown dictionary format \"%s\".", format)
+	}
+
+	entries := []*app.DictionaryEntry{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if entry := parseLine(line); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func parseCedictLine(line string) *app.DictionaryEntry {
+	match := cedictLine.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+
+	entry := &app.DictionaryEntry{
+		Headword:      match[2],
+		Transcription: domain.PinyinToneMarks(strings.TrimSpace(match[3])),
+	}
+	if match[1] != match[2] {
+		entry.Variant = match[1]
+	}
+
+	return completeEntry(entry, strings.Split(match[4], "/"))
+}
+
+// parseTsvLine reads "headword<TAB>transcription<TAB>translation; translation".
+func parseTsvLine(line string) *app.DictionaryEntry {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 3 {
+		return nil
+	}
+
+	entry := &app.DictionaryEntry{
+		Headword:      strings.TrimSpace(fields[0]),
+		Transcription: strings.TrimSpace(fields[1]),
+	}
+
+	return completeEntry(entry, strings.Split(fields[2], ";"))
+}
+
+// completeEntry keeps translations which fit an expression and drops the entry when none is left.
+func completeEntry(entry *app.DictionaryEntry, translations []string) *app.DictionaryEntry {
+	if entry.Headword == "" || utf8.RuneCountInString(entry.Headword) > maxValueLength ||
+		utf8.RuneCountInString(entry.Variant) > maxValueLength {
+		return nil
+	}
+
+	if utf8.RuneCountInString(entry.Transcription) > maxValueLength {
+		entry.Transcription = ""
+	}
+
+	seen := make(map[string]bool)
+	for _, value := range translations {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] || utf8.RuneCountInString(value) > maxValueLength {
+			continue
+		}
+		seen[value] = true
+		entry.Translations = append(entry.Translations, value)
+	}
+
+	if len(entry.Translations) == 0 {
+		return nil
+	}
+
+	return entry
+}
@@ -0,0 +1,78 @@
+package dictionary
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+)
+
+func TestParseCedict(t *testing.T) {
+	input := strings.Join([]string{
+		"# CC-CEDICT",
+		"#! version=1",
+		"",
+		"你好 你好 [ni3 hao3] /hello/hi/",
+		"謝謝 谢谢 [xie4 xie5] /to thank/thanks/thanks/",
+		"女兒 女儿 [nu:3 er2] /daughter/",
+		"broken line without brackets",
+		"空 空 [kong1] //",
+		"長 长 [" + strings.Repeat("x", 200) + "] /long/",
+		strings.Repeat("字", 200) + " 字 [zi4] /too long/",
+	}, "\n")
+
+	entries, err := (&DictionaryService{}).Parse(strings.NewReader(input), app.DictionaryCedict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*app.DictionaryEntry{
+		{Headword: "你好", Transcription: "nǐ hǎo", Translations: []string{"hello", "hi"}},
+		{Headword: "谢谢", Variant: "謝謝", Transcription: "xiè xie", Translations: []string{"to thank", "thanks"}},
+		{Headword: "女儿", Variant: "女兒", Transcription: "nǚ ér", Translations: []string{"daughter"}},
+		{Headword: "长", Variant: "長", Transcription: "", Translations: []string{"long"}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Parse() = %v, want %v", dump(entries), dump(want))
+	}
+}
+
+func TestParseTsv(t *testing.T) {
+	input := strings.Join([]string{
+		"# headword\ttranscription\ttranslations",
+		"こんにちは\tkonnichiwa\thello; good afternoon;",
+		"ありがとう\t\tthank you",
+		"only\ttwo fields",
+		"  \t  \tnothing",
+		"空\tsora\t ; ",
+	}, "\n")
+
+	entries, err := (&DictionaryService{}).Parse(strings.NewReader(input), app.DictionaryTsv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*app.DictionaryEntry{
+		{Headword: "こんにちは", Transcription: "konnichiwa", Translations: []string{"hello", "good afternoon"}},
+		{Headword: "ありがとう", Translations: []string{"thank you"}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Parse() = %v, want %v", dump(entries), dump(want))
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := (&DictionaryService{}).Parse(strings.NewReader(""), app.DictionaryFormat("xdxf")); err == nil {
+		t.Error("Parse() of an unknown format succeeded")
+	}
+}
+
+func dump(entries []*app.DictionaryEntry) string {
+	parts := []string{}
+	for _, entry := range entries {
+		parts = append(parts, entry.Headword+"|"+entry.Variant+"|"+entry.Transcription+"|"+strings.Join(entry.Translations, ","))
+	}
+
+	return "[" + strings.Join(parts, "; ") + "]"
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+)
+
+const valueSeparator = "; "
+
+type ExportService struct{}
+
+func (s *ExportService) Render(export *app.VocabularyExport, format app.ExportFormat, layout app.ExportLayout) ([]byte, error) {
+	switch format {
+	case app.ExportJson:
+		return json.Marshal(export)
+	case app.ExportCsv:
+		return s.renderCsv(export)
+	case app.ExportHtml:
+		return s.renderHtml(export, layout)
+	case app.ExportPdf:
+		return s.renderPdf(export, layout)
+	}
+
+	return nil, fmt.Errorf("Unknown export format \"%s\".", format)
+}
+
+func sliceTitle(slice *app.ExportSlice) string {
+	return strings.Join(append(append([]string{}, slice.Folders...), slice.Name), " / ")
+}
+
+func translationValues(entry *app.ExportEntry) []string {
+	values := []string{}
+	for _, tr := range entry.Translations {
+		values = append(values, tr.Value)
+	}
+
+	return values
+}
+
+// entryTranscriptions joins expression transcriptions with the ones of its translations.
+func entryTranscriptions(entry *app.ExportEntry) []string {
+	values := []string{}
+	seen := make(map[string]bool)
+
+	add := func(items []string) {
+		for _, item := range items {
+			if !seen[item] {
+				seen[item] = true
+				values = append(values, item)
+			}
+		}
+	}
+
+	add(entry.Transcriptions)
+	for _, tr := range entry.Translations {
+		add(tr.Transcriptions)
+	}
+
+	return values
+}
+
+func entryComments(entry *app.ExportEntry) []string {
+	values := []string{}
+	for _, tr := range entry.Translations {
+		if tr.Comment != "" {
+			values = append(values, tr.Comment)
+		}
+	}
+
+	return values
+}
+
+// renderCsv writes a row per expression with columns matching the vocabulary import.
+func (s *ExportService) renderCsv(export *app.VocabularyExport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write([]string{"slice", "expression", "translation", "transcription", "comment"}); err != nil {
+		return nil, err
+	}
+
+	for _, slice := range export.Slices {
+		for _, entry := range slice.Entries {
+			err := writer.Write([]string{
+				sliceTitle(slice),
+				entry.Expression,
+				strings.Join(translationValues(entry), valueSeparator),
+				strings.Join(entryTranscriptions(entry), valueSeparator),
+				strings.Join(entryComments(entry), valueSeparator),
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *ExportService) renderHtml(export *app.VocabularyExport, layout app.ExportLayout) ([]byte, error) {
+	t, err := template.New("vocabulary.html").Funcs(template.FuncMap{
+		"sliceTitle":     sliceTitle,
+		"transcriptions": func(entry *app.ExportEntry) string { return strings.Join(entryTranscriptions(entry), valueSeparator) },
+		"increment":      func(i int) int { return i + 1 },
+	}).ParseFiles("./assets/export/vocabulary.html")
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{})
+	data["Slices"] = export.Slices
+	data["CreatedAt"] = export.CreatedAt
+	data["Quiz"] = layout == app.ExportQuiz
+
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,179 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/pkg/pdf"
+)
+
+const (
+	pageMargin      = 40.0
+	columnGap       = 15.0
+	rowPadding      = 5.0
+	titleSize       = 15.0
+	expressionSize  = 12.0
+	translationSize = 11.0
+	noteSize        = 9.0
+	noteGray        = 0.4
+	ruleGray        = 0.8
+)
+
+type pdfLine struct {
+	text string
+	size float64
+	gray float64
+}
+
+type pdfSheet struct {
+	doc    *pdf.Document
+	page   *pdf.Page
+	y      float64
+	quiz   bool
+	split  float64
+	number int
+}
+
+func lineHeight(size float64) float64 {
+	return size * 1.3
+}
+
+func linesHeight(lines []pdfLine) float64 {
+	height := 0.0
+	for _, line := range lines {
+		height += lineHeight(line.size)
+	}
+
+	return height
+}
+
+func (s *pdfSheet) newPage() {
+	s.page = s.doc.AddPage()
+	s.y = s.doc.Height() - pageMargin
+
+	if s.quiz {
+		s.page.SetGray(noteGray)
+		s.page.Line(s.split, pageMargin, s.split, s.doc.Height()-pageMargin, 0.5, true)
+		s.page.SetGray(0)
+	}
+}
+
+func (s *pdfSheet) wrap(text string, size float64, gray float64, width float64) []pdfLine {
+	lines := []pdfLine{}
+	for _, line := range s.doc.WrapText(text, size, width) {
+		lines = append(lines, pdfLine{line, size, gray})
+	}
+
+	return lines
+}
+
+func (s *pdfSheet) drawLines(x float64, lines []pdfLine) {
+	y := s.y
+	for _, line := range lines {
+		y -= lineHeight(line.size)
+		s.page.SetGray(line.gray)
+		s.page.Text(x, y+line.size*0.25, line.size, line.text)
+	}
+	s.page.SetGray(0)
+}
+
+func (s *pdfSheet) title(text string) {
+	lines := s.wrap(text, titleSize, 0, s.doc.Width()-2*pageMargin)
+	height := linesHeight(lines) + rowPadding*2
+
+	// Keep the title together with at least one entry.
+	if s.y-height-lineHeight(expressionSize)*2 < pageMargin {
+		s.newPage()
+	}
+
+	s.y -= rowPadding
+	s.drawLines(pageMargin, lines)
+	s.y -= height - rowPadding
+}
+
+func (s *pdfSheet) entry(entry *app.ExportEntry) {
+	leftX := pageMargin
+	leftWidth := s.split - columnGap - leftX
+	rightX := s.split + columnGap
+	rightWidth := s.doc.Width() - pageMargin - rightX
+
+	expression := entry.Expression
+	if s.quiz {
+		s.number++
+		expression = fmt.Sprintf("%d. %s", s.number, expression)
+	}
+
+	left := s.wrap(expression, expressionSize, 0, leftWidth)
+	if transcriptions := entryTranscriptions(entry); len(transcriptions) > 0 {
+		left = append(left, s.wrap(strings.Join(transcriptions, valueSeparator), noteSize, noteGray, leftWidth)...)
+	}
+
+	right := []pdfLine{}
+	for _, tr := range entry.Translations {
+		right = append(right, s.wrap(tr.Value, translationSize, 0, rightWidth)...)
+		if tr.Comment != "" {
+			right = append(right, s.wrap(tr.Comment, noteSize, noteGray, rightWidth)...)
+		}
+	}
+
+	height := linesHeight(left)
+	if rightHeight := linesHeight(right); rightHeight > height {
+		height = rightHeight
+	}
+	height += rowPadding * 2
+
+	if s.y-height < pageMargin {
+		s.newPage()
+	}
+
+	s.y -= rowPadding
+	s.drawLines(leftX, left)
+	s.drawLines(rightX, right)
+	s.y -= height - rowPadding
+
+	if !s.quiz {
+		s.page.SetGray(ruleGray)
+		s.page.Line(pageMargin, s.y, s.doc.Width()-pageMargin, s.y, 0.5, false)
+		s.page.SetGray(0)
+	}
+}
+
+// renderPdf draws a two-column A4 vocabulary sheet. Font given with PDF_FONT_PATH gets
+// embedded, the standard font can't show characters beyond Western European ones.
+func (s *ExportService) renderPdf(export *app.VocabularyExport, layout app.ExportLayout) ([]byte, error) {
+	doc := pdf.New(pdf.A4Width, pdf.A4Height)
+
+	if fontPath := os.Getenv("PDF_FONT_PATH"); fontPath != "" {
+		font, err := os.ReadFile(fontPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = doc.SetFont(font); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet := &pdfSheet{
+		doc:   doc,
+		quiz:  layout == app.ExportQuiz,
+		split: pageMargin + (doc.Width()-2*pageMargin)*0.45,
+	}
+	if sheet.quiz {
+		sheet.split = doc.Width() / 2
+	}
+	sheet.newPage()
+
+	for _, slice := range export.Slices {
+		sheet.title(sliceTitle(slice))
+		sheet.number = 0
+
+		for _, entry := range slice.Entries {
+			sheet.entry(entry)
+		}
+	}
+
+	return doc.Bytes()
+}
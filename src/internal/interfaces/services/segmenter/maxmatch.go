@@ -0,0 +1,91 @@
+package segmenter
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+)
+
+// MaxMatchSegmenter takes the longest word known to expressions or dictionaries of the language
+// at every position. Unknown Han characters are words of their own and unknown runs of one
+// script, e.g. katakana, are kept together.
+type MaxMatchSegmenter struct {
+	LangCode       string
+	DictionaryRepo app.DictionaryRepo
+}
+
+var scripts = []*unicode.RangeTable{unicode.Hiragana, unicode.Katakana, unicode.Latin, unicode.Hangul}
+
+func scriptOf(r rune) *unicode.RangeTable {
+	if r == 'ー' {
+		return unicode.Katakana
+	}
+
+	for _, script := range scripts {
+		if unicode.Is(script, r) {
+			return script
+		}
+	}
+
+	return nil
+}
+
+// longestWord returns the end of the longest known word at the position or the position itself.
+func longestWord(vocabulary map[string]bool, maxWordLength int, runes []rune, pos int) int {
+	end := pos + maxWordLength
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	for ; end > pos; end-- {
+		if vocabulary[string(runes[pos:end])] {
+			return end
+		}
+	}
+
+	return pos
+}
+
+func (s *MaxMatchSegmenter) Segment(content string) ([]*app.Token, error) {
+	vocabulary, err := s.DictionaryRepo.Vocabulary(s.LangCode, content)
+	if err != nil {
+		return nil, err
+	}
+
+	maxWordLength := 0
+	for word := range vocabulary {
+		if length := utf8.RuneCountInString(word); length > maxWordLength {
+			maxWordLength = length
+		}
+	}
+
+	runes := []rune(content)
+	tokens := []*app.Token{}
+
+	for pos := 0; pos < len(runes); {
+		end := longestWord(vocabulary, maxWordLength, runes, pos)
+		r := runes[pos]
+
+		switch {
+		case end > pos:
+			tokens = appendToken(tokens, runes[pos:end], pos, true, false)
+		case !isWordRune(r):
+			end = pos + 1
+			tokens = appendToken(tokens, runes[pos:end], pos, false, true)
+		default:
+			end = pos + 1
+			if script := scriptOf(r); script != nil {
+				for end < len(runes) && scriptOf(runes[end]) == script &&
+					longestWord(vocabulary, maxWordLength, runes, end) == end {
+					end++
+				}
+			}
+			tokens = appendToken(tokens, runes[pos:end], pos, true, false)
+		}
+
+		pos = end
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,43 @@
+package segmenter
+
+import (
+	"unicode"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/app/services"
+)
+
+// unspacedLangs are written without spaces between words.
+var unspacedLangs = map[string]bool{
+	"zh": true,
+	"ja": true,
+}
+
+type SegmenterService struct {
+	DictionaryRepo app.DictionaryRepo
+}
+
+func (s *SegmenterService) Segmenter(langCode string) services.Segmenter {
+	if unspacedLangs[langCode] {
+		return &MaxMatchSegmenter{LangCode: langCode, DictionaryRepo: s.DictionaryRepo}
+	}
+
+	return &SpaceSegmenter{}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// appendToken adds the runes to the previous token of the same kind or starts a new one.
+func appendToken(tokens []*app.Token, runes []rune, offset int, isWord bool, merge bool) []*app.Token {
+	if merge && len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		if last.IsWord == isWord && last.Offset+len([]rune(last.Value)) == offset {
+			last.Value += string(runes)
+			return tokens
+		}
+	}
+
+	return append(tokens, &app.Token{Value: string(runes), Offset: offset, IsWord: isWord})
+}
@@ -0,0 +1,112 @@
+package segmenter
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+)
+
+// vocabularyRepo is a dictionary which knows only the vocabulary words occurring in the content.
+type vocabularyRepo struct {
+	app.DictionaryRepo
+	words []string
+}
+
+func (r *vocabularyRepo) Vocabulary(_ string, content string) (map[string]bool, error) {
+	vocabulary := make(map[string]bool)
+	for _, word := range r.words {
+		if strings.Contains(content, word) {
+			vocabulary[word] = true
+		}
+	}
+
+	return vocabulary, nil
+}
+
+// dump writes word tokens as they are and other tokens in brackets,
+// each token is prefixed with its offset.
+func dump(tokens []*app.Token) string {
+	parts := []string{}
+	for _, token := range tokens {
+		value := token.Value
+		if !token.IsWord {
+			value = "[" + value + "]"
+		}
+		parts = append(parts, strconv.Itoa(token.Offset)+":"+value)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func TestSpaceSegmenter(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"Hello, world!", "0:Hello 5:[, ] 7:world 12:[!]"},
+		{"don't stop", "0:don't 5:[ ] 6:stop"},
+		{"well-known -dash- 'quote'", "0:well-known 10:[ -] 12:dash 16:[- '] 19:quote 24:[']"},
+		{"café naïve", "0:café 4:[ ] 5:naïve"},
+		{"Привет, мир", "0:Привет 6:[, ] 8:мир"},
+		{"42 rue", "0:42 2:[ ] 3:rue"},
+		{"  ", "0:[  ]"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		tokens, err := (&SpaceSegmenter{}).Segment(tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := dump(tokens); got != tt.want {
+			t.Errorf("Segment(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestMaxMatchSegmenter(t *testing.T) {
+	segmenter := &MaxMatchSegmenter{LangCode: "zh", DictionaryRepo: &vocabularyRepo{words: []string{
+		"我", "喜欢", "学习", "中文", "中国", "中国人", "人", "学", "习", "テレビ", "見る",
+	}}}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"我喜欢学习中文。", "0:我 1:喜欢 3:学习 5:中文 7:[。]"},
+		{"中国人", "0:中国人"},
+		{"中国人学", "0:中国人 3:学"},
+		{"龍鳳", "0:龍 1:鳳"},
+		{"我看テレビ", "0:我 1:看 2:テレビ"},
+		{"カタカナ人", "0:カタカナ 4:人"},
+		{"iPhone中文", "0:iPhone 6:中文"},
+		{"我，你", "0:我 1:[，] 2:你"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		tokens, err := segmenter.Segment(tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := dump(tokens); got != tt.want {
+			t.Errorf("Segment(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestSegmenterService(t *testing.T) {
+	service := &SegmenterService{}
+
+	if _, ok := service.Segmenter("zh").(*MaxMatchSegmenter); !ok {
+		t.Error("zh isn't segmented by maximum matching")
+	}
+	if _, ok := service.Segmenter("ja").(*MaxMatchSegmenter); !ok {
+		t.Error("ja isn't segmented by maximum matching")
+	}
+	if _, ok := service.Segmenter("en").(*SpaceSegmenter); !ok {
+		t.Error("en isn't segmented by spaces")
+	}
+}
@@ -0,0 +1,24 @@
+package segmenter
+
+import "github.com/alexkarpovich/lst-api/src/internal/app"
+
+// SpaceSegmenter splits words on spaces and punctuation, apostrophes and hyphens
+// between letters stay within words like "don't" or "well-known".
+type SpaceSegmenter struct{}
+
+func (s *SpaceSegmenter) Segment(content string) ([]*app.Token, error) {
+	runes := []rune(content)
+	tokens := []*app.Token{}
+
+	for pos, r := range runes {
+		isWord := isWordRune(r)
+		if !isWord && (r == '\'' || r == '’' || r == '-') &&
+			pos > 0 && pos+1 < len(runes) && isWordRune(runes[pos-1]) && isWordRune(runes[pos+1]) {
+			isWord = true
+		}
+
+		tokens = appendToken(tokens, runes[pos:pos+1], pos, isWord, true)
+	}
+
+	return tokens, nil
+}
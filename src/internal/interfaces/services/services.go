@@ -3,16 +3,39 @@ package services
 import (
 	"github.com/alexkarpovich/lst-api/src/internal/app/services"
 	"github.com/alexkarpovich/lst-api/src/internal/interfaces/repos"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/services/anki"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/services/backup"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/services/dictionary"
 	"github.com/alexkarpovich/lst-api/src/internal/interfaces/services/email"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/services/export"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/services/segmenter"
+	"github.com/alexkarpovich/lst-api/src/internal/interfaces/services/transcriber"
 )
 
 type Services struct {
-	Email    services.EmailService
-	Training services.TrainingService
+	Email       services.EmailService
+	Training    services.TrainingService
+	Anki        services.AnkiService
+	Export      services.ExportService
+	Backup      services.BackupService
+	Dictionary  services.DictionaryService
+	Transcriber services.TranscriberService
+	Segmenter   services.SegmenterService
 }
 
 func NewServices(repos *repos.Repos) *Services {
 	return &Services{
-		Email: &email.EmailService{},
+		Email:      &email.EmailService{},
+		Anki:       &anki.AnkiService{},
+		Export:     &export.ExportService{},
+		Backup:     &backup.BackupService{},
+		Dictionary: &dictionary.DictionaryService{},
+		Transcriber: &transcriber.TranscriberService{
+			LangRepo:       repos.Lang,
+			DictionaryRepo: repos.Dictionary,
+		},
+		Segmenter: &segmenter.SegmenterService{
+			DictionaryRepo: repos.Dictionary,
+		},
 	}
 }
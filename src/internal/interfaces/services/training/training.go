@@ -10,14 +10,15 @@ type TrainingService struct {
 	Training     app.Training
 	NodeRepo     app.NodeRepo
 	TrainingRepo app.TrainingRepo
+	TagRepo      app.TagRepo
 }
 
-func NewService(nr app.NodeRepo, tr app.TrainingRepo, trn app.Training) services.TrainingService {
+func NewService(nr app.NodeRepo, tr app.TrainingRepo, tgr app.TagRepo, trn app.Training) services.TrainingService {
 	if trn.Type == app.TrainingDirect {
-		return &trainingDirectService{&TrainingService{trn, nr, tr}}
+		return &trainingDirectService{&TrainingService{trn, nr, tr, tgr}}
 	}
 	if trn.Type == app.TrainingCycles {
-		return &trainingCyclesService{&TrainingService{trn, nr, tr}}
+		return &trainingCyclesService{&TrainingService{trn, nr, tr, tgr}}
 	}
 
 	return nil
@@ -30,3 +31,45 @@ func (s *TrainingService) NextItem() (*app.TrainingItem, error) {
 func (s *TrainingService) ItemAnswers(itemId *valueobject.ID) ([]*app.TrainingAnswer, error) {
 	return s.TrainingRepo.ItemAnswers(itemId)
 }
+
+// manualOrder tells whether training items have to follow the manual expression order of slices.
+func (s *TrainingService) manualOrder() (bool, error) {
+	if len(s.Training.Slices) == 0 {
+		return false, nil
+	}
+
+	return s.NodeRepo.HasManualOrder(s.Training.Slices)
+}
+
+// translations collects translations of the slices followed by the ones of saved filters, each once.
+func (s *TrainingService) translations() ([]*app.Translation, error) {
+	translations := []*app.Translation{}
+	if len(s.Training.Slices) > 0 {
+		sliceTranslations, err := s.NodeRepo.TranslationsBySlices(s.Training.Slices, s.Training.OwnerId)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, sliceTranslations...)
+	}
+
+	if len(s.Training.Filters) == 0 {
+		return translations, nil
+	}
+
+	filterTranslations, err := s.TagRepo.TranslationsByFilters(s.Training.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[valueobject.ID]bool)
+	for _, tr := range translations {
+		seen[*tr.Id] = true
+	}
+	for _, tr := range filterTranslations {
+		if !seen[*tr.Id] {
+			translations = append(translations, tr)
+		}
+	}
+
+	return translations, nil
+}
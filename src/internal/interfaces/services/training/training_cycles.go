@@ -18,7 +18,12 @@ type trainingCyclesService struct {
 
 func (s *trainingCyclesService) Create() (*app.Training, error) {
 	training := s.Training
-	translations, err := s.NodeRepo.TranslationsBySlices(training.Slices)
+	translations, err := s.translations()
+	if err != nil {
+		return nil, err
+	}
+
+	manual, err := s.manualOrder()
 	if err != nil {
 		return nil, err
 	}
@@ -28,8 +33,11 @@ func (s *trainingCyclesService) Create() (*app.Training, error) {
 	stageCount := uint(math.Round(math.Log(float64(xCount)/minChunkSize)/math.Log(2))) + 1
 
 	for stage = 1; stage <= uint(stageCount); stage++ {
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(translations), func(i, j int) { translations[i], translations[j] = translations[j], translations[i] })
+		// Chunks of the manual order keep their place, items within a chunk are still asked randomly.
+		if !manual {
+			rand.Seed(time.Now().UnixNano())
+			rand.Shuffle(len(translations), func(i, j int) { translations[i], translations[j] = translations[j], translations[i] })
+		}
 
 		rate := math.Round(float64(xCount) / (minChunkSize * math.Pow(2, float64(stage))))
 		chunkSize := (float64(xCount) + rate - 1) / rate
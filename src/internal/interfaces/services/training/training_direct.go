@@ -10,7 +10,12 @@ type trainingDirectService struct {
 
 func (s *trainingDirectService) Create() (*app.Training, error) {
 	training := s.Training
-	translations, err := s.NodeRepo.TranslationsBySlices(training.Slices)
+	translations, err := s.translations()
+	if err != nil {
+		return nil, err
+	}
+
+	manual, err := s.manualOrder()
 	if err != nil {
 		return nil, err
 	}
@@ -26,6 +31,11 @@ func (s *trainingDirectService) Create() (*app.Training, error) {
 			Complete:      false,
 		}
 
+		// Every item gets its own cycle so that they are asked one by one.
+		if manual {
+			trnItem.Cycle = uint(i + 1)
+		}
+
 		training.Items = append(training.Items, trnItem)
 	}
 
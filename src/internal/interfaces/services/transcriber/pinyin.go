@@ -0,0 +1,72 @@
+package transcriber
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+// PinyinTranscriber splits the value into the longest words known to dictionaries
+// and to transcriptions confirmed by users, then joins their pinyin.
+type PinyinTranscriber struct {
+	TypeId         *valueobject.ID
+	DictionaryRepo app.DictionaryRepo
+}
+
+func (t *PinyinTranscriber) Transcribe(value string) (string, error) {
+	lexicon, err := t.DictionaryRepo.Lexicon(t.TypeId, value)
+	if err != nil {
+		return "", err
+	}
+
+	maxWordLength := 0
+	for word := range lexicon {
+		if length := utf8.RuneCountInString(word); length > maxWordLength {
+			maxWordLength = length
+		}
+	}
+
+	runes := []rune(value)
+	parts := []string{}
+
+	for pos := 0; pos < len(runes); {
+		end := pos + maxWordLength
+		if end > len(runes) {
+			end = len(runes)
+		}
+
+		for ; end > pos; end-- {
+			if transcription, ok := lexicon[string(runes[pos:end])]; ok {
+				parts = append(parts, transcription)
+				break
+			}
+		}
+
+		if end > pos {
+			pos = end
+			continue
+		}
+
+		r := runes[pos]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			// A character nobody knows makes the whole transcription a guess
+			return "", nil
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			end = pos
+			for end < len(runes) && !unicode.Is(unicode.Han, runes[end]) &&
+				(unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			parts = append(parts, string(runes[pos:end]))
+			pos = end
+		default:
+			pos++
+		}
+	}
+
+	return strings.Join(parts, " "), nil
+}
@@ -0,0 +1,130 @@
+package transcriber
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// Markers of kana which depend on the neighbouring syllables
+	sokuon     = "っ"
+	longVowel  = "ー"
+	syllabicN  = "ん"
+	kanaOffset = 'ァ' - 'ぁ'
+)
+
+var kanaSyllables = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+// kanaDigraphs are syllables written with a small kana, katakana ones are given in hiragana.
+var kanaDigraphs = map[string]string{
+	"きゃ": "kya", "きゅ": "kyu", "きょ": "kyo",
+	"ぎゃ": "gya", "ぎゅ": "gyu", "ぎょ": "gyo",
+	"しゃ": "sha", "しゅ": "shu", "しょ": "sho", "しぇ": "she",
+	"じゃ": "ja", "じゅ": "ju", "じょ": "jo", "じぇ": "je",
+	"ちゃ": "cha", "ちゅ": "chu", "ちょ": "cho", "ちぇ": "che",
+	"ぢゃ": "ja", "ぢゅ": "ju", "ぢょ": "jo",
+	"にゃ": "nya", "にゅ": "nyu", "にょ": "nyo",
+	"ひゃ": "hya", "ひゅ": "hyu", "ひょ": "hyo",
+	"びゃ": "bya", "びゅ": "byu", "びょ": "byo",
+	"ぴゃ": "pya", "ぴゅ": "pyu", "ぴょ": "pyo",
+	"みゃ": "mya", "みゅ": "myu", "みょ": "myo",
+	"りゃ": "rya", "りゅ": "ryu", "りょ": "ryo",
+	"てぃ": "ti", "でぃ": "di", "とぅ": "tu", "どぅ": "du",
+	"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo",
+	"うぃ": "wi", "うぇ": "we", "うぉ": "wo", "つぁ": "tsa",
+	"ゔぁ": "va", "ゔぃ": "vi", "ゔぇ": "ve", "ゔぉ": "vo",
+}
+
+// RomajiTranscriber writes hiragana and katakana in the Hepburn romanization,
+// values with kanji can't be transcribed by rules.
+type RomajiTranscriber struct{}
+
+func (t *RomajiTranscriber) Transcribe(value string) (string, error) {
+	runes := []rune(value)
+	for idx, r := range runes {
+		if r >= 'ァ' && r <= 'ヶ' {
+			runes[idx] = r - kanaOffset
+		}
+	}
+
+	tokens := []string{}
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+
+		if idx+1 < len(runes) {
+			if syllable, ok := kanaDigraphs[string(runes[idx:idx+2])]; ok {
+				tokens = append(tokens, syllable)
+				idx++
+				continue
+			}
+		}
+
+		switch {
+		case r == 'っ':
+			tokens = append(tokens, sokuon)
+		case r == 'ー':
+			tokens = append(tokens, longVowel)
+		case r == 'ん':
+			tokens = append(tokens, syllabicN)
+		case kanaSyllables[r] != "":
+			tokens = append(tokens, kanaSyllables[r])
+		case unicode.IsSpace(r):
+			tokens = append(tokens, " ")
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			tokens = append(tokens, strings.ToLower(string(r)))
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		default:
+			return "", nil
+		}
+	}
+
+	var b strings.Builder
+	for idx, token := range tokens {
+		next := ""
+		if idx+1 < len(tokens) {
+			next = tokens[idx+1]
+		}
+
+		switch token {
+		case sokuon:
+			// Doubles the consonant of the next syllable, "tch" is written before "ch"
+			if strings.HasPrefix(next, "ch") {
+				b.WriteByte('t')
+			} else if next != "" && strings.IndexByte("bcdfghjkmprstvwz", next[0]) >= 0 {
+				b.WriteByte(next[0])
+			}
+		case longVowel:
+			written := b.String()
+			if written != "" && strings.IndexByte("aeiou", written[len(written)-1]) >= 0 {
+				b.WriteByte(written[len(written)-1])
+			}
+		case syllabicN:
+			b.WriteByte('n')
+			if next != "" && strings.IndexByte("aeiouy", next[0]) >= 0 {
+				b.WriteByte('\'')
+			}
+		default:
+			b.WriteString(token)
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
@@ -0,0 +1,32 @@
+package transcriber
+
+import (
+	"strings"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/app/services"
+	"github.com/alexkarpovich/lst-api/src/internal/domain"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+type TranscriberService struct {
+	LangRepo       domain.LangRepo
+	DictionaryRepo app.DictionaryRepo
+}
+
+// Transcriber picks the transcriber by the language and the name of the transcription type.
+func (s *TranscriberService) Transcriber(typeId *valueobject.ID) (services.Transcriber, error) {
+	transcriptionType, err := s.LangRepo.GetTranscriptionType(typeId)
+	if err != nil {
+		return nil, err
+	}
+
+	switch transcriptionType.LangCode + ":" + strings.ToLower(transcriptionType.Name) {
+	case "zh:pinyin":
+		return &PinyinTranscriber{TypeId: typeId, DictionaryRepo: s.DictionaryRepo}, nil
+	case "ja:romaji":
+		return &RomajiTranscriber{}, nil
+	}
+
+	return nil, nil
+}
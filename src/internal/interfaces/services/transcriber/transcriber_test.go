@@ -0,0 +1,110 @@
+package transcriber
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexkarpovich/lst-api/src/internal/app"
+	"github.com/alexkarpovich/lst-api/src/internal/domain/valueobject"
+)
+
+func TestRomajiTranscriber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"こんにちは", "konnichiha"},
+		{"ありがとう", "arigatou"},
+		{"きょう", "kyou"},
+		{"がっこう", "gakkou"},
+		{"まっちゃ", "matcha"},
+		{"きっぷ", "kippu"},
+		{"ほんや", "hon'ya"},
+		{"せんえん", "sen'en"},
+		{"しんぶん", "shinbun"},
+		{"コーヒー", "koohii"},
+		{"パーティー", "paatii"},
+		{"ヴァイオリン", "vaiorin"},
+		{"ふぁいる", "fairu"},
+		{"ＯＫです", ""},
+		{"OK です", "ok desu"},
+		{"Tシャツ", "tshatsu"},
+		{"すし、さしみ！", "sushisashimi"},
+		{"にほん ご", "nihon go"},
+		{"っ", ""},
+		{"ー", ""},
+		{"日本", ""},
+		{"ひらがな漢字", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := (&RomajiTranscriber{}).Transcribe(tt.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Transcribe(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// lexiconRepo is a dictionary which knows only the lexicon words occurring in the value.
+type lexiconRepo struct {
+	app.DictionaryRepo
+	words map[string]string
+}
+
+func (r *lexiconRepo) Lexicon(_ *valueobject.ID, value string) (map[string]string, error) {
+	lexicon := make(map[string]string)
+	for word, transcription := range r.words {
+		if strings.Contains(value, word) {
+			lexicon[word] = transcription
+		}
+	}
+
+	return lexicon, nil
+}
+
+func TestPinyinTranscriber(t *testing.T) {
+	transcriber := &PinyinTranscriber{DictionaryRepo: &lexiconRepo{words: map[string]string{
+		"你":   "nǐ",
+		"好":   "hǎo",
+		"你好":  "nǐ hǎo",
+		"中":   "zhōng",
+		"国":   "guó",
+		"中国":  "Zhōng guó",
+		"中国人": "Zhōng guó rén",
+		"人":   "rén",
+		"银行":  "yín háng",
+		"行":   "xíng",
+		"银":   "yín",
+	}}}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"你好", "nǐ hǎo"},
+		{"中国人", "Zhōng guó rén"},
+		{"中国人好", "Zhōng guó rén hǎo"},
+		{"人中国", "rén Zhōng guó"},
+		{"银行", "yín háng"},
+		{"行人", "xíng rén"},
+		{"你好，中国！", "nǐ hǎo Zhōng guó"},
+		{"卡拉OK", ""},
+		{"你好OK123", "nǐ hǎo OK123"},
+		{"A你", "A nǐ"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := transcriber.Transcribe(tt.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Transcribe(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+CREATE TABLE group_links (
+  id serial PRIMARY KEY,
+  group_id INT NOT NULL,
+  author_id INT NOT NULL,
+  token VARCHAR(128) UNIQUE NOT NULL,
+  role SMALLINT NOT NULL,
+  max_uses INT NOT NULL DEFAULT 0,
+  use_count INT NOT NULL DEFAULT 0,
+  status SMALLINT NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_group
+    FOREIGN KEY(group_id) 
+    REFERENCES groups(id),
+  CONSTRAINT fk_author
+    FOREIGN KEY(author_id) 
+    REFERENCES users(id)
+);
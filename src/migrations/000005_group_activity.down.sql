@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS group_activity_group_idx;
+DROP TABLE IF EXISTS group_activity;
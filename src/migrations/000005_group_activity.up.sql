@@ -0,0 +1,19 @@
+CREATE TABLE group_activity (
+  id serial PRIMARY KEY,
+  group_id INT NOT NULL,
+  actor_id INT NOT NULL,
+  action VARCHAR(64) NOT NULL,
+  target_type VARCHAR(32) NOT NULL,
+  target_id INT,
+  before jsonb,
+  after jsonb,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_group
+    FOREIGN KEY(group_id) 
+    REFERENCES groups(id),
+  CONSTRAINT fk_actor
+    FOREIGN KEY(actor_id) 
+    REFERENCES users(id)
+);
+
+CREATE INDEX group_activity_group_idx ON group_activity USING BTREE (group_id, id);
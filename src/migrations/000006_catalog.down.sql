@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS subscriptions;
+
+ALTER TABLE groups
+    DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,15 @@
+ALTER TABLE groups
+    ADD COLUMN visibility SMALLINT NOT NULL DEFAULT 1;
+
+CREATE TABLE subscriptions (
+  user_id INT NOT NULL,
+  group_id INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_user
+    FOREIGN KEY(user_id) 
+    REFERENCES users(id),
+  CONSTRAINT fk_group
+    FOREIGN KEY(group_id) 
+    REFERENCES groups(id),
+  UNIQUE(user_id, group_id)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS groups_deleted_at_idx;
+
+ALTER TABLE groups
+    DROP CONSTRAINT IF EXISTS fk_deleted_by,
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,10 @@
+ALTER TABLE groups
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN deleted_by INT,
+    ADD CONSTRAINT fk_deleted_by
+        FOREIGN KEY (deleted_by)
+        REFERENCES users(id);
+
+UPDATE groups SET deleted_at=NOW() WHERE status=1;
+
+CREATE INDEX groups_deleted_at_idx ON groups (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,32 @@
+CREATE TABLE node_trash (
+  id serial PRIMARY KEY,
+  group_id INT NOT NULL,
+  node_id INT NOT NULL,
+  deleted_by INT NOT NULL,
+  deleted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_group
+    FOREIGN KEY(group_id)
+    REFERENCES groups(id),
+  CONSTRAINT fk_node
+    FOREIGN KEY(node_id)
+    REFERENCES nodes(id),
+  CONSTRAINT fk_deleted_by
+    FOREIGN KEY(deleted_by)
+    REFERENCES users(id)
+);
+
+CREATE INDEX node_trash_group_idx ON node_trash (group_id, deleted_at);
+
+CREATE TABLE node_trash_items (
+  trash_id INT NOT NULL,
+  node_id INT NOT NULL,
+  path ltree NOT NULL,
+  order_idx INT,
+  CONSTRAINT fk_trash
+    FOREIGN KEY(trash_id)
+    REFERENCES node_trash(id),
+  CONSTRAINT fk_node
+    FOREIGN KEY(node_id)
+    REFERENCES nodes(id),
+  UNIQUE(trash_id, node_id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE nodes
+    DROP COLUMN IF EXISTS expression_order;
+
+ALTER TABLE node_expression
+    DROP COLUMN IF EXISTS position;
@@ -0,0 +1,5 @@
+ALTER TABLE node_expression
+    ADD COLUMN position INT;
+
+ALTER TABLE nodes
+    ADD COLUMN expression_order VARCHAR(16) NOT NULL DEFAULT 'created';
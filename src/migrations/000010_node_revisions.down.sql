@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS node_revisions;
+DROP FUNCTION IF EXISTS node_snapshot(INT);
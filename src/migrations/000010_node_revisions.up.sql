@@ -0,0 +1,45 @@
+CREATE TABLE node_revisions (
+  id serial PRIMARY KEY,
+  node_id INT NOT NULL,
+  number INT NOT NULL,
+  actor_id INT,
+  action VARCHAR(64) NOT NULL,
+  snapshot jsonb NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_node
+    FOREIGN KEY(node_id)
+    REFERENCES nodes(id),
+  CONSTRAINT fk_actor
+    FOREIGN KEY(actor_id)
+    REFERENCES users(id),
+  UNIQUE(node_id, number)
+);
+
+-- node_snapshot captures the node name, text and attached expressions with their translations.
+CREATE OR REPLACE FUNCTION node_snapshot(nid INT)
+RETURNS jsonb AS $$
+  SELECT jsonb_build_object(
+    'name', n.name,
+    'text', (SELECT jsonb_build_object('id', t.id, 'title', t.title) FROM texts t WHERE t.id=n.text_id),
+    'expressions', COALESCE((
+      SELECT jsonb_agg(jsonb_build_object(
+        'id', e.id,
+        'value', e.value,
+        'position', ne.position,
+        'translations', COALESCE((
+          SELECT jsonb_agg(jsonb_build_object('id', tr.id, 'value', ve.value, 'comment', COALESCE(tr.comment, '')) ORDER BY tr.id)
+          FROM node_translation nt
+          LEFT JOIN translations tr ON tr.id=nt.translation_id
+          LEFT JOIN expressions ve ON ve.id=tr.native_id
+          WHERE nt.node_id=n.id AND tr.target_id=e.id
+        ), '[]'::jsonb)
+      ) ORDER BY ne.position NULLS LAST, ne.created_at, e.id)
+      FROM node_expression ne
+      LEFT JOIN expressions e ON e.id=ne.expression_id
+      WHERE ne.node_id=n.id
+    ), '[]'::jsonb)
+  ) FROM nodes n WHERE n.id=nid
+$$ LANGUAGE SQL STABLE;
+
+INSERT INTO node_revisions (node_id, number, action, snapshot)
+SELECT n.id, 1, 'node.baseline', node_snapshot(n.id) FROM nodes n;
@@ -0,0 +1,17 @@
+DELETE FROM training_items WHERE training_id IN (SELECT id FROM trainings WHERE filters <> '{}');
+DELETE FROM trainings WHERE filters <> '{}';
+
+ALTER TABLE trainings
+    DROP CONSTRAINT IF EXISTS trainings_owner_id_type_transcription_type_slices_filters_key;
+
+ALTER TABLE trainings
+    DROP COLUMN IF EXISTS filters;
+
+ALTER TABLE trainings
+    ADD CONSTRAINT trainings_owner_id_type_transcription_type_slices_key
+    UNIQUE(owner_id, type, transcription_type, slices);
+
+DROP TABLE IF EXISTS saved_filters;
+DROP TABLE IF EXISTS node_expression_tag;
+DROP TABLE IF EXISTS node_tag;
+DROP TABLE IF EXISTS tags;
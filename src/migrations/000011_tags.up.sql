@@ -0,0 +1,73 @@
+CREATE TABLE tags (
+  id serial PRIMARY KEY,
+  group_id INT NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_group
+    FOREIGN KEY(group_id)
+    REFERENCES groups(id)
+    ON DELETE CASCADE,
+  UNIQUE(group_id, name)
+);
+
+CREATE TABLE node_tag (
+  node_id INT NOT NULL,
+  tag_id INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_node
+    FOREIGN KEY(node_id)
+    REFERENCES nodes(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_tag
+    FOREIGN KEY(tag_id)
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+  UNIQUE(node_id, tag_id)
+);
+
+-- Tags of node-expression links go away together with the link.
+CREATE TABLE node_expression_tag (
+  node_id INT NOT NULL,
+  expression_id INT NOT NULL,
+  tag_id INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_node_expression
+    FOREIGN KEY(node_id, expression_id)
+    REFERENCES node_expression(node_id, expression_id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_tag
+    FOREIGN KEY(tag_id)
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+  UNIQUE(node_id, expression_id, tag_id)
+);
+
+CREATE INDEX node_expression_tag_tag_idx ON node_expression_tag (tag_id);
+
+CREATE TABLE saved_filters (
+  id serial PRIMARY KEY,
+  group_id INT NOT NULL,
+  owner_id INT NOT NULL,
+  name VARCHAR(128) NOT NULL,
+  tag_ids INT[] NOT NULL,
+  match VARCHAR(8) NOT NULL DEFAULT 'any',
+  node_ids INT[] NOT NULL DEFAULT '{}',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_group
+    FOREIGN KEY(group_id)
+    REFERENCES groups(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_owner
+    FOREIGN KEY(owner_id)
+    REFERENCES users(id)
+);
+
+ALTER TABLE trainings
+    ADD COLUMN filters INT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE trainings
+    DROP CONSTRAINT IF EXISTS trainings_owner_id_type_transcription_type_slices_key;
+
+ALTER TABLE trainings
+    ADD CONSTRAINT trainings_owner_id_type_transcription_type_slices_filters_key
+    UNIQUE(owner_id, type, transcription_type, slices, filters);
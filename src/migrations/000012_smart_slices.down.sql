@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS training_failures;
+
+ALTER TABLE nodes
+    DROP COLUMN IF EXISTS smart_query;
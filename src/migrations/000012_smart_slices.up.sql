@@ -0,0 +1,20 @@
+ALTER TABLE nodes
+    ADD COLUMN smart_query JSONB;
+
+-- Failed answers of training items, smart slices select the often failed translations.
+CREATE TABLE training_failures (
+  id serial PRIMARY KEY,
+  user_id INT NOT NULL,
+  translation_id INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_user
+    FOREIGN KEY(user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_translation
+    FOREIGN KEY(translation_id)
+    REFERENCES translations(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX training_failures_user_idx ON training_failures USING BTREE (user_id, translation_id, created_at);
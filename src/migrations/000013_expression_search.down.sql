@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS transcriptions_search_idx;
+DROP INDEX IF EXISTS expressions_search_idx;
+DROP FUNCTION IF EXISTS search_key(TEXT);
+DROP EXTENSION IF EXISTS unaccent;
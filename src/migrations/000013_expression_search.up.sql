@@ -0,0 +1,11 @@
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- search_key folds case, diacritics, tone numbers and separators,
+-- so "nihao" matches both "nǐ hǎo" and "ni3 hao3".
+CREATE OR REPLACE FUNCTION search_key(value TEXT)
+RETURNS TEXT AS $$
+  SELECT lower(regexp_replace(public.unaccent('public.unaccent'::regdictionary, value), '[[:space:][:digit:][:punct:]]+', '', 'g'))
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX expressions_search_idx ON expressions USING GIN (search_key(value) gin_trgm_ops);
+CREATE INDEX transcriptions_search_idx ON transcriptions USING GIN (search_key(value) gin_trgm_ops);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS dictionary_entries;
+DROP TABLE IF EXISTS dictionaries;
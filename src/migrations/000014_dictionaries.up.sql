@@ -0,0 +1,36 @@
+-- Dictionaries are imported offline and kept apart from user content,
+-- their entries only become expressions when a suggestion is accepted.
+CREATE TABLE dictionaries (
+  id serial PRIMARY KEY,
+  name VARCHAR(128) NOT NULL UNIQUE,
+  format VARCHAR(16) NOT NULL,
+  source_lang VARCHAR(2) NOT NULL,
+  native_lang VARCHAR(2) NOT NULL,
+  transcription_type INT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_source_lang
+    FOREIGN KEY(source_lang)
+    REFERENCES languages(code),
+  CONSTRAINT fk_native_lang
+    FOREIGN KEY(native_lang)
+    REFERENCES languages(code),
+  CONSTRAINT fk_transcription_type
+    FOREIGN KEY(transcription_type)
+    REFERENCES transcription_types(id)
+);
+
+CREATE TABLE dictionary_entries (
+  id serial PRIMARY KEY,
+  dictionary_id INT NOT NULL,
+  headword VARCHAR(128) NOT NULL,
+  variant VARCHAR(128) NOT NULL DEFAULT '',
+  transcription VARCHAR(128) NOT NULL DEFAULT '',
+  translations TEXT[] NOT NULL,
+  CONSTRAINT fk_dictionary
+    FOREIGN KEY(dictionary_id)
+    REFERENCES dictionaries(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX dictionary_entries_headword_idx ON dictionary_entries USING BTREE (headword);
+CREATE INDEX dictionary_entries_variant_idx ON dictionary_entries USING BTREE (variant);
@@ -0,0 +1 @@
+DELETE FROM transcription_types WHERE lang='ja' AND name='romaji';
@@ -0,0 +1 @@
+INSERT INTO transcription_types (lang, name) VALUES ('ja', 'romaji');
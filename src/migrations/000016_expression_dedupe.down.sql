@@ -0,0 +1 @@
+DROP INDEX IF EXISTS expressions_lower_value_idx;
@@ -0,0 +1,2 @@
+-- Expressions are looked up ignoring the case when they are written.
+CREATE INDEX expressions_lower_value_idx ON expressions USING BTREE (lang, lower(value));
@@ -0,0 +1 @@
+ALTER TABLE user_group DROP CONSTRAINT IF EXISTS user_group_member_key;
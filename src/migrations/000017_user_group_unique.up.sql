@@ -0,0 +1,6 @@
+-- Memberships duplicated by concurrent joins collapse into one, an active one wins.
+DELETE FROM user_group a USING user_group b
+WHERE a.user_id=b.user_id AND a.group_id=b.group_id
+  AND (a.status<>b.status AND b.status=1 OR a.status=b.status AND a.ctid < b.ctid);
+
+ALTER TABLE user_group ADD CONSTRAINT user_group_member_key UNIQUE (user_id, group_id);
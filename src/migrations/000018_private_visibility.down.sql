@@ -0,0 +1,4 @@
+UPDATE nodes SET visibility = 1 - visibility;
+UPDATE groups SET visibility = 1 - visibility;
+
+ALTER TABLE groups ALTER COLUMN visibility SET DEFAULT 1;
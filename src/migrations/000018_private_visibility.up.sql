@@ -0,0 +1,6 @@
+-- Private becomes the zero value of node and group visibility. Stored values are
+-- swapped, so every node and group keeps the visibility it had.
+UPDATE nodes SET visibility = 1 - visibility;
+UPDATE groups SET visibility = 1 - visibility;
+
+ALTER TABLE groups ALTER COLUMN visibility SET DEFAULT 0;
@@ -0,0 +1 @@
+-- Removed tokens can't be restored.
@@ -0,0 +1,4 @@
+-- Activity entries stored invitation and join link tokens which any member can read.
+UPDATE group_activity SET before = before - 'Token' - 'TokenExpiresAt' WHERE before ? 'Token';
+UPDATE group_activity SET after = after - 'Token' - 'TokenExpiresAt' WHERE after ? 'Token';
+UPDATE group_activity SET after = after - 'token' WHERE action='link.create' AND after ? 'token';
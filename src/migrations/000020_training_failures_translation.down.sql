@@ -0,0 +1 @@
+DROP INDEX IF EXISTS training_failures_translation_idx;
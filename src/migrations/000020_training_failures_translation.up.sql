@@ -0,0 +1,2 @@
+-- Difficulty order of slices counts failures of every learner by translation.
+CREATE INDEX training_failures_translation_idx ON training_failures USING BTREE (translation_id);
@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS node_translation_links(INT[], INT);
+DROP FUNCTION IF EXISTS node_expression_links(INT[], INT);
+DROP FUNCTION IF EXISTS smart_links(INT, INT);
@@ -0,0 +1,65 @@
+-- Smart slices are computed when read instead of being stored as node links,
+-- so the failed rule shows every member translations they failed themselves.
+DELETE FROM node_translation WHERE node_id IN (SELECT id FROM nodes WHERE type=2);
+DELETE FROM node_expression WHERE node_id IN (SELECT id FROM nodes WHERE type=2);
+
+-- smart_links selects links of the group static slices matching the query of the smart node,
+-- limited to subtrees of its source nodes when they are given. Zero days means no time limit
+-- and failures are counted for the viewer, nobody's when there is no viewer.
+CREATE OR REPLACE FUNCTION smart_links(nid INT, viewer INT)
+RETURNS TABLE (expression_id INT, translation_id INT, created_at TIMESTAMP) AS $$
+  SELECT ne.expression_id, nt.translation_id, ne.created_at FROM nodes s
+  JOIN group_node sgn ON sgn.node_id=s.id
+  JOIN groups g ON g.id=sgn.group_id
+  CROSS JOIN LATERAL (
+    SELECT s.smart_query->>'rule' AS rule,
+      COALESCE((s.smart_query->>'days')::int, 0) AS days,
+      COALESCE((s.smart_query->>'failures')::int, 0) AS failures,
+      ARRAY(SELECT jsonb_array_elements_text(COALESCE(s.smart_query->'sourceIds', '[]'::jsonb))::int) AS source_ids
+  ) q
+  JOIN group_node gn ON gn.group_id=sgn.group_id
+  JOIN nodes n ON n.id=gn.node_id AND n.type=1
+  JOIN node_expression ne ON ne.node_id=gn.node_id
+  JOIN node_translation nt ON nt.node_id=ne.node_id
+  JOIN translations t ON t.id=nt.translation_id AND t.target_id=ne.expression_id
+  WHERE s.id=nid AND s.type=2 AND (
+    cardinality(q.source_ids)=0
+    OR ne.node_id=ANY(q.source_ids)
+    OR EXISTS (SELECT 1 FROM unnest(q.source_ids) AS src(id) WHERE gn.path ~ ('*.' || src.id || '.*')::lquery)
+  ) AND CASE q.rule
+    WHEN 'recent' THEN q.days=0 OR ne.created_at >= NOW() - make_interval(days => q.days)
+    WHEN 'no-transcription' THEN NOT EXISTS (
+      SELECT 1 FROM translation_transcription tt
+      JOIN transcriptions tsc ON tsc.id=tt.transcription_id
+      WHERE tt.translation_id=t.id AND tsc.type=g.transcription_type
+    )
+    WHEN 'failed' THEN (
+      SELECT COUNT(*) FROM training_failures tf
+      WHERE tf.user_id=viewer AND tf.translation_id=t.id
+        AND (q.days=0 OR tf.created_at >= NOW() - make_interval(days => q.days))
+    ) >= q.failures
+    ELSE FALSE
+  END
+$$ LANGUAGE SQL STABLE;
+
+-- node_expression_links lists expression links of the nodes, the ones of smart nodes are computed for the viewer.
+CREATE OR REPLACE FUNCTION node_expression_links(ids INT[], viewer INT)
+RETURNS TABLE (node_id INT, expression_id INT, created_at TIMESTAMP, position INT) AS $$
+  SELECT ne.node_id, ne.expression_id, ne.created_at, ne.position FROM node_expression ne
+  WHERE ne.node_id=ANY(ids)
+  UNION ALL
+  SELECT s.id, l.expression_id, MIN(l.created_at), NULL::int FROM unnest(ids) AS s(id)
+  CROSS JOIN LATERAL smart_links(s.id, viewer) l
+  GROUP BY s.id, l.expression_id
+$$ LANGUAGE SQL STABLE;
+
+-- node_translation_links lists translation links of the nodes, the ones of smart nodes are computed for the viewer.
+CREATE OR REPLACE FUNCTION node_translation_links(ids INT[], viewer INT)
+RETURNS TABLE (node_id INT, translation_id INT, created_at TIMESTAMP) AS $$
+  SELECT nt.node_id, nt.translation_id, nt.created_at FROM node_translation nt
+  WHERE nt.node_id=ANY(ids)
+  UNION ALL
+  SELECT s.id, l.translation_id, MIN(l.created_at) FROM unnest(ids) AS s(id)
+  CROSS JOIN LATERAL smart_links(s.id, viewer) l
+  GROUP BY s.id, l.translation_id
+$$ LANGUAGE SQL STABLE;
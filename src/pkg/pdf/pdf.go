@@ -0,0 +1,320 @@
+// Package pdf writes simple text documents made of lines and single font text.
+// A TrueType font is embedded when given, otherwise the standard Courier font
+// is used which can only show WinAnsi characters.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	// A4 page size in points
+	A4Width  = 595.28
+	A4Height = 841.89
+
+	courierAdvance = 600
+)
+
+type Document struct {
+	width  float64
+	height float64
+	font   *trueType
+	used   map[uint16]rune
+	pages  []*Page
+}
+
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+func New(width float64, height float64) *Document {
+	return &Document{
+		width:  width,
+		height: height,
+		used:   make(map[uint16]rune),
+	}
+}
+
+// SetFont embeds the TrueType font which is used for all text of the document.
+func (d *Document) SetFont(data []byte) error {
+	font, err := parseTrueType(data)
+	if err != nil {
+		return err
+	}
+	d.font = font
+
+	return nil
+}
+
+func (d *Document) Width() float64 {
+	return d.width
+}
+
+func (d *Document) Height() float64 {
+	return d.height
+}
+
+func (d *Document) AddPage() *Page {
+	page := &Page{doc: d}
+	d.pages = append(d.pages, page)
+
+	return page
+}
+
+// encode converts text into font codes returning their total advance in thousandths of text size.
+func (d *Document) encode(text string) ([]byte, int) {
+	codes := []byte{}
+	advance := 0
+
+	for _, r := range text {
+		if d.font == nil {
+			codes = append(codes, winAnsiCode(r))
+			advance += courierAdvance
+			continue
+		}
+
+		glyph := d.font.glyphs[r]
+		if _, ok := d.used[glyph]; !ok {
+			d.used[glyph] = r
+		}
+		codes = append(codes, byte(glyph>>8), byte(glyph))
+		advance += d.font.advance(glyph)
+	}
+
+	return codes, advance
+}
+
+func (d *Document) TextWidth(text string, size float64) float64 {
+	advance := 0
+	for _, r := range text {
+		if d.font == nil {
+			advance += courierAdvance
+		} else {
+			advance += d.font.advance(d.font.glyphs[r])
+		}
+	}
+
+	return float64(advance) * size / 1000
+}
+
+// WrapText breaks text into lines fitting the width, words longer than
+// the width are broken between characters.
+func (d *Document) WrapText(text string, size float64, width float64) []string {
+	lines := []string{}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+
+			if d.TextWidth(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+
+			for _, r := range word {
+				if line != "" && d.TextWidth(line+string(r), size) > width {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+
+		if line != "" || len(lines) == 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// Text draws the text with its baseline starting at x, y.
+func (p *Page) Text(x float64, y float64, size float64, text string) {
+	if strings.TrimFunc(text, unicode.IsSpace) == "" {
+		return
+	}
+
+	codes, _ := p.doc.encode(text)
+	fmt.Fprintf(&p.content, "BT /F1 %.2f Tf %.2f %.2f Td <%X> Tj ET\n", size, x, y, codes)
+}
+
+// SetGray sets the gray level used for the following text and lines, 0 is black.
+func (p *Page) SetGray(level float64) {
+	fmt.Fprintf(&p.content, "%.2f g %.2f G\n", level, level)
+}
+
+func (p *Page) Line(x1 float64, y1 float64, x2 float64, y2 float64, width float64, dashed bool) {
+	dash := "[] 0 d"
+	if dashed {
+		dash = "[4 3] 0 d"
+	}
+
+	fmt.Fprintf(&p.content, "%s %.2f w %.2f %.2f m %.2f %.2f l S\n", dash, width, x1, y1, x2, y2)
+}
+
+type objectWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *objectWriter) object(number int, body string) {
+	for len(w.offsets) < number {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[number-1] = w.buf.Len()
+
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", number, body)
+}
+
+func (w *objectWriter) stream(number int, dict string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("<< %s /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream", dict, compressed.Len(), compressed.Bytes())
+	w.object(number, body)
+
+	return nil
+}
+
+// Bytes renders the document into PDF file.
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	w := &objectWriter{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	const (
+		catalogObj = 1
+		pagesObj   = 2
+		fontObj    = 3
+	)
+	next := fontObj + 1
+
+	if d.font == nil {
+		w.object(fontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	} else {
+		cidFontObj, descriptorObj, fileObj, toUnicodeObj := next, next+1, next+2, next+3
+		next += 4
+
+		w.object(fontObj, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /LstFont /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", cidFontObj, toUnicodeObj))
+		w.object(cidFontObj, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /LstFont /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW %d /W [%s] /CIDToGIDMap /Identity >>", descriptorObj, d.font.advance(0), d.widths()))
+
+		f := d.font
+		w.object(descriptorObj, fmt.Sprintf("<< /Type /FontDescriptor /FontName /LstFont /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle %d /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+			f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]), f.italicAngle, f.scale(f.ascent), f.scale(f.descent), f.scale(f.capHeight), fileObj))
+
+		if err := w.stream(fileObj, fmt.Sprintf("/Length1 %d", len(f.data)), f.data); err != nil {
+			return nil, err
+		}
+		if err := w.stream(toUnicodeObj, "", d.toUnicode()); err != nil {
+			return nil, err
+		}
+	}
+
+	kids := []string{}
+	for _, page := range d.pages {
+		pageObj, contentObj := next, next+1
+		next += 2
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+
+		w.object(pageObj, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>", pagesObj, d.width, d.height, fontObj, contentObj))
+		if err := w.stream(contentObj, "", page.content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	w.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	w.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, catalogObj, xref)
+
+	return w.buf.Bytes(), nil
+}
+
+func (d *Document) usedGlyphs() []uint16 {
+	glyphs := []uint16{}
+	for glyph := range d.used {
+		glyphs = append(glyphs, glyph)
+	}
+	sort.Slice(glyphs, func(a, b int) bool {
+		return glyphs[a] < glyphs[b]
+	})
+
+	return glyphs
+}
+
+func (d *Document) widths() string {
+	parts := []string{}
+	for _, glyph := range d.usedGlyphs() {
+		parts = append(parts, fmt.Sprintf("%d [%d]", glyph, d.font.advance(glyph)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// toUnicode maps glyphs back to characters so that text can be copied and searched.
+func (d *Document) toUnicode() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	buf.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	buf.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	buf.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	glyphs := []uint16{}
+	for _, glyph := range d.usedGlyphs() {
+		if glyph != 0 {
+			glyphs = append(glyphs, glyph)
+		}
+	}
+
+	for start := 0; start < len(glyphs); start += 100 {
+		end := start + 100
+		if end > len(glyphs) {
+			end = len(glyphs)
+		}
+
+		fmt.Fprintf(&buf, "%d beginbfchar\n", end-start)
+		for _, glyph := range glyphs[start:end] {
+			fmt.Fprintf(&buf, "<%04X> <", glyph)
+			for _, unit := range utf16.Encode([]rune{d.used[glyph]}) {
+				fmt.Fprintf(&buf, "%04X", unit)
+			}
+			buf.WriteString(">\n")
+		}
+		buf.WriteString("endbfchar\n")
+	}
+
+	buf.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	return buf.Bytes()
+}
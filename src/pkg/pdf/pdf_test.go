@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWinAnsiCode(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want byte
+	}{
+		{'a', 'a'},
+		{'é', 0xe9},
+		{'€', 0x80},
+		{'—', 0x97},
+		{'\n', '?'},
+		{'ж', '?'},
+		{'你', '?'},
+	}
+
+	for _, tt := range tests {
+		if got := winAnsiCode(tt.r); got != tt.want {
+			t.Errorf("winAnsiCode(%q) = %#x, want %#x", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	doc := New(A4Width, A4Height)
+
+	// Courier characters are 6 points wide at the size of 10.
+	tests := []struct {
+		text  string
+		width float64
+		want  []string
+	}{
+		{"", 60, []string{""}},
+		{"hello world", 66, []string{"hello world"}},
+		{"hello world", 60, []string{"hello", "world"}},
+		{"a  b   c", 30, []string{"a b c"}},
+		{"abcdefghij", 24, []string{"abcd", "efgh", "ij"}},
+		{"hi abcdefgh", 30, []string{"hi", "abcde", "fgh"}},
+		{"one\ntwo", 60, []string{"one", "two"}},
+	}
+
+	for _, tt := range tests {
+		got := doc.WrapText(tt.text, 10, tt.width)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("WrapText(%q, %v) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	doc := New(A4Width, A4Height)
+	doc.AddPage().Text(10, 10, 12, "first")
+	page := doc.AddPage()
+	page.Text(10, 10, 12, "  ")
+	page.Line(0, 0, 10, 10, 1, true)
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("document lacks the header or the end marker")
+	}
+	if !bytes.Contains(data, []byte("/BaseFont /Courier")) || !bytes.Contains(data, []byte("/Count 2")) {
+		t.Error("document lacks the standard font or its two pages")
+	}
+
+	// Every xref entry must point at the start of its object.
+	var xref int
+	tail := data[bytes.LastIndex(data, []byte("startxref\n")):]
+	if _, err := fmt.Sscanf(string(tail), "startxref\n%d", &xref); err != nil {
+		t.Fatalf("startxref: %v", err)
+	}
+	var count int
+	if _, err := fmt.Sscanf(string(data[xref:]), "xref\n0 %d\n", &count); err != nil {
+		t.Fatalf("xref: %v", err)
+	}
+	entries := strings.Split(string(data[xref:]), "\n")[3 : 3+count-1]
+	for i, entry := range entries {
+		var offset int
+		fmt.Sscanf(entry, "%d", &offset)
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("object %d is not at offset %d", i+1, offset)
+		}
+	}
+}
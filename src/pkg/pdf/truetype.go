@@ -0,0 +1,209 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidFont = errors.New("pdf: invalid TrueType font")
+
+// trueType keeps the metrics needed to embed the font as a CID font.
+type trueType struct {
+	data        []byte
+	unitsPerEm  int
+	ascent      int
+	descent     int
+	capHeight   int
+	bbox        [4]int
+	italicAngle int
+	advances    []int
+	glyphs      map[rune]uint16
+}
+
+func parseTrueType(data []byte) (*trueType, error) {
+	if len(data) < 12 {
+		return nil, ErrInvalidFont
+	}
+
+	version := binary.BigEndian.Uint32(data)
+	if version != 0x00010000 && version != 0x74727565 {
+		return nil, ErrInvalidFont
+	}
+
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, ErrInvalidFont
+		}
+
+		tag := string(data[record : record+4])
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, ErrInvalidFont
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+
+	head, hhea, hmtx, cmap := tables["head"], tables["hhea"], tables["hmtx"], tables["cmap"]
+	if len(head) < 54 || len(hhea) < 36 || hmtx == nil || cmap == nil {
+		return nil, ErrInvalidFont
+	}
+
+	font := &trueType{
+		data:       data,
+		unitsPerEm: int(binary.BigEndian.Uint16(head[18:])),
+		ascent:     int(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent:    int(int16(binary.BigEndian.Uint16(hhea[6:]))),
+		bbox: [4]int{
+			int(int16(binary.BigEndian.Uint16(head[36:]))),
+			int(int16(binary.BigEndian.Uint16(head[38:]))),
+			int(int16(binary.BigEndian.Uint16(head[40:]))),
+			int(int16(binary.BigEndian.Uint16(head[42:]))),
+		},
+	}
+	if font.unitsPerEm == 0 {
+		return nil, ErrInvalidFont
+	}
+	font.capHeight = font.ascent
+
+	if os2 := tables["OS/2"]; len(os2) >= 90 && binary.BigEndian.Uint16(os2) >= 2 {
+		font.capHeight = int(int16(binary.BigEndian.Uint16(os2[88:])))
+	}
+	if post := tables["post"]; len(post) >= 8 {
+		font.italicAngle = int(int16(binary.BigEndian.Uint16(post[4:])))
+	}
+
+	numberOfHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	if len(hmtx) < 4*numberOfHMetrics {
+		return nil, ErrInvalidFont
+	}
+	for i := 0; i < numberOfHMetrics; i++ {
+		font.advances = append(font.advances, int(binary.BigEndian.Uint16(hmtx[4*i:])))
+	}
+
+	glyphs, err := parseCmap(cmap)
+	if err != nil {
+		return nil, err
+	}
+	font.glyphs = glyphs
+
+	return font, nil
+}
+
+// parseCmap reads the unicode subtable, format 12 preferred over format 4.
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrInvalidFont
+	}
+
+	var format4, format12 []byte
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := 4 + 8*i
+		if record+8 > len(cmap) {
+			return nil, ErrInvalidFont
+		}
+
+		platform := binary.BigEndian.Uint16(cmap[record:])
+		encoding := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if offset+4 > len(cmap) {
+			return nil, ErrInvalidFont
+		}
+		if platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+
+		subtable := cmap[offset:]
+		switch binary.BigEndian.Uint16(subtable) {
+		case 4:
+			format4 = subtable
+		case 12:
+			format12 = subtable
+		}
+	}
+
+	glyphs := make(map[rune]uint16)
+
+	switch {
+	case format12 != nil:
+		if len(format12) < 16 {
+			return nil, ErrInvalidFont
+		}
+		groups := int(binary.BigEndian.Uint32(format12[12:]))
+		if len(format12) < 16+12*groups {
+			return nil, ErrInvalidFont
+		}
+		for i := 0; i < groups; i++ {
+			group := format12[16+12*i:]
+			start := binary.BigEndian.Uint32(group)
+			end := binary.BigEndian.Uint32(group[4:])
+			glyph := binary.BigEndian.Uint32(group[8:])
+			for code := start; code <= end && code <= 0x10ffff; code++ {
+				glyphs[rune(code)] = uint16(glyph + code - start)
+			}
+		}
+	case format4 != nil:
+		if len(format4) < 14 {
+			return nil, ErrInvalidFont
+		}
+		segments := int(binary.BigEndian.Uint16(format4[6:])) / 2
+		endCodes := 14
+		startCodes := endCodes + 2*segments + 2
+		deltas := startCodes + 2*segments
+		rangeOffsets := deltas + 2*segments
+		if len(format4) < rangeOffsets+2*segments {
+			return nil, ErrInvalidFont
+		}
+
+		for i := 0; i < segments; i++ {
+			end := int(binary.BigEndian.Uint16(format4[endCodes+2*i:]))
+			start := int(binary.BigEndian.Uint16(format4[startCodes+2*i:]))
+			delta := binary.BigEndian.Uint16(format4[deltas+2*i:])
+			rangeOffset := int(binary.BigEndian.Uint16(format4[rangeOffsets+2*i:]))
+
+			for code := start; code <= end && code != 0xffff; code++ {
+				var glyph uint16
+				if rangeOffset == 0 {
+					glyph = uint16(code) + delta
+				} else {
+					pos := rangeOffsets + 2*i + rangeOffset + 2*(code-start)
+					if pos+2 > len(format4) {
+						continue
+					}
+					glyph = binary.BigEndian.Uint16(format4[pos:])
+					if glyph != 0 {
+						glyph += delta
+					}
+				}
+				if glyph != 0 {
+					glyphs[rune(code)] = glyph
+				}
+			}
+		}
+	default:
+		return nil, errors.New("pdf: font has no unicode character map")
+	}
+
+	return glyphs, nil
+}
+
+// advance returns glyph width in thousandths of text size.
+func (f *trueType) advance(glyph uint16) int {
+	idx := int(glyph)
+	if idx >= len(f.advances) {
+		idx = len(f.advances) - 1
+	}
+	if idx < 0 {
+		return 0
+	}
+
+	return f.advances[idx] * 1000 / f.unitsPerEm
+}
+
+func (f *trueType) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
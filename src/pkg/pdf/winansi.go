@@ -0,0 +1,23 @@
+package pdf
+
+// winAnsiSpecials are characters placed by Windows-1252 into the 0x80-0x9f range.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// winAnsiCode returns the standard font code for the character, "?" when it can't be shown.
+func winAnsiCode(r rune) byte {
+	switch {
+	case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+		return byte(r)
+	}
+
+	if code, ok := winAnsiSpecials[r]; ok {
+		return code
+	}
+
+	return '?'
+}
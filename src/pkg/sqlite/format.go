@@ -0,0 +1,327 @@
+// Package sqlite reads and writes SQLite 3 database files in pure Go.
+// Only tables and indexes are supported, which is enough to exchange
+// self-contained files like Anki collections.
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	headerMagic = "SQLite format 3\x00"
+	headerSize  = 100
+
+	pageLeafTable     = 0x0d
+	pageInteriorTable = 0x05
+	pageLeafIndex     = 0x0a
+	pageInteriorIndex = 0x02
+)
+
+var ErrCorrupted = errors.New("sqlite: database file is corrupted")
+
+// Row maps column names to values which are nil, int64, float64, string or []byte.
+type Row map[string]interface{}
+
+func getVarint(buf []byte) (uint64, int) {
+	var v uint64
+
+	for i := 0; i < 8; i++ {
+		if i >= len(buf) {
+			return 0, 0
+		}
+		v = (v << 7) | uint64(buf[i]&0x7f)
+		if buf[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+
+	if len(buf) < 9 {
+		return 0, 0
+	}
+
+	return (v << 8) | uint64(buf[8]), 9
+}
+
+func putVarint(v uint64) []byte {
+	if v > 0x00ffffffffffffff {
+		buf := make([]byte, 9)
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return buf
+	}
+
+	var tmp [9]byte
+	n := 0
+	for {
+		tmp[n] = byte(v&0x7f) | 0x80
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	tmp[0] &= 0x7f
+
+	buf := make([]byte, n)
+	for i := 0; i < n; i++ {
+		buf[i] = tmp[n-1-i]
+	}
+
+	return buf
+}
+
+func intSerialType(v int64) (uint64, int) {
+	switch {
+	case v == 0:
+		return 8, 0
+	case v == 1:
+		return 9, 0
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return 1, 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return 2, 2
+	case v >= -(1<<23) && v < 1<<23:
+		return 3, 3
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return 4, 4
+	case v >= -(1<<47) && v < 1<<47:
+		return 5, 6
+	default:
+		return 6, 8
+	}
+}
+
+// encodeRecord serializes values into the SQLite record format.
+func encodeRecord(values []interface{}) ([]byte, error) {
+	var header, body bytes.Buffer
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			header.Write(putVarint(0))
+		case bool:
+			if v {
+				header.Write(putVarint(9))
+			} else {
+				header.Write(putVarint(8))
+			}
+		case int:
+			writeInt(&header, &body, int64(v))
+		case int64:
+			writeInt(&header, &body, v)
+		case uint:
+			writeInt(&header, &body, int64(v))
+		case float64:
+			header.Write(putVarint(7))
+			binary.Write(&body, binary.BigEndian, math.Float64bits(v))
+		case string:
+			header.Write(putVarint(uint64(len(v))*2 + 13))
+			body.WriteString(v)
+		case []byte:
+			header.Write(putVarint(uint64(len(v))*2 + 12))
+			body.Write(v)
+		default:
+			return nil, fmt.Errorf("sqlite: unsupported value type %T", value)
+		}
+	}
+
+	// Header size includes its own varint which is 1 byte for any sane record,
+	// but grows when the header gets longer.
+	size := header.Len() + 1
+	for len(putVarint(uint64(size))) != size-header.Len() {
+		size = header.Len() + len(putVarint(uint64(size)))
+	}
+
+	record := append(putVarint(uint64(size)), header.Bytes()...)
+
+	return append(record, body.Bytes()...), nil
+}
+
+func writeInt(header *bytes.Buffer, body *bytes.Buffer, v int64) {
+	serialType, size := intSerialType(v)
+	header.Write(putVarint(serialType))
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(v))
+	body.Write(buf[8-size:])
+}
+
+// decodeRecord parses the SQLite record format.
+func decodeRecord(record []byte) ([]interface{}, error) {
+	headerLen, n := getVarint(record)
+	if n == 0 || headerLen > uint64(len(record)) {
+		return nil, ErrCorrupted
+	}
+
+	types := []uint64{}
+	for pos := n; pos < int(headerLen); {
+		serialType, n := getVarint(record[pos:headerLen])
+		if n == 0 {
+			return nil, ErrCorrupted
+		}
+		types = append(types, serialType)
+		pos += n
+	}
+
+	values := make([]interface{}, len(types))
+	body := record[headerLen:]
+
+	for i, serialType := range types {
+		var size int
+
+		switch {
+		case serialType == 0 || serialType == 8 || serialType == 9:
+			size = 0
+		case serialType >= 1 && serialType <= 4:
+			size = int(serialType)
+		case serialType == 5:
+			size = 6
+		case serialType == 6 || serialType == 7:
+			size = 8
+		case serialType >= 12:
+			// Lengths are checked before the conversion, a crafted serial type overflows int.
+			if (serialType-12)/2 > uint64(len(body)) {
+				return nil, ErrCorrupted
+			}
+			size = int((serialType - 12) / 2)
+		default:
+			return nil, ErrCorrupted
+		}
+
+		if size > len(body) {
+			return nil, ErrCorrupted
+		}
+		data := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0:
+			values[i] = nil
+		case serialType == 8:
+			values[i] = int64(0)
+		case serialType == 9:
+			values[i] = int64(1)
+		case serialType == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(data))
+		case serialType <= 6:
+			var v int64
+			for _, b := range data {
+				v = (v << 8) | int64(b)
+			}
+			// sign extend
+			shift := uint(64 - 8*size)
+			values[i] = (v << shift) >> shift
+		case serialType%2 == 0:
+			values[i] = append([]byte{}, data...)
+		default:
+			values[i] = string(data)
+		}
+	}
+
+	return values, nil
+}
+
+// compareValues orders values the way SQLite does with BINARY collation:
+// NULL < numbers < text < blob.
+func compareValues(a interface{}, b interface{}) int {
+	rank := func(v interface{}) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case int, int64, uint, float64, bool:
+			return 1
+		case string:
+			return 2
+		default:
+			return 3
+		}
+	}
+
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch ra {
+	case 1:
+		fa, fb := toFloat(a), toFloat(b)
+		if fa < fb {
+			return -1
+		} else if fa > fb {
+			return 1
+		}
+		ia, aInt := toInt(a)
+		ib, bInt := toInt(b)
+		if aInt && bInt && ia != ib {
+			if ia < ib {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	case 2:
+		return strings.Compare(a.(string), b.(string))
+	case 3:
+		return bytes.Compare(a.([]byte), b.([]byte))
+	}
+
+	return 0
+}
+
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return int64(n), true
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+func toFloat(v interface{}) float64 {
+	if n, ok := toInt(v); ok {
+		return float64(n)
+	}
+	if f, ok := v.(float64); ok {
+		return f
+	}
+
+	return 0
+}
+
+// maxLocalPayload returns how many payload bytes are stored in the cell itself,
+// the rest goes to overflow pages.
+func maxLocalPayload(usable int, payload int, leafTable bool) int {
+	maxLocal := usable - 35
+	if !leafTable {
+		maxLocal = (usable-12)*64/255 - 23
+	}
+	if payload <= maxLocal {
+		return payload
+	}
+
+	minLocal := (usable-12)*32/255 - 23
+	local := minLocal + (payload-minLocal)%(usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+
+	return local
+}
@@ -0,0 +1,253 @@
+package sqlite
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+type tableInfo struct {
+	rootPage uint32
+	columns  []column
+}
+
+// DB is a read-only SQLite database loaded into memory.
+type DB struct {
+	data     []byte
+	pageSize int
+	usable   int
+	tables   map[string]*tableInfo
+}
+
+// Open parses the database file contents.
+func Open(data []byte) (*DB, error) {
+	if len(data) < headerSize || string(data[:16]) != headerMagic {
+		return nil, fmt.Errorf("sqlite: not a database file")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, ErrCorrupted
+	}
+
+	db := &DB{
+		data:     data,
+		pageSize: pageSize,
+		usable:   pageSize - int(data[20]),
+		tables:   make(map[string]*tableInfo),
+	}
+	// SQLite never leaves less than 480 usable bytes in a page.
+	if db.usable < 480 || len(data) < pageSize {
+		return nil, ErrCorrupted
+	}
+
+	if encoding := binary.BigEndian.Uint32(data[56:60]); encoding > 1 {
+		return nil, fmt.Errorf("sqlite: only UTF-8 databases are supported")
+	}
+
+	err := db.scanTable(1, func(rowid int64, values []interface{}) error {
+		if len(values) < 5 {
+			return ErrCorrupted
+		}
+
+		kind, _ := values[0].(string)
+		name, _ := values[1].(string)
+		rootPage, _ := values[3].(int64)
+		sql, _ := values[4].(string)
+
+		if kind == "table" {
+			db.tables[name] = &tableInfo{
+				rootPage: uint32(rootPage),
+				columns:  parseTableColumns(sql),
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// HasTable tells whether the table exists.
+func (db *DB) HasTable(name string) bool {
+	_, ok := db.tables[name]
+	return ok
+}
+
+// Rows returns all rows of the table ordered by rowid.
+func (db *DB) Rows(name string) ([]Row, error) {
+	table, ok := db.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("sqlite: no such table: %s", name)
+	}
+
+	rows := []Row{}
+	err := db.scanTable(table.rootPage, func(rowid int64, values []interface{}) error {
+		row := make(Row, len(table.columns))
+		for i, col := range table.columns {
+			var value interface{}
+			if i < len(values) {
+				value = values[i]
+			}
+			if col.rowidAlias {
+				value = rowid
+			}
+			row[col.name] = value
+		}
+		rows = append(rows, row)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (db *DB) page(number uint32) ([]byte, error) {
+	start := int(number-1) * db.pageSize
+	if number == 0 || start+db.pageSize > len(db.data) {
+		return nil, ErrCorrupted
+	}
+
+	return db.data[start : start+db.pageSize], nil
+}
+
+func (db *DB) scanTable(pageNumber uint32, fn func(int64, []interface{}) error) error {
+	return db.scanPage(pageNumber, fn, make(map[uint32]bool))
+}
+
+// scanPage walks the b-tree from the page, every page is visited once
+// so that looped child pointers of a corrupted file end the scan.
+func (db *DB) scanPage(pageNumber uint32, fn func(int64, []interface{}) error, visited map[uint32]bool) error {
+	if visited[pageNumber] {
+		return ErrCorrupted
+	}
+	visited[pageNumber] = true
+
+	page, err := db.page(pageNumber)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if pageNumber == 1 {
+		offset = headerSize
+	}
+
+	kind := page[offset]
+	headerLen := 8
+	if kind == pageInteriorTable {
+		headerLen = 12
+	} else if kind != pageLeafTable {
+		return ErrCorrupted
+	}
+	if offset+headerLen > len(page) {
+		return ErrCorrupted
+	}
+	cellCount := int(binary.BigEndian.Uint16(page[offset+3:]))
+
+	for i := 0; i < cellCount; i++ {
+		pointer := offset + headerLen + 2*i
+		if pointer+2 > len(page) {
+			return ErrCorrupted
+		}
+		cellOffset := int(binary.BigEndian.Uint16(page[pointer:]))
+		if cellOffset >= len(page) {
+			return ErrCorrupted
+		}
+		cell := page[cellOffset:]
+
+		if kind == pageInteriorTable {
+			if len(cell) < 4 {
+				return ErrCorrupted
+			}
+			err = db.scanPage(binary.BigEndian.Uint32(cell), fn, visited)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// A payload can't be larger than the file it's stored in.
+		payloadSize, n := getVarint(cell)
+		if n == 0 || payloadSize > uint64(len(db.data)) {
+			return ErrCorrupted
+		}
+		rowid, m := getVarint(cell[n:])
+		if m == 0 {
+			return ErrCorrupted
+		}
+
+		payload, err := db.readPayload(cell[n+m:], int(payloadSize), true)
+		if err != nil {
+			return err
+		}
+
+		values, err := decodeRecord(payload)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(int64(rowid), values); err != nil {
+			return err
+		}
+	}
+
+	if kind == pageInteriorTable {
+		return db.scanPage(binary.BigEndian.Uint32(page[offset+8:]), fn, visited)
+	}
+
+	return nil
+}
+
+// readPayload collects the cell payload following the overflow page chain.
+func (db *DB) readPayload(cell []byte, size int, leafTable bool) ([]byte, error) {
+	if size < 0 || size > len(db.data) {
+		return nil, ErrCorrupted
+	}
+
+	local := maxLocalPayload(db.usable, size, leafTable)
+	if local < 0 || local > size || local > len(cell) {
+		return nil, ErrCorrupted
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, cell[:local]...)
+	if local == size {
+		return payload, nil
+	}
+
+	if local+4 > len(cell) {
+		return nil, ErrCorrupted
+	}
+	next := binary.BigEndian.Uint32(cell[local:])
+
+	visited := make(map[uint32]bool)
+	for len(payload) < size {
+		if visited[next] {
+			return nil, ErrCorrupted
+		}
+		visited[next] = true
+
+		page, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+
+		chunk := size - len(payload)
+		if chunk > db.usable-4 {
+			chunk = db.usable - 4
+		}
+		payload = append(payload, page[4:4+chunk]...)
+		next = binary.BigEndian.Uint32(page)
+	}
+
+	return payload, nil
+}
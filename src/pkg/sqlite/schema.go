@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"strings"
+)
+
+type column struct {
+	name       string
+	rowidAlias bool
+}
+
+// splitDefinitions returns comma separated parts within the outermost parentheses.
+func splitDefinitions(sql string) []string {
+	start := strings.Index(sql, "(")
+	end := strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil
+	}
+
+	parts := []string{}
+	depth, from := 0, start+1
+	var quote rune
+
+	for i, ch := range sql[start+1 : end] {
+		pos := start + 1 + i
+
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`' || ch == '[':
+			quote = ch
+			if ch == '[' {
+				quote = ']'
+			}
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(sql[from:pos]))
+			from = pos + 1
+		}
+	}
+
+	return append(parts, strings.TrimSpace(sql[from:end]))
+}
+
+func unquoteName(name string) string {
+	if len(name) >= 2 {
+		first, last := name[0], name[len(name)-1]
+		if (first == '"' && last == '"') || (first == '`' && last == '`') || (first == '[' && last == ']') || (first == '\'' && last == '\'') {
+			return name[1 : len(name)-1]
+		}
+	}
+
+	return name
+}
+
+// parseTableColumns extracts column names from CREATE TABLE statement.
+func parseTableColumns(sql string) []column {
+	columns := []column{}
+
+	for _, def := range splitDefinitions(sql) {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "CONSTRAINT":
+			continue
+		}
+
+		normalized := strings.ToUpper(strings.Join(fields[1:], " "))
+		columns = append(columns, column{
+			name:       unquoteName(fields[0]),
+			rowidAlias: strings.HasPrefix(normalized, "INTEGER PRIMARY KEY"),
+		})
+	}
+
+	return columns
+}
+
+// parseIndexColumns extracts indexed column names from CREATE INDEX statement.
+func parseIndexColumns(sql string) []string {
+	columns := []string{}
+
+	for _, def := range splitDefinitions(sql) {
+		fields := strings.Fields(def)
+		if len(fields) > 0 {
+			columns = append(columns, unquoteName(fields[0]))
+		}
+	}
+
+	return columns
+}
@@ -0,0 +1,339 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVarint(t *testing.T) {
+	tests := []uint64{0, 1, 127, 128, 16383, 16384, 1 << 32, 0x00ffffffffffffff, 0x0100000000000000, math.MaxUint64}
+
+	for _, v := range tests {
+		buf := putVarint(v)
+		got, n := getVarint(buf)
+		if got != v || n != len(buf) {
+			t.Errorf("getVarint(putVarint(%d)) = %d, %d; want %d, %d", v, got, n, v, len(buf))
+		}
+		if _, n := getVarint(buf[:len(buf)-1]); n != 0 {
+			t.Errorf("getVarint of truncated %d read %d bytes", v, n)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []interface{}
+		want   []interface{}
+	}{
+		{"empty", []interface{}{}, []interface{}{}},
+		{"null", []interface{}{nil}, []interface{}{nil}},
+		{"bool", []interface{}{true, false}, []interface{}{int64(1), int64(0)}},
+		{
+			"integers",
+			[]interface{}{int64(-1), int64(300), int64(-70000), int64(1 << 40), int64(math.MinInt64), 7, uint(8)},
+			[]interface{}{int64(-1), int64(300), int64(-70000), int64(1 << 40), int64(math.MinInt64), int64(7), int64(8)},
+		},
+		{"float", []interface{}{1.5}, []interface{}{1.5}},
+		{"text", []interface{}{"", "こんにちは"}, []interface{}{"", "こんにちは"}},
+		{"blob", []interface{}{[]byte{0, 1, 2}}, []interface{}{[]byte{0, 1, 2}}},
+		{"long text", []interface{}{strings.Repeat("x", 200)}, []interface{}{strings.Repeat("x", 200)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := encodeRecord(tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := decodeRecord(record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeRecord() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRecordCorrupted(t *testing.T) {
+	concat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	tests := []struct {
+		name   string
+		record []byte
+	}{
+		{"empty", []byte{}},
+		{"header longer than record", []byte{10, 1}},
+		{"truncated serial type", concat(putVarint(2), []byte{0x81})},
+		{"reserved serial type", concat(putVarint(2), putVarint(10))},
+		{"integer past the body", concat(putVarint(2), putVarint(6), []byte{1, 2})},
+		{"text past the body", concat(putVarint(2), putVarint(13+2*5), []byte("abc"))},
+		{"text length overflowing int", concat(putVarint(10), putVarint(math.MaxUint64), []byte("abc"))},
+		{"blob length overflowing int", concat(putVarint(10), putVarint(math.MaxUint64-1), []byte("abc"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeRecord(tt.record); err != ErrCorrupted {
+				t.Errorf("decodeRecord() error = %v, want %v", err, ErrCorrupted)
+			}
+		})
+	}
+}
+
+func TestMaxLocalPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   int
+		leafTable bool
+		want      int
+	}{
+		{"fits in leaf", 100, true, 100},
+		{"largest local in leaf", 4061, true, 4061},
+		{"overflows leaf", 4062, true, 489},
+		{"fits in index", 1000, false, 1000},
+		{"overflows index", 1003, false, 489},
+		{"huge", math.MaxInt32, true, 2047},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := maxLocalPayload(4096, tt.payload, tt.leafTable)
+			if got != tt.want {
+				t.Errorf("maxLocalPayload(4096, %d) = %d, want %d", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+// testDatabase returns a database with a small table and a table spanning several pages
+// with rows overflowing to overflow pages.
+func testDatabase(t *testing.T) []byte {
+	w := NewWriter()
+	if err := w.CreateTable("notes", "CREATE TABLE notes (id integer primary key, flds text, tags text)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CreateTable("cards", "CREATE TABLE cards (id integer primary key, nid integer, due integer, data blob)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CreateIndex("ix_cards_nid", "cards", "CREATE INDEX ix_cards_nid ON cards (nid)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Insert("notes", 10, "hello\x1fworld", " greeting "); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Insert("notes", 20, strings.Repeat("long ", 2000), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 500; i++ {
+		if err := w.Insert("cards", i, 10+i%2*10, int64(i)*1000, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := w.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestRoundTrip(t *testing.T) {
+	db, err := Open(testDatabase(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !db.HasTable("notes") || !db.HasTable("cards") || db.HasTable("revlog") {
+		t.Fatalf("tables = %v", db.tables)
+	}
+
+	notes, err := db.Rows("notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{"id": int64(10), "flds": "hello\x1fworld", "tags": " greeting "},
+		{"id": int64(20), "flds": strings.Repeat("long ", 2000), "tags": nil},
+	}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("notes = %v, want %v", notes, want)
+	}
+
+	cards, err := db.Rows("cards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 500 {
+		t.Fatalf("len(cards) = %d, want 500", len(cards))
+	}
+	for i, card := range cards {
+		id := int64(i + 1)
+		if card["id"] != id || card["due"] != id*1000 || !bytes.Equal(card["data"].([]byte), []byte{byte(id)}) {
+			t.Fatalf("cards[%d] = %v", i, card)
+		}
+	}
+
+	if _, err := db.Rows("revlog"); err == nil {
+		t.Error("Rows of a missing table succeeded")
+	}
+}
+
+// firstCell returns the offset of the first cell of the table root page which must be a leaf.
+func firstCell(t *testing.T, data []byte, table string) int {
+	db, err := Open(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := int(db.tables[table].rootPage-1) * db.pageSize
+	if data[start] != pageLeafTable {
+		t.Fatalf("root page of %s isn't a leaf", table)
+	}
+
+	return start + int(binary.BigEndian.Uint16(data[start+8:]))
+}
+
+func TestOpenCorrupted(t *testing.T) {
+	valid := testDatabase(t)
+
+	mutate := func(fn func([]byte)) []byte {
+		data := append([]byte{}, valid...)
+		fn(data)
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, nil},
+		{"not a database", []byte(strings.Repeat("not sqlite ", 20)), nil},
+		{"header only", valid[:headerSize], ErrCorrupted},
+		{"truncated first page", valid[:defaultPageSize/2], ErrCorrupted},
+		{"page size not a power of two", mutate(func(d []byte) { binary.BigEndian.PutUint16(d[16:], 1000) }), ErrCorrupted},
+		{"page size too small", mutate(func(d []byte) { binary.BigEndian.PutUint16(d[16:], 256) }), ErrCorrupted},
+		{"reserved space leaves no usable bytes", mutate(func(d []byte) { d[20] = 255; binary.BigEndian.PutUint16(d[16:], 512) }), ErrCorrupted},
+		{"utf-16 encoding", mutate(func(d []byte) { binary.BigEndian.PutUint32(d[56:], 2) }), nil},
+		{"unknown page type", mutate(func(d []byte) { d[headerSize] = 0x42 }), ErrCorrupted},
+		{"cell count past the page", mutate(func(d []byte) { binary.BigEndian.PutUint16(d[headerSize+3:], 0xffff) }), ErrCorrupted},
+		{"cell pointer past the page", mutate(func(d []byte) { binary.BigEndian.PutUint16(d[headerSize+8:], 0xffff) }), ErrCorrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Open(tt.data)
+			if err == nil {
+				t.Fatal("Open() succeeded")
+			}
+			if tt.want != nil && err != tt.want {
+				t.Errorf("Open() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// overflowPointer returns the offset of the first overflow page number of the second
+// notes row, which is too long to be stored in its cell.
+func overflowPointer(t *testing.T, data []byte) int {
+	start := firstCell(t, data, "notes") &^ (defaultPageSize - 1)
+	cell := start + int(binary.BigEndian.Uint16(data[start+10:]))
+
+	size, n := getVarint(data[cell:])
+	_, m := getVarint(data[cell+n:])
+
+	return cell + n + m + maxLocalPayload(defaultPageSize, int(size), true)
+}
+
+func TestRowsCorrupted(t *testing.T) {
+	valid := testDatabase(t)
+	cell := firstCell(t, valid, "notes")
+	overflow := overflowPointer(t, valid)
+	overflowPage := binary.BigEndian.Uint32(valid[overflow:])
+
+	mutate := func(fn func([]byte)) []byte {
+		data := append([]byte{}, valid...)
+		fn(data)
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"huge payload size", mutate(func(d []byte) { copy(d[cell:], putVarint(math.MaxUint64)) })},
+		{"payload size larger than the file", mutate(func(d []byte) { copy(d[cell:], []byte{0x87, 0xff, 0xff, 0xff, 0x7f}) })},
+		{"payload size past the local part", mutate(func(d []byte) { copy(d[cell:], putVarint(3000)) })},
+		{"overflow page past the file", mutate(func(d []byte) { binary.BigEndian.PutUint32(d[overflow:], 0xffffffff) })},
+		{"overflow chain looping", mutate(func(d []byte) {
+			binary.BigEndian.PutUint32(d[int(overflowPage-1)*defaultPageSize:], overflowPage)
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := Open(tt.data)
+			if err != nil {
+				return
+			}
+
+			_, errNotes := db.Rows("notes")
+			_, errCards := db.Rows("cards")
+			if errNotes == nil && errCards == nil {
+				t.Error("Rows() succeeded for both tables")
+			}
+		})
+	}
+}
+
+// TestCorruptedBytes flips every byte of a small database one by one,
+// reading it must fail with an error or succeed but never panic.
+func TestCorruptedBytes(t *testing.T) {
+	w := NewWriter()
+	if err := w.CreateTable("notes", "CREATE TABLE notes (id integer primary key, flds text)"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		if err := w.Insert("notes", i, strings.Repeat("note ", 1000*i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	valid, err := w.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for pos := range valid {
+		for _, mask := range []byte{0xff, 0x80} {
+			data := append([]byte{}, valid...)
+			data[pos] ^= mask
+
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("byte %d xor %#x: panic: %v", pos, mask, r)
+					}
+				}()
+
+				db, err := Open(data)
+				if err != nil {
+					return
+				}
+				db.Rows("notes")
+			}()
+		}
+	}
+}
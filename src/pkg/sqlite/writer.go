@@ -0,0 +1,521 @@
+package sqlite
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+const (
+	defaultPageSize = 4096
+	sqliteVersion   = 3037000
+)
+
+type writerRow struct {
+	rowid  int64
+	values []interface{}
+}
+
+type writerTable struct {
+	name      string
+	sql       string
+	columns   []column
+	rows      []*writerRow
+	nextRowid int64
+}
+
+type writerIndex struct {
+	name    string
+	table   string
+	sql     string
+	columns []string
+}
+
+// cell is a b-tree cell whose overflow pages get allocated only when the page is written.
+type cell struct {
+	kind    byte
+	left    uint32
+	rowid   int64
+	payload []byte
+}
+
+// Writer builds a new SQLite database file in memory.
+type Writer struct {
+	pageSize int
+	pages    [][]byte
+	tables   []*writerTable
+	tableMap map[string]*writerTable
+	indexes  []*writerIndex
+}
+
+func NewWriter() *Writer {
+	return &Writer{
+		pageSize: defaultPageSize,
+		tableMap: make(map[string]*writerTable),
+	}
+}
+
+// CreateTable registers a table, columns are taken from the statement.
+func (w *Writer) CreateTable(name string, sql string) error {
+	if _, ok := w.tableMap[name]; ok {
+		return fmt.Errorf("sqlite: table %s already exists", name)
+	}
+
+	columns := parseTableColumns(sql)
+	if len(columns) == 0 {
+		return fmt.Errorf("sqlite: table %s has no columns", name)
+	}
+
+	table := &writerTable{name: name, sql: sql, columns: columns, nextRowid: 1}
+	w.tables = append(w.tables, table)
+	w.tableMap[name] = table
+
+	return nil
+}
+
+// CreateIndex registers an index over the table columns given in the statement.
+func (w *Writer) CreateIndex(name string, table string, sql string) error {
+	t, ok := w.tableMap[table]
+	if !ok {
+		return fmt.Errorf("sqlite: no such table: %s", table)
+	}
+
+	columns := parseIndexColumns(sql)
+	for _, name := range columns {
+		if t.columnIndex(name) < 0 {
+			return fmt.Errorf("sqlite: no such column: %s", name)
+		}
+	}
+
+	w.indexes = append(w.indexes, &writerIndex{name: name, table: table, sql: sql, columns: columns})
+
+	return nil
+}
+
+// Insert appends a row, values follow the order of table columns.
+func (w *Writer) Insert(table string, values ...interface{}) error {
+	t, ok := w.tableMap[table]
+	if !ok {
+		return fmt.Errorf("sqlite: no such table: %s", table)
+	}
+
+	if len(values) != len(t.columns) {
+		return fmt.Errorf("sqlite: table %s has %d columns but %d values were supplied", table, len(t.columns), len(values))
+	}
+
+	row := &writerRow{rowid: t.nextRowid, values: values}
+	for i, col := range t.columns {
+		if !col.rowidAlias || values[i] == nil {
+			continue
+		}
+
+		rowid, ok := toInt(values[i])
+		if !ok {
+			return fmt.Errorf("sqlite: %s.%s must be an integer", table, col.name)
+		}
+		row.rowid = rowid
+	}
+
+	if row.rowid >= t.nextRowid {
+		t.nextRowid = row.rowid + 1
+	}
+	t.rows = append(t.rows, row)
+
+	return nil
+}
+
+func (t *writerTable) columnIndex(name string) int {
+	for i, col := range t.columns {
+		if col.name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Bytes lays out all tables and indexes into pages and returns the database file.
+func (w *Writer) Bytes() ([]byte, error) {
+	w.pages = [][]byte{make([]byte, w.pageSize)}
+	master := []*cell{}
+
+	for _, t := range w.tables {
+		sort.SliceStable(t.rows, func(a, b int) bool {
+			return t.rows[a].rowid < t.rows[b].rowid
+		})
+
+		cells := []*cell{}
+		for i, row := range t.rows {
+			if i > 0 && t.rows[i-1].rowid == row.rowid {
+				return nil, fmt.Errorf("sqlite: duplicate rowid %d in table %s", row.rowid, t.name)
+			}
+
+			values := append([]interface{}{}, row.values...)
+			for j, col := range t.columns {
+				if col.rowidAlias {
+					values[j] = nil
+				}
+			}
+
+			record, err := encodeRecord(values)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, &cell{kind: pageLeafTable, rowid: row.rowid, payload: record})
+		}
+
+		root := w.allocPage()
+		w.buildTableTree(root, cells)
+
+		record, err := encodeRecord([]interface{}{"table", t.name, t.name, int64(root), t.sql})
+		if err != nil {
+			return nil, err
+		}
+		master = append(master, &cell{kind: pageLeafTable, rowid: int64(len(master) + 1), payload: record})
+	}
+
+	for _, idx := range w.indexes {
+		t := w.tableMap[idx.table]
+
+		keys := [][]interface{}{}
+		for _, row := range t.rows {
+			key := []interface{}{}
+			for _, name := range idx.columns {
+				pos := t.columnIndex(name)
+				if t.columns[pos].rowidAlias {
+					key = append(key, row.rowid)
+				} else {
+					key = append(key, row.values[pos])
+				}
+			}
+			keys = append(keys, append(key, row.rowid))
+		}
+
+		sort.SliceStable(keys, func(a, b int) bool {
+			for i := range keys[a] {
+				if c := compareValues(keys[a][i], keys[b][i]); c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+
+		payloads := [][]byte{}
+		for _, key := range keys {
+			record, err := encodeRecord(key)
+			if err != nil {
+				return nil, err
+			}
+			payloads = append(payloads, record)
+		}
+
+		root := w.allocPage()
+		w.buildIndexTree(root, payloads)
+
+		record, err := encodeRecord([]interface{}{"index", idx.name, idx.table, int64(root), idx.sql})
+		if err != nil {
+			return nil, err
+		}
+		master = append(master, &cell{kind: pageLeafTable, rowid: int64(len(master) + 1), payload: record})
+	}
+
+	w.buildTableTree(1, master)
+	w.writeHeader()
+
+	data := make([]byte, 0, len(w.pages)*w.pageSize)
+	for _, page := range w.pages {
+		data = append(data, page...)
+	}
+
+	return data, nil
+}
+
+func (w *Writer) writeHeader() {
+	header := w.pages[0][:headerSize]
+
+	copy(header, headerMagic)
+	binary.BigEndian.PutUint16(header[16:], uint16(w.pageSize))
+	header[18] = 1
+	header[19] = 1
+	header[20] = 0
+	header[21] = 64
+	header[22] = 32
+	header[23] = 32
+	binary.BigEndian.PutUint32(header[24:], 1)
+	binary.BigEndian.PutUint32(header[28:], uint32(len(w.pages)))
+	binary.BigEndian.PutUint32(header[40:], 1)
+	binary.BigEndian.PutUint32(header[44:], 4)
+	binary.BigEndian.PutUint32(header[56:], 1)
+	binary.BigEndian.PutUint32(header[92:], 1)
+	binary.BigEndian.PutUint32(header[96:], sqliteVersion)
+}
+
+func (w *Writer) allocPage() uint32 {
+	w.pages = append(w.pages, make([]byte, w.pageSize))
+	return uint32(len(w.pages))
+}
+
+func pageHeaderLen(kind byte) int {
+	if kind == pageInteriorTable || kind == pageInteriorIndex {
+		return 12
+	}
+
+	return 8
+}
+
+func (w *Writer) capacity(page uint32) int {
+	if page == 1 {
+		return w.pageSize - headerSize
+	}
+
+	return w.pageSize
+}
+
+func (w *Writer) cellSize(c *cell) int {
+	if c.kind == pageInteriorTable {
+		return 4 + len(putVarint(uint64(c.rowid)))
+	}
+
+	size := len(c.payload)
+	local := maxLocalPayload(w.pageSize, size, c.kind == pageLeafTable)
+	n := len(putVarint(uint64(size))) + local
+	if local < size {
+		n += 4
+	}
+
+	switch c.kind {
+	case pageLeafTable:
+		n += len(putVarint(uint64(c.rowid)))
+	case pageInteriorIndex:
+		n += 4
+	}
+
+	return n
+}
+
+func (w *Writer) fits(kind byte, cells []*cell, capacity int) bool {
+	used := pageHeaderLen(kind)
+	for _, c := range cells {
+		used += 2 + w.cellSize(c)
+	}
+
+	return used <= capacity
+}
+
+// encodeCell serializes the cell allocating overflow pages for the payload tail.
+func (w *Writer) encodeCell(c *cell) []byte {
+	buf := []byte{}
+
+	if c.kind == pageInteriorTable || c.kind == pageInteriorIndex {
+		buf = appendUint32(buf, c.left)
+	}
+	if c.kind == pageInteriorTable {
+		return append(buf, putVarint(uint64(c.rowid))...)
+	}
+
+	size := len(c.payload)
+	buf = append(buf, putVarint(uint64(size))...)
+	if c.kind == pageLeafTable {
+		buf = append(buf, putVarint(uint64(c.rowid))...)
+	}
+
+	local := maxLocalPayload(w.pageSize, size, c.kind == pageLeafTable)
+	buf = append(buf, c.payload[:local]...)
+	if local < size {
+		buf = appendUint32(buf, w.writeOverflow(c.payload[local:]))
+	}
+
+	return buf
+}
+
+func (w *Writer) writeOverflow(data []byte) uint32 {
+	chunk := w.pageSize - 4
+	pages := []uint32{}
+	for pos := 0; pos < len(data); pos += chunk {
+		pages = append(pages, w.allocPage())
+	}
+
+	for i, number := range pages {
+		page := w.pages[number-1]
+		if i+1 < len(pages) {
+			binary.BigEndian.PutUint32(page, pages[i+1])
+		}
+
+		end := (i + 1) * chunk
+		if end > len(data) {
+			end = len(data)
+		}
+		copy(page[4:], data[i*chunk:end])
+	}
+
+	return pages[0]
+}
+
+func (w *Writer) writePage(number uint32, kind byte, cells []*cell, right uint32) {
+	offset := 0
+	if number == 1 {
+		offset = headerSize
+	}
+
+	encoded := make([][]byte, len(cells))
+	for i, c := range cells {
+		encoded[i] = w.encodeCell(c)
+	}
+
+	page := w.pages[number-1]
+	headerLen := pageHeaderLen(kind)
+	contentStart := w.pageSize
+
+	for i, data := range encoded {
+		contentStart -= len(data)
+		copy(page[contentStart:], data)
+		binary.BigEndian.PutUint16(page[offset+headerLen+2*i:], uint16(contentStart))
+	}
+
+	page[offset] = kind
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(contentStart%65536))
+	if headerLen == 12 {
+		binary.BigEndian.PutUint32(page[offset+8:], right)
+	}
+}
+
+type tableChild struct {
+	page uint32
+	key  int64
+}
+
+func (w *Writer) buildTableTree(root uint32, cells []*cell) {
+	if w.fits(pageLeafTable, cells, w.capacity(root)) {
+		w.writePage(root, pageLeafTable, cells, 0)
+		return
+	}
+
+	children := []tableChild{}
+	for start := 0; start < len(cells); {
+		end := start + 1
+		for end < len(cells) && w.fits(pageLeafTable, cells[start:end+1], w.pageSize) {
+			end++
+		}
+
+		page := w.allocPage()
+		w.writePage(page, pageLeafTable, cells[start:end], 0)
+		children = append(children, tableChild{page, cells[end-1].rowid})
+		start = end
+	}
+
+	for {
+		interior := []*cell{}
+		for _, child := range children[:len(children)-1] {
+			interior = append(interior, &cell{kind: pageInteriorTable, left: child.page, rowid: child.key})
+		}
+
+		if w.fits(pageInteriorTable, interior, w.capacity(root)) {
+			w.writePage(root, pageInteriorTable, interior, children[len(children)-1].page)
+			return
+		}
+
+		// Children are spread evenly so that every interior page has at least one cell.
+		perPage := (w.pageSize-12)/(2+4+9) + 1
+		pageCount := (len(children) + perPage - 1) / perPage
+		size := (len(children) + pageCount - 1) / pageCount
+
+		next := []tableChild{}
+		for start := 0; start < len(children); start += size {
+			end := start + size
+			if end > len(children) {
+				end = len(children)
+			}
+
+			page := w.allocPage()
+			w.writePage(page, pageInteriorTable, interior[start:end-1], children[end-1].page)
+			next = append(next, tableChild{page, children[end-1].key})
+		}
+		children = next
+	}
+}
+
+func (w *Writer) buildIndexTree(root uint32, payloads [][]byte) {
+	leafCells := make([]*cell, len(payloads))
+	for i, payload := range payloads {
+		leafCells[i] = &cell{kind: pageLeafIndex, payload: payload}
+	}
+
+	if w.fits(pageLeafIndex, leafCells, w.capacity(root)) {
+		w.writePage(root, pageLeafIndex, leafCells, 0)
+		return
+	}
+
+	// Every page but the last one is followed by a separator entry promoted to the parent.
+	children := []uint32{}
+	separators := [][]byte{}
+	for start := 0; start < len(leafCells); {
+		end := start + 1
+		for end < len(leafCells) && w.fits(pageLeafIndex, leafCells[start:end+1], w.pageSize) {
+			end++
+		}
+
+		if end == len(leafCells) {
+			page := w.allocPage()
+			w.writePage(page, pageLeafIndex, leafCells[start:end], 0)
+			children = append(children, page)
+			break
+		}
+
+		if end+1 == len(leafCells) && end-1 > start {
+			end--
+		}
+
+		page := w.allocPage()
+		w.writePage(page, pageLeafIndex, leafCells[start:end], 0)
+		children = append(children, page)
+		separators = append(separators, payloads[end])
+		start = end + 1
+	}
+
+	for {
+		interior := make([]*cell, len(separators))
+		for i, separator := range separators {
+			interior[i] = &cell{kind: pageInteriorIndex, left: children[i], payload: separator}
+		}
+
+		if w.fits(pageInteriorIndex, interior, w.capacity(root)) {
+			w.writePage(root, pageInteriorIndex, interior, children[len(children)-1])
+			return
+		}
+
+		nextChildren := []uint32{}
+		nextSeparators := [][]byte{}
+		for start := 0; start < len(children); {
+			end := start
+			for end < len(interior) && w.fits(pageInteriorIndex, interior[start:end+1], w.pageSize) {
+				end++
+			}
+
+			if end == len(interior) {
+				page := w.allocPage()
+				w.writePage(page, pageInteriorIndex, interior[start:end], children[end])
+				nextChildren = append(nextChildren, page)
+				break
+			}
+
+			if end+1 == len(interior) && end-1 > start {
+				end--
+			}
+
+			page := w.allocPage()
+			w.writePage(page, pageInteriorIndex, interior[start:end], children[end])
+			nextChildren = append(nextChildren, page)
+			nextSeparators = append(nextSeparators, separators[end])
+			start = end + 1
+		}
+		children, separators = nextChildren, nextSeparators
+	}
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var tmp [4]byte
+	binary.BigEndian.PutUint32(tmp[:], v)
+
+	return append(buf, tmp[:]...)
+}